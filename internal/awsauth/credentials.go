@@ -0,0 +1,157 @@
+package awsauth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Credentials are AWS access keys, optionally temporary
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string    // Set for temporary (role/SSO) credentials
+	Expires         time.Time // Zero for long-term keys
+}
+
+// Expired reports whether temporary credentials have expired
+func (c *Credentials) Expired() bool {
+	return !c.Expires.IsZero() && time.Now().After(c.Expires.Add(-time.Minute))
+}
+
+// LoadCredentials resolves credentials in the usual AWS order:
+//  1. AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN
+//  2. static keys of the profile in ~/.aws/credentials
+//  3. credential_process of the profile in ~/.aws/config, which covers
+//     assumed roles and SSO through tools like aws-vault or
+//     "aws configure export-credentials"
+//
+// An empty profile means AWS_PROFILE or "default".
+func LoadCredentials(ctx context.Context, profile string) (*Credentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return &Credentials{
+			AccessKeyID:     id,
+			SecretAccessKey: secret,
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	credsFile, err := readINI(sharedFile("AWS_SHARED_CREDENTIALS_FILE", "credentials"))
+	if err != nil {
+		return nil, err
+	}
+	if section := credsFile[profile]; section["aws_access_key_id"] != "" {
+		return &Credentials{
+			AccessKeyID:     section["aws_access_key_id"],
+			SecretAccessKey: section["aws_secret_access_key"],
+			SessionToken:    section["aws_session_token"],
+		}, nil
+	}
+
+	configFile, err := readINI(sharedFile("AWS_CONFIG_FILE", "config"))
+	if err != nil {
+		return nil, err
+	}
+	name := "profile " + profile
+	if profile == "default" {
+		name = "default"
+	}
+	if process := configFile[name]["credential_process"]; process != "" {
+		return runCredentialProcess(ctx, process)
+	}
+
+	return nil, fmt.Errorf("no AWS credentials found: set AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY "+
+		"or configure profile %q in ~/.aws", profile)
+}
+
+// sharedFile returns the path of a shared AWS file, honoring its
+// environment override
+func sharedFile(env, name string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", name)
+}
+
+// readINI parses the sections of an AWS shared config file. A missing
+// file yields no sections.
+func readINI(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			current = make(map[string]string)
+			sections[name] = current
+		case current != nil:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				current[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// runCredentialProcess runs an external credential provider and parses
+// its JSON output
+func runCredentialProcess(ctx context.Context, process string) (*Credentials, error) {
+	args := strings.Fields(process)
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential_process %q failed: %w", args[0], err)
+	}
+
+	var result struct {
+		Version         int    `json:"Version"`
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+		Expiration      string `json:"Expiration"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("invalid credential_process output: %w", err)
+	}
+	if result.AccessKeyID == "" || result.SecretAccessKey == "" {
+		return nil, fmt.Errorf("credential_process returned no access key")
+	}
+
+	creds := &Credentials{
+		AccessKeyID:     result.AccessKeyID,
+		SecretAccessKey: result.SecretAccessKey,
+		SessionToken:    result.SessionToken,
+	}
+	if result.Expiration != "" {
+		creds.Expires, _ = time.Parse(time.RFC3339, result.Expiration)
+	}
+	return creds, nil
+}
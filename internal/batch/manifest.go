@@ -0,0 +1,238 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Row is one generation job in a batch manifest. Empty fields fall back
+// to the batch defaults.
+type Row struct {
+	Line       int               `json:"-"` // Source line number, for error messages
+	Prompt     string            `json:"prompt"`
+	Model      string            `json:"model,omitempty"`
+	Size       string            `json:"size,omitempty"`
+	Seed       *int64            `json:"seed,omitempty"`
+	Count      int               `json:"count,omitempty"` // Images to generate, 0 for the batch default
+	OutputName string            `json:"output_name,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Template   string            `json:"template,omitempty"` // Named template from the config
+	Vars       map[string]string `json:"vars,omitempty"`     // Template variables
+}
+
+// manifestColumns are the CSV columns of a manifest, in canonical order
+var manifestColumns = []string{"prompt", "model", "size", "seed", "count", "output_name", "tags", "template", "vars"}
+
+// tagSeparator separates tags and vars within a CSV cell
+const tagSeparator = ";"
+
+// IsJSONL reports whether path is a JSON Lines file, judged by extension
+func IsJSONL(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".jsonl" || ext == ".ndjson"
+}
+
+// Load reads a manifest from a CSV or JSONL file
+func Load(path string) ([]Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	var rows []Row
+	if IsJSONL(path) {
+		rows, err = parseJSONL(f)
+	} else {
+		rows, err = parseCSV(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("manifest %s has no rows", path)
+	}
+	return rows, nil
+}
+
+// parseJSONL parses one JSON object per line; blank lines are skipped
+func parseJSONL(r io.Reader) ([]Row, error) {
+	var rows []Row
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var row Row
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		row.Line = line
+		if err := row.validate(); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseCSV parses a CSV manifest with a header row. Columns may appear in
+// any order; unknown columns are rejected to catch typos.
+func parseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !contains(manifestColumns, name) {
+			return nil, fmt.Errorf("unknown column %q, supported: %s", name, strings.Join(manifestColumns, ", "))
+		}
+		index[name] = i
+	}
+	_, hasPrompt := index["prompt"]
+	_, hasTemplate := index["template"]
+	if !hasPrompt && !hasTemplate {
+		return nil, fmt.Errorf("missing required column \"prompt\" or \"template\"")
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		get := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := Row{
+			Line:       line,
+			Prompt:     get("prompt"),
+			Model:      get("model"),
+			Size:       get("size"),
+			OutputName: get("output_name"),
+			Tags:       splitTags(get("tags")),
+			Template:   get("template"),
+		}
+		if row.Vars, err = parseVars(get("vars")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if s := get("seed"); s != "" {
+			seed, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid seed %q", line, s)
+			}
+			row.Seed = &seed
+		}
+		if s := get("count"); s != "" {
+			if row.Count, err = strconv.Atoi(s); err != nil {
+				return nil, fmt.Errorf("line %d: invalid count %q", line, s)
+			}
+		}
+		if err := row.validate(); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// validate checks the fields of a row that do not depend on the provider
+func (r *Row) validate() error {
+	if r.Prompt == "" && r.Template == "" {
+		return fmt.Errorf("line %d: prompt or template is required", r.Line)
+	}
+	if r.Count < 0 {
+		return fmt.Errorf("line %d: count must not be negative", r.Line)
+	}
+	if r.OutputName != "" && (filepath.IsAbs(r.OutputName) || strings.Contains(r.OutputName, "..")) {
+		return fmt.Errorf("line %d: output_name must be a relative path inside the output directory", r.Line)
+	}
+	return nil
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, tagSeparator) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// parseVars parses "key=value;key=value" template variables
+func parseVars(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	vars := make(map[string]string)
+	for _, pair := range strings.Split(s, tagSeparator) {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid vars entry %q, expected key=value", pair)
+		}
+		vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return vars, nil
+}
+
+// formatVars formats template variables as "key=value;key=value"
+func formatVars(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + vars[k]
+	}
+	return strings.Join(pairs, tagSeparator)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package batch
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadPrompts reads a prompt file with one prompt per line into rows.
+// Blank lines and lines starting with "#" are skipped.
+func LoadPrompts(path string) ([]Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prompt file: %w", err)
+	}
+	defer f.Close()
+
+	var rows []Row
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rows = append(rows, Row{Line: line, Prompt: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("prompt file %s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("prompt file %s has no prompts", path)
+	}
+	return rows, nil
+}
@@ -0,0 +1,108 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result statuses
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // Outputs existed under on_conflict: skip
+)
+
+// Result records the outcome of one manifest row
+type Result struct {
+	Row
+	Status   string        `json:"status"`
+	Paths    []string      `json:"paths,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Cost     float64       `json:"cost"` // USD, estimated from the model catalog
+	Error    string        `json:"error,omitempty"`
+}
+
+// StripPrompt removes prompt text from the result. Vars are removed too,
+// since templates usually build the prompt from them; cost, timing and
+// paths are kept.
+func (r *Result) StripPrompt() {
+	r.Prompt = ""
+	r.Vars = nil
+}
+
+// Redact applies fn to the prompt text, var values and error of the
+// result
+func (r *Result) Redact(fn func(string) string) {
+	r.Prompt = fn(r.Prompt)
+	r.Error = fn(r.Error)
+	if r.Vars != nil {
+		vars := make(map[string]string, len(r.Vars))
+		for k, v := range r.Vars {
+			vars[k] = fn(v)
+		}
+		r.Vars = vars
+	}
+}
+
+// resultColumns are the columns appended to the manifest columns in CSV
+// results files
+var resultColumns = []string{"status", "path", "duration", "cost", "error"}
+
+// WriteResults writes results to path as CSV or JSONL, matching the
+// manifest columns so the file can be reopened in the same spreadsheet
+func WriteResults(path string, results []Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create results file: %w", err)
+	}
+	defer f.Close()
+
+	if IsJSONL(path) {
+		enc := json.NewEncoder(f)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("failed to write results: %w", err)
+			}
+		}
+		return f.Close()
+	}
+
+	w := csv.NewWriter(f)
+	w.Write(append(append([]string(nil), manifestColumns...), resultColumns...))
+	for _, r := range results {
+		seed := ""
+		if r.Seed != nil {
+			seed = strconv.FormatInt(*r.Seed, 10)
+		}
+		count := ""
+		if r.Count > 0 {
+			count = strconv.Itoa(r.Count)
+		}
+		w.Write([]string{
+			r.Prompt,
+			r.Model,
+			r.Size,
+			seed,
+			count,
+			r.OutputName,
+			strings.Join(r.Tags, tagSeparator),
+			r.Template,
+			formatVars(r.Vars),
+			r.Status,
+			strings.Join(r.Paths, tagSeparator),
+			r.Duration.Round(time.Millisecond).String(),
+			strconv.FormatFloat(r.Cost, 'f', 4, 64),
+			r.Error,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,23 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Render expands a prompt template with the variables of a row. Missing
+// variables are an error so that typos do not produce literal
+// "<no value>" prompts.
+func Render(name, text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %s: %w", name, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
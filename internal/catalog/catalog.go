@@ -0,0 +1,235 @@
+package catalog
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// DefaultURL is the hosted catalog used by "models refresh"
+const DefaultURL = "https://foxzi.github.io/llm-imager/catalog.json"
+
+// dateLayout is the format of dates in the catalog
+const dateLayout = "2006-01-02"
+
+//go:embed catalog.json
+var embedded []byte
+
+var (
+	mu      sync.RWMutex
+	current *Catalog
+)
+
+// Catalog is a versioned list of known models
+type Catalog struct {
+	Version   int     `json:"version"`
+	UpdatedAt string  `json:"updated_at"` // YYYY-MM-DD
+	Models    []Model `json:"models"`
+}
+
+// Model describes a model entry in the catalog
+type Model struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Provider      string   `json:"provider"`
+	Sizes         []string `json:"sizes,omitempty"`
+	Features      []string `json:"features,omitempty"`
+	PricePerImage float64  `json:"price_per_image,omitempty"` // USD, approximate
+	MaxImages     int      `json:"max_images,omitempty"`      // Images per request, 0 if unknown
+	MaxSize       string   `json:"max_size,omitempty"`        // Largest WIDTHxHEIGHT output, per side
+	DeprecatedAt  string   `json:"deprecated_at,omitempty"`   // YYYY-MM-DD
+	SunsetAt      string   `json:"sunset_at,omitempty"`       // YYYY-MM-DD, model removed
+	Replacement   string   `json:"replacement,omitempty"`     // Suggested model ID
+}
+
+// Deprecated reports whether the model is deprecated at time t
+func (m Model) Deprecated(t time.Time) bool {
+	return onOrBefore(m.DeprecatedAt, t) || m.Sunset(t)
+}
+
+// Sunset reports whether the model has been removed at time t
+func (m Model) Sunset(t time.Time) bool {
+	return onOrBefore(m.SunsetAt, t)
+}
+
+// MaxDimensions returns the largest output width and height, if known
+func (m Model) MaxDimensions() (width, height int, ok bool) {
+	if _, err := fmt.Sscanf(m.MaxSize, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// onOrBefore reports whether date is set and not after t
+func onOrBefore(date string, t time.Time) bool {
+	if date == "" {
+		return false
+	}
+	d, err := time.Parse(dateLayout, date)
+	return err == nil && !d.After(t)
+}
+
+// Current returns the active catalog: the embedded one, or a newer
+// override installed with Use
+func Current() *Catalog {
+	mu.RLock()
+	c := current
+	mu.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		current = Embedded()
+	}
+	return current
+}
+
+// Embedded returns the catalog built into the binary
+func Embedded() *Catalog {
+	c, err := Parse(embedded)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded model catalog: %v", err))
+	}
+	return c
+}
+
+// Use installs c as the active catalog
+func Use(c *Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = c
+}
+
+// Parse decodes and validates a catalog
+func Parse(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode catalog: %w", err)
+	}
+	if _, err := time.Parse(dateLayout, c.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("invalid catalog updated_at %q", c.UpdatedAt)
+	}
+	for _, m := range c.Models {
+		if m.ID == "" || m.Provider == "" {
+			return nil, fmt.Errorf("catalog entry missing id or provider")
+		}
+		for _, date := range []string{m.DeprecatedAt, m.SunsetAt} {
+			if _, err := time.Parse(dateLayout, date); date != "" && err != nil {
+				return nil, fmt.Errorf("invalid date %q in catalog entry %s", date, m.ID)
+			}
+		}
+	}
+	return &c, nil
+}
+
+// Updated returns the catalog update date
+func (c *Catalog) Updated() time.Time {
+	t, _ := time.Parse(dateLayout, c.UpdatedAt)
+	return t
+}
+
+// ForProvider returns the models of a provider
+func (c *Catalog) ForProvider(provider string) []Model {
+	var models []Model
+	for _, m := range c.Models {
+		if m.Provider == provider {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// Lookup returns the catalog entry for a model ID
+func (c *Catalog) Lookup(id string) (Model, bool) {
+	for _, m := range c.Models {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// DefaultOverridePath returns where refreshed catalogs are stored
+func DefaultOverridePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "llm-imager", "catalog.json")
+}
+
+// LoadOverride activates the catalog at path if it is newer than the
+// embedded one. A missing file is not an error.
+func LoadOverride(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read catalog override: %w", err)
+	}
+
+	override, err := Parse(data)
+	if err != nil {
+		return fmt.Errorf("catalog override %s: %w", path, err)
+	}
+
+	if override.Updated().After(Current().Updated()) {
+		Use(override)
+	}
+	return nil
+}
+
+// Refresh downloads the catalog from url, validates it and stores it at path
+func Refresh(ctx context.Context, client *http.Client, url, path string) (*Catalog, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	httputil.ApplyUserAgent(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("catalog server returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save catalog: %w", err)
+	}
+
+	if c.Updated().After(Current().Updated()) {
+		Use(c)
+	}
+	return c, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// defaultAltTextPrompt asks for a description that works as HTML alt text
+const defaultAltTextPrompt = "Write alt text for this image for use on a web page: " +
+	"one or two plain sentences, under 150 characters, describing what it shows. " +
+	"Do not start with \"Image of\" or \"Picture of\". Reply with the alt text only."
+
+// altTexter describes images with a vision model
+type altTexter struct {
+	describer provider.Describer
+	model     string
+	prompt    string
+}
+
+// altTextEnabled reports whether saved images get alt text, from
+// --alt-text or alt_text.enabled; --alt-text-txt implies it
+func altTextEnabled(opts *generateOptions) bool {
+	if opts.altTextFile {
+		return true
+	}
+	if opts.hasAltText {
+		return opts.altText
+	}
+	return cfg.AltText.Enabled
+}
+
+// newAltTexter resolves the vision model of --vision-model or
+// vision.model. Dry runs get placeholder descriptions.
+func newAltTexter(opts *generateOptions) (*altTexter, error) {
+	prompt := cfg.AltText.Prompt
+	if prompt == "" {
+		prompt = defaultAltTextPrompt
+	}
+	describer, model, err := visionDescriber(opts, "")
+	if err != nil {
+		return nil, err
+	}
+	return &altTexter{describer: describer, model: model, prompt: prompt}, nil
+}
+
+// visionDescriber resolves the vision model describing images: model if
+// set, else --vision-model or vision.model. Dry runs get the placeholder
+// provider.
+func visionDescriber(opts *generateOptions, model string) (provider.Describer, string, error) {
+	if opts.dryRun {
+		return provider.NewDryRun(), "dryrun/placeholder", nil
+	}
+
+	if model == "" {
+		model = opts.visionModel
+	}
+	if model == "" {
+		model = cfg.Vision.Model
+	}
+	p, model, err := registry.Resolve(model)
+	if err != nil {
+		return nil, "", fmt.Errorf("vision model: %w", err)
+	}
+	describer, ok := p.(provider.Describer)
+	if !ok {
+		return nil, "", fmt.Errorf("vision model %s: provider %s cannot describe images (supported: openai, google, openrouter)", model, p.Name())
+	}
+	return describer, model, nil
+}
+
+// describable reports whether a vision model can look at an image; they
+// take raster images only, and hosted images have no data
+func describable(img generator.Image) bool {
+	return img.Format != "svg" && !img.IsVideo() && len(img.Data) > 0
+}
+
+// visionInput returns a generated image as the input of a vision model
+func visionInput(img generator.Image) generator.InputImage {
+	return generator.InputImage{
+		Name:     fmt.Sprintf("image_%d.%s", img.Index+1, img.Format),
+		MIMEType: "image/" + img.Format,
+		Data:     img.Data,
+	}
+}
+
+// describe sets the alt text of images. The images are already paid for,
+// so a failed description is a warning.
+func (a *altTexter) describe(ctx context.Context, images []generator.Image) {
+	for i := range images {
+		img := &images[i]
+		if !describable(*img) {
+			continue
+		}
+
+		text, err := a.describer.Describe(ctx, a.model, a.prompt, visionInput(*img))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: alt text for image %d failed: %v\n", img.Index+1, err)
+			continue
+		}
+		img.AltText = text
+		fmt.Printf("Alt text for image %d: %s\n", img.Index+1, text)
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// maxTagLength drops answers that are sentences rather than keywords
+const maxTagLength = 40
+
+// tagListMarker matches the bullet or number of a tag answered as a list
+var tagListMarker = regexp.MustCompile(`^(?:[-*•#]+|\d+[.)])\s*`)
+
+// autoTagPrompt asks for keyword tags; %d is the maximum number of tags
+const autoTagPrompt = "List up to %d keyword tags for this image, for search in an asset library: " +
+	"subjects, objects, setting, style, colors and mood. " +
+	"Reply with lowercase tags separated by commas and nothing else."
+
+// tagger extracts keyword tags from images with a vision model
+type tagger struct {
+	describer provider.Describer
+	model     string
+	maxTags   int
+}
+
+// autoTagEnabled reports whether saved images get tags, from --auto-tag
+// or auto_tag.enabled
+func autoTagEnabled(opts *generateOptions) bool {
+	if opts.hasAutoTag {
+		return opts.autoTag
+	}
+	return cfg.AutoTag.Enabled
+}
+
+// newTagger resolves the tagging model: --tag-model, auto_tag.model, then
+// the vision model used for alt text
+func newTagger(opts *generateOptions) (*tagger, error) {
+	model := opts.tagModel
+	if model == "" {
+		model = cfg.AutoTag.Model
+	}
+	describer, model, err := visionDescriber(opts, model)
+	if err != nil {
+		return nil, err
+	}
+	maxTags := cfg.AutoTag.MaxTags
+	if maxTags <= 0 {
+		maxTags = 10
+	}
+	return &tagger{describer: describer, model: model, maxTags: maxTags}, nil
+}
+
+// tag sets the tags of images. The images are already paid for, so a
+// failed request is a warning.
+func (t *tagger) tag(ctx context.Context, images []generator.Image) {
+	for i := range images {
+		img := &images[i]
+		if !describable(*img) {
+			continue
+		}
+
+		text, err := t.describer.Describe(ctx, t.model, fmt.Sprintf(autoTagPrompt, t.maxTags), visionInput(*img))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: tagging image %d failed: %v\n", img.Index+1, err)
+			continue
+		}
+		img.Tags = parseTags(text, t.maxTags)
+		fmt.Printf("Tags for image %d: %s\n", img.Index+1, strings.Join(img.Tags, ", "))
+	}
+}
+
+// parseTags splits a tag list answer on commas and lines, normalizing
+// each tag to lowercase without list markers, hashes or quotes, and
+// dropping duplicates and tags past limit
+func parseTags(text string, limit int) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	})
+
+	tags := make([]string, 0, min(len(fields), limit))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		tag := strings.ToLower(strings.TrimSpace(field))
+		tag = tagListMarker.ReplaceAllString(tag, "")
+		tag = strings.Trim(tag, "\"'`. ")
+		tag = strings.Join(strings.Fields(tag), " ")
+		if tag == "" || len(tag) > maxTagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == limit {
+			break
+		}
+	}
+	return tags
+}
@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/batch"
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/config"
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/output"
+	"github.com/piligrim/llm-imager/internal/provider"
+	"github.com/piligrim/llm-imager/internal/run"
+)
+
+func newBatchCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var outputDir string
+	var resultsPath string
+
+	cmd := &cobra.Command{
+		Use:   "batch <manifest>",
+		Short: "Generate images for every row of a CSV or JSONL manifest",
+		Long: `Generate one image per manifest row and write a results file.
+
+Manifests are CSV files with a header row or JSONL files with one object per
+line. Columns: prompt, model, size, seed, count, output_name, tags,
+template, vars. Each row needs a prompt or a template. Tags and vars
+("key=value") in CSV cells are separated by ";". Empty columns fall back to
+the template, the flags of this command and the config defaults.
+
+All rows are checked before the first is generated: models, sizes,
+templates, parameters and output names. If any row is invalid, every
+invalid row is listed and nothing is generated.
+
+Templates are defined under "templates" in the config file and expand
+variables with Go template syntax, e.g. "Studio photo of {{.name}}".
+
+The results file repeats the manifest columns and adds status, path,
+duration and cost (estimated from the model catalog) for each row. With
+--incognito or privacy.store_prompts: false, prompts and vars are left out
+of the results file.
+
+With --run (or output.runs: true), images and the results file go to a new
+run directory under <output directory>/runs instead of --output-dir.`,
+		Example: `  llm-imager batch products.csv -d out/
+  llm-imager batch jobs.jsonl -d out/ -m openai/dall-e-3 --results out/results.jsonl`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.plan {
+				return fmt.Errorf("--plan is not supported by batch; use --dry-run to check a batch file")
+			}
+			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasSafetyTolerance = cmd.Flags().Changed("safety-tolerance")
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			opts.hasAltText = cmd.Flags().Changed("alt-text")
+			opts.hasAutoTag = cmd.Flags().Changed("auto-tag")
+			opts.hasOCR = cmd.Flags().Changed("ocr")
+			opts.hasStrength = cmd.Flags().Changed("strength")
+			opts.hasControlStrength = cmd.Flags().Changed("control-strength")
+			if !runsEnabled(opts) {
+				return runBatch(cmd, args[0], outputDir, resultsPath, opts, nil)
+			}
+
+			// The run directory replaces the output directory
+			r, err := startRun()
+			if err != nil {
+				return err
+			}
+			m := run.Manifest{Command: "batch"}
+			err = runBatch(cmd, args[0], r.Dir, resultsPath, opts, &m)
+			finishRun(r, m, err)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "d", ".",
+		"directory for generated images")
+	cmd.Flags().StringVar(&resultsPath, "results", "",
+		"results file, CSV or JSONL (default: results.<manifest ext> in the output directory)")
+	addGenerateFlags(cmd, opts)
+
+	return cmd
+}
+
+// runBatch generates the rows of a manifest into outputDir. The results
+// file defaults to results.<manifest ext> in outputDir. A non-nil m
+// receives the row counts, paths and cost for the run manifest.
+func runBatch(cmd *cobra.Command, manifestPath, outputDir, resultsPath string, defaults *generateOptions, m *run.Manifest) error {
+	if resultsPath == "" {
+		resultsPath = filepath.Join(outputDir, "results"+filepath.Ext(manifestPath))
+	}
+
+	rows, err := batch.Load(manifestPath)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, ok := lookupTemplate(row.Template); row.Template != "" && !ok {
+			return fmt.Errorf("manifest line %d: unknown template %q", row.Line, row.Template)
+		}
+	}
+	if err := validateRows(cmd.Context(), rows, outputDir, defaults); err != nil {
+		return err
+	}
+	return generateRows(cmd.Context(), rows, outputDir, resultsPath, defaults, m)
+}
+
+// rowOptions returns the generation options of a row: the batch
+// defaults, overridden by the row template and then the row columns
+func rowOptions(defaults *generateOptions, row batch.Row, outputDir string, index int) (generateOptions, error) {
+	opts := *defaults
+	opts.prompt = row.Prompt
+	opts.outputPath = filepath.Join(outputDir, rowOutputName(row, index))
+	err := applyTemplate(&opts, row)
+	if row.Model != "" {
+		opts.model = row.Model
+	}
+	if row.Size != "" {
+		opts.size = row.Size
+	}
+	if row.Seed != nil {
+		opts.seed, opts.hasSeed = *row.Seed, true
+	}
+	if row.Count > 0 {
+		opts.count = row.Count
+	}
+	return opts, err
+}
+
+// validateRows checks every row as executeGenerate would, without
+// generating, so a typo in row 900 fails the batch before row 1 is paid
+// for. All invalid rows are reported together.
+func validateRows(ctx context.Context, rows []batch.Row, outputDir string, defaults *generateOptions) error {
+	var errs []string
+	outputs := make(map[string]int, len(rows))
+	for i, row := range rows {
+		opts, err := rowOptions(defaults, row, outputDir, i)
+		if err == nil {
+			opts.validateOnly = true
+			_, _, err = executeGenerate(ctx, &opts)
+		}
+		// Rows sharing an output name would overwrite or skip each other
+		if policy, _ := output.ParseConflictPolicy(opts.onConflict); err == nil && policy != output.ConflictSuffix {
+			if line, ok := outputs[opts.outputPath]; ok {
+				err = fmt.Errorf("output %s is also written by line %d", opts.outputPath, line)
+			}
+			outputs[opts.outputPath] = row.Line
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("  line %d: %s", row.Line, redactor.Apply(err.Error())))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d rows are invalid, nothing was generated:\n%s", len(errs), len(rows), strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// generateRows generates rows one after another into outputDir, going on
+// past failed rows, and writes the results file unless resultsPath is
+// empty. A non-nil m receives the row counts, paths and cost.
+func generateRows(ctx context.Context, rows []batch.Row, outputDir, resultsPath string, defaults *generateOptions, m *run.Manifest) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	progress, err := openProgress(defaults.progressJSON)
+	if err != nil {
+		return err
+	}
+	defer progress.Close()
+	defaults.progress = progress
+	for i, row := range rows {
+		progress.emit(progressEvent{Event: progressQueued, Row: i + 1, Total: len(rows), Model: row.Model})
+	}
+
+	results := make([]batch.Result, 0, len(rows))
+	var failed, skipped int
+	var totalCost float64
+	start := time.Now()
+
+	for i, row := range rows {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("[%d/%d] %s\n", i+1, len(rows), redactor.Apply(rowTitle(row)))
+		progress.setRow(i+1, len(rows))
+
+		opts, err := rowOptions(defaults, row, outputDir, i)
+
+		rowStart := time.Now()
+		var resp *generator.Response
+		var paths []string
+		if err == nil {
+			resp, paths, err = executeGenerate(ctx, &opts)
+		}
+
+		result := batch.Result{
+			Row:      row,
+			Status:   batch.StatusOK,
+			Paths:    paths,
+			Duration: time.Since(rowStart),
+		}
+		if row.Template != "" {
+			result.Prompt = opts.prompt
+		}
+		if err != nil {
+			result.Status = batch.StatusFailed
+			result.Error = err.Error()
+			failed++
+			progress.failed(opts.model, err)
+			fmt.Fprintf(os.Stderr, "Row %d failed: %s\n", row.Line, redactor.Apply(err.Error()))
+		} else if len(resp.Images) == 0 && len(resp.Videos) == 0 {
+			// executeGenerate found all outputs in place and generated nothing
+			result.Status = batch.StatusSkipped
+			skipped++
+		} else {
+			result.Cost = estimateCost(resp)
+			totalCost += result.Cost
+		}
+		if !cfg.Privacy.StorePrompts {
+			result.StripPrompt()
+		}
+		result.Redact(redactor.Apply)
+		results = append(results, result)
+	}
+
+	if resultsPath != "" {
+		if err := os.MkdirAll(filepath.Dir(resultsPath), 0755); err != nil {
+			return fmt.Errorf("failed to create results directory: %w", err)
+		}
+		if err := batch.WriteResults(resultsPath, results); err != nil {
+			return err
+		}
+	}
+	if m != nil {
+		m.Results = resultsPath
+		m.Succeeded, m.Skipped, m.Failed = len(results)-failed-skipped, skipped, failed
+		m.Cost = totalCost
+		for _, result := range results {
+			m.Images = append(m.Images, result.Paths...)
+		}
+	}
+
+	fmt.Printf("Batch completed in %s: %d succeeded, %d skipped, %d failed, estimated cost $%.2f\n",
+		time.Since(start).Round(100*time.Millisecond), len(results)-failed-skipped, skipped, failed, totalCost)
+	if resultsPath != "" {
+		fmt.Printf("Results: %s\n", resultsPath)
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("batch interrupted after %d of %d rows", len(results), len(rows))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(rows))
+	}
+	return nil
+}
+
+// lookupTemplate returns a named template from the config. Viper
+// lowercases map keys, so names match case-insensitively.
+func lookupTemplate(name string) (config.TemplateConfig, bool) {
+	t, ok := cfg.Templates[strings.ToLower(name)]
+	return t, ok
+}
+
+// applyTemplate expands the template of a row into opts. Template model
+// and size are defaults that the row columns override; the row prompt is
+// available to the template as {{.prompt}}.
+func applyTemplate(opts *generateOptions, row batch.Row) error {
+	if row.Template == "" {
+		return nil
+	}
+	t, _ := lookupTemplate(row.Template)
+
+	vars := make(map[string]string, len(row.Vars)+1)
+	if row.Prompt != "" {
+		vars["prompt"] = row.Prompt
+	}
+	for k, v := range row.Vars {
+		vars[k] = v
+	}
+
+	prompt, err := batch.Render(row.Template, t.Prompt, vars)
+	if err != nil {
+		return err
+	}
+	opts.prompt = prompt
+
+	if t.NegativePrompt != "" {
+		if opts.negativePrompt, err = batch.Render(row.Template, t.NegativePrompt, vars); err != nil {
+			return err
+		}
+	}
+	if t.Model != "" {
+		opts.model = t.Model
+	}
+	if t.Size != "" {
+		opts.size = t.Size
+	}
+	return nil
+}
+
+// rowTitle describes a manifest row in progress output
+func rowTitle(row batch.Row) string {
+	if row.Template == "" {
+		return row.Prompt
+	}
+	return fmt.Sprintf("%s %s", row.Template, formatRowVars(row.Vars))
+}
+
+// formatRowVars formats template variables for display
+func formatRowVars(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, vars[k])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// rowOutputName returns the output file name of a manifest row
+func rowOutputName(row batch.Row, index int) string {
+	name := row.OutputName
+	if name == "" {
+		name = fmt.Sprintf("%03d", index+1)
+	}
+	if filepath.Ext(name) == "" {
+		format := cfg.Output.Format
+		if format == "" {
+			format = "png"
+		}
+		name += "." + format
+	}
+	return name
+}
+
+// estimateCost returns the catalog price of the generated images and
+// videos, or 0 for models without a known price
+func estimateCost(resp *generator.Response) float64 {
+	m, ok := catalog.Current().Lookup(provider.QualifiedModelID(resp.Provider, resp.Model))
+	if !ok {
+		return 0
+	}
+	return m.PricePerImage * float64(len(resp.Images)+len(resp.Videos))
+}
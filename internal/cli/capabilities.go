@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// capabilitiesVersion is the version of the capabilities --json format,
+// raised on incompatible changes
+const capabilitiesVersion = 1
+
+// Operations a provider or model supports
+const (
+	opGenerate         = "generate"
+	opEdit             = "edit"
+	opSearchReplace    = "search_replace"
+	opRecolor          = "recolor"
+	opOutpaint         = "outpaint"
+	opRemoveBackground = "remove_background"
+	opVectorize        = "vectorize"
+	opVideo            = "video"
+	opVariations       = "variations"
+	opImageToImage     = "image_to_image"
+	opControl          = "control"
+	opStyleImage       = "style_image"
+	opDescribe         = "describe"
+	opSegment          = "segment"
+	opProbe            = "probe"
+)
+
+// operations are all operations, in the order they are listed
+var operations = []string{opGenerate, opEdit, opSearchReplace, opRecolor, opOutpaint, opRemoveBackground,
+	opVectorize, opVideo, opVariations, opImageToImage, opControl, opStyleImage, opDescribe, opSegment, opProbe}
+
+// capabilities is the feature matrix of the registered providers
+type capabilities struct {
+	Version   int                    `json:"version"`
+	Features  []string               `json:"features"` // All model features, sorted
+	Providers []providerCapabilities `json:"providers"`
+}
+
+// providerCapabilities describes what a provider and its models support
+type providerCapabilities struct {
+	Name       string              `json:"name"`
+	Configured bool                `json:"configured"` // Has the credentials it needs
+	Operations []string            `json:"operations"`
+	Params     []string            `json:"params,omitempty"`     // Accepted --param keys
+	AnyParams  bool                `json:"any_params,omitempty"` // --param keys are passed to the model
+	Models     []modelCapabilities `json:"models"`
+}
+
+// modelCapabilities describes one model. Operations are those of the
+// provider the model supports; image_to_image and variations depend on
+// the model.
+type modelCapabilities struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Operations    []string `json:"operations"`
+	Features      []string `json:"features"`
+	Sizes         []string `json:"sizes,omitempty"`
+	MaxSize       string   `json:"max_size,omitempty"`
+	MaxImages     int      `json:"max_images,omitempty"`
+	PricePerImage float64  `json:"price_per_image,omitempty"` // USD, approximate
+	Deprecated    bool     `json:"deprecated,omitempty"`
+	Replacement   string   `json:"replacement,omitempty"`
+}
+
+func newCapabilitiesCmd() *cobra.Command {
+	var asJSON bool
+	var providerFilter string
+
+	cmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Show what each provider and model supports",
+		Long: `Show the feature matrix of the configured providers: the operations each
+provider supports (generate, edit, search_replace, recolor, outpaint,
+remove_background, vectorize, video, variations, image_to_image, control,
+style_image, describe, segment, probe) and, for each model, its
+operations, catalog features, sizes and price.
+
+--json prints the full matrix for documentation generators and GUIs
+building forms; its "version" field changes on incompatible changes.`,
+		Example: `  llm-imager capabilities
+  llm-imager capabilities --json > capabilities.json
+  llm-imager capabilities --json -p stability`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			caps := collectCapabilities(providerFilter)
+			if providerFilter != "" && len(caps.Providers) == 0 {
+				return fmt.Errorf("unknown provider %q", providerFilter)
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(caps)
+			}
+			return printCapabilities(caps)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false,
+		"print the matrix as JSON")
+	cmd.Flags().StringVarP(&providerFilter, "provider", "p", "",
+		"only this provider")
+
+	return cmd
+}
+
+// collectCapabilities builds the matrix of the registered providers,
+// sorted by name, or of one provider
+func collectCapabilities(providerFilter string) capabilities {
+	caps := capabilities{Version: capabilitiesVersion, Features: []string{}}
+
+	providers := registry.ListProviders()
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name() < providers[j].Name() })
+
+	for _, p := range providers {
+		if providerFilter != "" && p.Name() != providerFilter {
+			continue
+		}
+		pc := providerCapabilities{
+			Name:       p.Name(),
+			Configured: checkProviderAPIKey(p.Name()) == nil,
+			Operations: providerOperations(p),
+			Models:     []modelCapabilities{},
+		}
+		if pp, ok := p.(provider.ParamsProvider); ok {
+			pc.Params = pp.SupportedParams()
+		}
+		if ip, ok := p.(provider.InputParamsProvider); ok {
+			pc.AnyParams = ip.AcceptsInputParams()
+		}
+
+		models := p.SupportedModels()
+		sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+		for _, m := range models {
+			mc := modelCapabilities{
+				ID:            m.ID,
+				Name:          m.Name,
+				Operations:    modelOperations(p, m.ID, pc.Operations),
+				Features:      m.Features,
+				Sizes:         m.Sizes,
+				MaxImages:     m.MaxImages,
+				PricePerImage: m.PricePerImage,
+			}
+			if mc.Features == nil {
+				mc.Features = []string{}
+			}
+			if entry, ok := catalog.Current().Lookup(m.ID); ok {
+				mc.MaxSize = entry.MaxSize
+				mc.Deprecated = entry.Deprecated(time.Now())
+				mc.Replacement = entry.Replacement
+			}
+			for _, f := range mc.Features {
+				if !slices.Contains(caps.Features, f) {
+					caps.Features = append(caps.Features, f)
+				}
+			}
+			pc.Models = append(pc.Models, mc)
+		}
+		caps.Providers = append(caps.Providers, pc)
+	}
+
+	sort.Strings(caps.Features)
+	return caps
+}
+
+// providerOperations returns the operations of a provider's optional
+// interfaces. Image-to-image and variations are listed when any model may
+// support them.
+func providerOperations(p provider.Provider) []string {
+	ops := []string{opGenerate}
+	if _, ok := p.(provider.Editor); ok {
+		ops = append(ops, opEdit)
+	}
+	if _, ok := p.(provider.SearchReplacer); ok {
+		ops = append(ops, opSearchReplace)
+	}
+	if _, ok := p.(provider.Recolorer); ok {
+		ops = append(ops, opRecolor)
+	}
+	if _, ok := p.(provider.Outpainter); ok {
+		ops = append(ops, opOutpaint)
+	}
+	if _, ok := p.(provider.BackgroundRemover); ok {
+		ops = append(ops, opRemoveBackground)
+	}
+	if _, ok := p.(provider.Vectorizer); ok {
+		ops = append(ops, opVectorize)
+	}
+	if _, ok := p.(provider.VideoGenerator); ok {
+		ops = append(ops, opVideo)
+	}
+	if _, ok := p.(provider.Variator); ok {
+		ops = append(ops, opVariations)
+	}
+	if _, ok := p.(provider.InitImageProvider); ok {
+		ops = append(ops, opImageToImage)
+	}
+	if _, ok := p.(provider.ControlProvider); ok {
+		ops = append(ops, opControl)
+	}
+	if _, ok := p.(provider.StyleImageProvider); ok {
+		ops = append(ops, opStyleImage)
+	}
+	if _, ok := p.(provider.Describer); ok {
+		ops = append(ops, opDescribe)
+	}
+	if _, ok := p.(provider.Segmenter); ok {
+		ops = append(ops, opSegment)
+	}
+	if _, ok := p.(provider.Prober); ok {
+		ops = append(ops, opProbe)
+	}
+	return ops
+}
+
+// modelOperations narrows the provider operations to a model: variations,
+// image-to-image, control and style images only where the provider accepts
+// them for the model, and edit only for models whose catalog entry lists
+// it, if it has one
+func modelOperations(p provider.Provider, model string, ops []string) []string {
+	entry, known := catalog.Current().Lookup(model)
+
+	var result []string
+	for _, op := range ops {
+		switch op {
+		case opVariations:
+			if v := p.(provider.Variator); !v.AcceptsVariations(model) {
+				continue
+			}
+		case opImageToImage:
+			if ip := p.(provider.InitImageProvider); !ip.AcceptsInitImage(model) {
+				continue
+			}
+		case opControl:
+			cp := p.(provider.ControlProvider)
+			if !slices.ContainsFunc(generator.ControlModes, func(mode string) bool { return cp.AcceptsControl(model, mode) }) {
+				continue
+			}
+		case opStyleImage:
+			if sp := p.(provider.StyleImageProvider); !sp.AcceptsStyleImage(model) {
+				continue
+			}
+		case opEdit:
+			if known && !slices.Contains(entry.Features, "edit") {
+				continue
+			}
+		}
+		result = append(result, op)
+	}
+	return result
+}
+
+// printCapabilities prints the provider × operation matrix and the
+// models of each provider
+func printCapabilities(caps capabilities) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PROVIDER\t%s\tMODELS\n", strings.ToUpper(strings.Join(operations, "\t")))
+	for _, pc := range caps.Providers {
+		row := []string{pc.Name}
+		for _, op := range operations {
+			mark := "-"
+			if slices.Contains(pc.Operations, op) {
+				mark = "yes"
+			}
+			row = append(row, mark)
+		}
+		fmt.Fprintf(w, "%s\t%d\n", strings.Join(row, "\t"), len(pc.Models))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, pc := range caps.Providers {
+		if len(pc.Models) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s\n", pc.Name)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  MODEL\tOPERATIONS\tFEATURES")
+		for _, mc := range pc.Models {
+			features := strings.Join(mc.Features, ", ")
+			if features == "" {
+				features = "-"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", mc.ID, strings.Join(mc.Operations, ", "), features)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
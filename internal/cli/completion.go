@@ -8,8 +8,9 @@ import (
 
 func newCompletionCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "completion [bash|zsh|fish|powershell]",
-		Short: "Generate shell completion script",
+		Use:         "completion [bash|zsh|fish|powershell]",
+		Annotations: map[string]string{skipPluginsAnnotation: "true"},
+		Short:       "Generate shell completion script",
 		Long: `Generate shell completion script for llm-imager.
 
 To load completions:
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
+)
+
+// aspectTolerance is how far the aspect ratio of an image may be from the
+// required one, relative, so that 1024x576 passes as 16:9
+const aspectTolerance = 0.02
+
+// sizeConstraint is --require-size: an exact size, or a minimum or
+// maximum with >= or <=
+type sizeConstraint struct {
+	op            string // "=", ">=" or "<="
+	width, height int
+}
+
+func (c sizeConstraint) String() string {
+	op := c.op
+	if op == "=" {
+		op = ""
+	}
+	return fmt.Sprintf("%s%dx%d", op, c.width, c.height)
+}
+
+// allows reports whether an image of the given size meets the constraint
+func (c sizeConstraint) allows(width, height int) bool {
+	switch c.op {
+	case ">=":
+		return width >= c.width && height >= c.height
+	case "<=":
+		return width <= c.width && height <= c.height
+	default:
+		return width == c.width && height == c.height
+	}
+}
+
+// imageConstraints checks the size and aspect ratio of returned images
+// and warns, fails or generates again with adjusted parameters
+type imageConstraints struct {
+	size    *sizeConstraint
+	aspect  *cropRatio
+	action  string // warn, fail or retry
+	retries int
+}
+
+// newImageConstraints parses --require-size, --require-aspect and
+// --on-violation, or returns nil when nothing is required. Frame sequences
+// are not checked.
+func newImageConstraints(opts *generateOptions) (*imageConstraints, error) {
+	if (opts.requireSize == "" && opts.requireAspect == "") || opts.frames > 1 {
+		return nil, nil
+	}
+
+	c := &imageConstraints{action: opts.onViolation, retries: cfg.Constraints.Retries}
+	switch c.action {
+	case "":
+		c.action = "warn"
+	case "warn", "fail", "retry":
+	default:
+		return nil, fmt.Errorf("unsupported --on-violation %q, supported: warn, fail, retry", c.action)
+	}
+
+	if opts.requireSize != "" {
+		size, err := parseSizeConstraint(opts.requireSize)
+		if err != nil {
+			return nil, err
+		}
+		c.size = &size
+	}
+	if opts.requireAspect != "" {
+		ratios, err := parseCrops([]string{opts.requireAspect})
+		if err != nil || len(ratios) != 1 {
+			return nil, fmt.Errorf("invalid --require-aspect %q, expected W:H such as 16:9", opts.requireAspect)
+		}
+		c.aspect = &ratios[0]
+	}
+	return c, nil
+}
+
+// parseSizeConstraint parses WxH, >=WxH or <=WxH
+func parseSizeConstraint(value string) (sizeConstraint, error) {
+	c := sizeConstraint{op: "="}
+	rest := strings.TrimSpace(value)
+	for _, op := range []string{">=", "<="} {
+		if after, ok := strings.CutPrefix(rest, op); ok {
+			c.op, rest = op, strings.TrimSpace(after)
+			break
+		}
+	}
+	w, h, ok := parseSizeFlag(rest)
+	if !ok {
+		return c, fmt.Errorf("invalid --require-size %q, expected WxH, >=WxH or <=WxH", value)
+	}
+	c.width, c.height = w, h
+	return c, nil
+}
+
+// verify checks the images of resp. With the retry action it adjusts the
+// size and aspect ratio of req to the constraints and generates again up
+// to the retry limit, keeping the response with the fewest violations;
+// violations left fail the generation under the fail and retry actions
+// and are warnings otherwise.
+func (c *imageConstraints) verify(req *generator.Request, resp *generator.Response, generate func() (*generator.Response, error)) (*generator.Response, error) {
+	best, bestViolations := resp, c.check(resp.Images)
+	if c.action == "retry" {
+		for attempt := 1; len(bestViolations) > 0 && attempt <= c.retries; attempt++ {
+			c.adjust(req)
+			fmt.Printf("Constraint not met: %s; generating again with size %q and aspect ratio %q (retry %d of %d)...\n",
+				bestViolations[0], req.Size, req.AspectRatio, attempt, c.retries)
+			retry, err := generate()
+			if err != nil {
+				return nil, err
+			}
+			if violations := c.check(retry.Images); len(violations) < len(bestViolations) {
+				best, bestViolations = retry, violations
+			}
+		}
+	}
+
+	if len(bestViolations) == 0 {
+		return best, nil
+	}
+	if c.action == "warn" {
+		for _, v := range bestViolations {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", v)
+		}
+		return best, nil
+	}
+	return nil, fmt.Errorf("%s", strings.Join(bestViolations, "; "))
+}
+
+// check returns a description of each image breaking the constraints.
+// Hosted and vector images have no pixels to measure.
+func (c *imageConstraints) check(images []generator.Image) []string {
+	var violations []string
+	for _, img := range images {
+		if !describable(img) {
+			continue
+		}
+		width, height := img.Width, img.Height
+		if width <= 0 || height <= 0 {
+			decoded, err := imaging.Decode(img)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: cannot check the size of image %d: %v\n", img.Index+1, err)
+				continue
+			}
+			b := decoded.Bounds()
+			width, height = b.Dx(), b.Dy()
+		}
+
+		if c.size != nil && !c.size.allows(width, height) {
+			violations = append(violations, fmt.Sprintf("image %d is %dx%d, required %s", img.Index+1, width, height, c.size))
+		} else if c.aspect != nil && !c.aspectMatches(width, height) {
+			violations = append(violations, fmt.Sprintf("image %d is %dx%d, required aspect ratio %d:%d",
+				img.Index+1, width, height, c.aspect.width, c.aspect.height))
+		}
+	}
+	return violations
+}
+
+// aspectMatches reports whether a size has the required aspect ratio
+// within aspectTolerance
+func (c *imageConstraints) aspectMatches(width, height int) bool {
+	got := float64(width) / float64(height)
+	want := float64(c.aspect.width) / float64(c.aspect.height)
+	return math.Abs(got/want-1) <= aspectTolerance
+}
+
+// adjust sets the request size and aspect ratio to what the constraints
+// require: the size of a size constraint, the aspect ratio of an aspect
+// constraint, and a requested size reshaped to that aspect ratio
+func (c *imageConstraints) adjust(req *generator.Request) {
+	if c.size != nil {
+		req.Size = fmt.Sprintf("%dx%d", c.size.width, c.size.height)
+	}
+	if c.aspect == nil {
+		return
+	}
+	req.AspectRatio = fmt.Sprintf("%d:%d", c.aspect.width, c.aspect.height)
+	if width, height, ok := parseSizeFlag(req.Size); ok && !c.aspectMatches(width, height) {
+		// Keep the longer side and round the other to a multiple of 16
+		long := max(width, height)
+		if c.aspect.width >= c.aspect.height {
+			width, height = long, roundTo16(long*c.aspect.height, c.aspect.width)
+		} else {
+			width, height = roundTo16(long*c.aspect.width, c.aspect.height), long
+		}
+		req.Size = fmt.Sprintf("%dx%d", width, height)
+	}
+}
+
+// roundTo16 returns n/d rounded to the nearest multiple of 16, at least 16
+func roundTo16(n, d int) int {
+	return max(int(math.Round(float64(n)/float64(d)/16))*16, 16)
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
+	"github.com/piligrim/llm-imager/internal/output"
+)
+
+// defaultCropName names crop variants after the image they are cut from,
+// e.g. out_4x5.png
+const defaultCropName = "{{.Base}}_{{.Ratio}}{{.Ext}}"
+
+// cropRatio is an aspect ratio of --crops
+type cropRatio struct {
+	width, height int
+}
+
+func (r cropRatio) String() string {
+	return fmt.Sprintf("%dx%d", r.width, r.height)
+}
+
+// cropNameData is the data available to the output.crop_name template
+type cropNameData struct {
+	Base  string // Output path of the image without extension, e.g. out/fox_2
+	Dir   string // Directory of the output path
+	Name  string // File name of the image without extension, e.g. fox_2
+	Index int    // Image number, from 1
+	Ratio string // Aspect ratio, e.g. 4x5
+	Ext   string // Extension of the crop, .png
+}
+
+// parseCrops parses the comma-separated aspect ratios of --crops, e.g.
+// 1:1,4:5,16:9,9:16; "none" turns off crops set in the config
+func parseCrops(values []string) ([]cropRatio, error) {
+	var ratios []cropRatio
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "none" {
+			return nil, nil
+		}
+		if v == "" {
+			continue
+		}
+		w, h, ok := strings.Cut(v, ":")
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("invalid crop ratio %q, expected W:H such as 4:5", v)
+		}
+		ratios = append(ratios, cropRatio{width: width, height: height})
+	}
+	return ratios, nil
+}
+
+// parseCropName compiles the output.crop_name template
+func parseCropName(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultCropName
+	}
+	tmpl, err := template.New("crop_name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid crop name template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeCrops cuts the centered crop variants of each image and saves them
+// under names rendered from the template, next to the image at
+// outputPath. It returns the saved paths.
+func writeCrops(writer *output.Writer, images []generator.Image, outputPath string, ratios []cropRatio, name *template.Template) ([]string, error) {
+	ext := filepath.Ext(outputPath)
+	base := strings.TrimSuffix(outputPath, ext)
+
+	var paths []string
+	for i, img := range images {
+		if img.Format == "svg" {
+			fmt.Fprintf(os.Stderr, "Warning: skipping crops of vector image %d\n", img.Index+1)
+			continue
+		}
+		if img.IsVideo() {
+			fmt.Fprintf(os.Stderr, "Warning: skipping crops of video %d\n", img.Index+1)
+			continue
+		}
+		decoded, err := imaging.Decode(img)
+		if err != nil {
+			return paths, err
+		}
+
+		data := cropNameData{
+			Base:  base,
+			Dir:   filepath.Dir(outputPath),
+			Index: img.Index + 1,
+			Ext:   ".png",
+		}
+		if len(images) > 1 {
+			data.Base = fmt.Sprintf("%s_%d", base, i+1)
+		}
+		data.Name = filepath.Base(data.Base)
+
+		for _, ratio := range ratios {
+			crop, err := imaging.EncodePNG(imaging.CropAspect(decoded, ratio.width, ratio.height))
+			if err != nil {
+				return paths, err
+			}
+
+			data.Ratio = ratio.String()
+			var b strings.Builder
+			if err := name.Execute(&b, data); err != nil {
+				return paths, fmt.Errorf("crop name: %w", err)
+			}
+			path := b.String()
+			if filepath.Ext(path) == "" {
+				path += data.Ext
+			}
+
+			saved, err := writer.Write([]generator.Image{crop}, path)
+			if err != nil {
+				return paths, err
+			}
+			paths = append(paths, saved...)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// captionPrompt asks a vision model for a detailed caption
+const captionPrompt = "Describe this image in detail: the subject, setting, composition, " +
+	"lighting, colors and style. Reply with the description only."
+
+// generationPromptPrompt asks a vision model for a prompt recreating the image
+const generationPromptPrompt = "Write a prompt for an image generation model that would produce " +
+	"an image like this one: the subject, setting, composition, lighting, color palette, " +
+	"medium and style, as a single paragraph. Reply with the prompt only, without quotes."
+
+func newDescribeCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var image string
+	var asPrompt bool
+	var question string
+
+	cmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Describe an image with a vision model",
+		Long: `Send an image to a vision model and print a detailed caption, or with
+--as-prompt a prompt that would generate a similar image. Only the text is
+printed, so it can be fed back into generate.
+
+The model is -m, else vision.model from the config (OpenAI, Google Gemini
+and OpenRouter vision models).`,
+		Example: `  llm-imager describe --image photo.jpg
+  llm-imager describe --image photo.jpg --as-prompt -m google/gemini-2.5-flash
+  llm-imager -p "$(llm-imager describe --image photo.jpg --as-prompt)" -m openai/dall-e-3 -o similar.png`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if asPrompt && question != "" {
+				return fmt.Errorf("use either --as-prompt or --question, not both")
+			}
+			prompt := captionPrompt
+			if asPrompt {
+				prompt = generationPromptPrompt
+			} else if question != "" {
+				prompt = question
+			}
+
+			if !cmd.Flags().Changed("dry-run") && cfg.Defaults.DryRun {
+				opts.dryRun = true
+			}
+
+			img, err := readInputImage(image)
+			if err != nil {
+				return err
+			}
+			describer, model, err := visionDescriber(opts, "")
+			if err != nil {
+				return err
+			}
+			text, err := describer.Describe(cmd.Context(), model, prompt, img)
+			if err != nil {
+				return withHint(fmt.Errorf("describing the image failed: %w", err))
+			}
+			fmt.Println(strings.TrimSpace(text))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "",
+		"image file to describe (required)")
+	cmd.Flags().BoolVar(&asPrompt, "as-prompt", false,
+		"print a generation prompt recreating the image instead of a caption")
+	cmd.Flags().StringVar(&question, "question", "",
+		"ask the vision model this instead of a caption")
+	cmd.Flags().StringVarP(&opts.visionModel, "model", "m", "",
+		"vision model (default from vision.model)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"print a placeholder description without API calls")
+
+	cmd.MarkFlagRequired("image")
+
+	return cmd
+}
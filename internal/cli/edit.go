@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// editImageTypes are the input image formats the edit endpoints accept
+var editImageTypes = []string{"image/png", "image/jpeg", "image/webp"}
+
+// searchReplaceModel is the model of edit --search when none is given;
+// all Stability models use the same search-and-replace endpoint
+const searchReplaceModel = "stability/stable-image-core"
+
+func newEditCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var replacePrompt string
+
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit an existing image with a text prompt",
+		Long: `Edit images using the edit endpoints of providers that have one:
+OpenAI (gpt-image-1, dall-e-2), Stability AI (inpaint) and Google Gemini.
+
+A mask limits the edit to part of the first image: OpenAI edits its
+transparent areas, Stability its white areas. Gemini takes no mask.
+--mask-prompt draws the mask from a description instead, with a
+segmentation model on Replicate (--mask-model).
+
+--search replaces an object found by description instead of by mask, with
+Stability AI search-and-replace: --replace-prompt (or -p) describes what
+replaces it. Without -m, ` + searchReplaceModel + ` is used.`,
+		Example: `  llm-imager edit --image room.png -p "add a green sofa" -m openai/gpt-image-1 -o room2.png
+  llm-imager edit --image room.png --mask sofa.png -p "a leather armchair" -m stability/stable-image-core -o room3.png
+  llm-imager edit --image cat.jpg --image hat.png -p "put the hat on the cat" -m google/gemini-2.5-flash-image -o cat.png
+  llm-imager edit --image beach.png --mask-prompt "the sky" -p "a stormy sunset sky" -m stability/stable-image-core -o beach2.png
+  llm-imager edit --image street.png --search "red car" --replace-prompt "blue car" -o street2.png`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if replacePrompt != "" {
+				if opts.search == "" {
+					return fmt.Errorf("--replace-prompt requires --search")
+				}
+				if opts.prompt != "" {
+					return fmt.Errorf("use either --prompt or --replace-prompt, not both")
+				}
+				opts.prompt = replacePrompt
+			}
+			if opts.prompt == "" {
+				if opts.search != "" {
+					return fmt.Errorf("--search requires --replace-prompt")
+				}
+				return fmt.Errorf("required flag(s) \"prompt\" not set")
+			}
+			if opts.maskPrompt != "" {
+				if opts.mask != "" {
+					return fmt.Errorf("use either --mask or --mask-prompt, not both")
+				}
+				if opts.search != "" {
+					return fmt.Errorf("--search finds the area to replace itself; it takes no --mask-prompt")
+				}
+			}
+			if opts.fidelity != "" && opts.fidelity != "low" && opts.fidelity != "high" {
+				return fmt.Errorf("invalid --fidelity %q, valid: low, high", opts.fidelity)
+			}
+			if opts.search != "" {
+				if opts.mask != "" {
+					return fmt.Errorf("--search finds the area to replace itself; it takes no --mask")
+				}
+				if opts.model == "" && opts.providerName == "" {
+					opts.model = searchReplaceModel
+				}
+			}
+
+			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.inputImages, "image", nil,
+		"image file to edit, repeatable for providers taking several (required)")
+	cmd.Flags().StringVar(&opts.mask, "mask", "",
+		"mask image marking the area of the first image to edit")
+	cmd.Flags().StringVar(&opts.maskPrompt, "mask-prompt", "",
+		"draw the mask from a description of the area to edit, e.g. \"the sky\"")
+	cmd.Flags().StringVar(&opts.maskModel, "mask-model", "replicate/"+provider.ReplicateSegmentModel,
+		"Replicate segmentation model drawing the --mask-prompt mask")
+	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
+		"description of the edit (required)")
+	cmd.Flags().StringVar(&opts.search, "search", "",
+		"object to find and replace instead of using a mask (Stability AI)")
+	cmd.Flags().StringVar(&replacePrompt, "replace-prompt", "",
+		"what replaces the --search object")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output file path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
+		"model to use (e.g., openai/gpt-image-1)")
+	cmd.Flags().StringVar(&opts.providerName, "provider", "",
+		"explicit provider (openai/stability/google)")
+	cmd.Flags().StringVar(&opts.size, "size", "",
+		"output size (OpenAI)")
+	cmd.Flags().StringVar(&opts.quality, "quality", "",
+		"image quality (GPT Image 1: low/medium/high)")
+	cmd.Flags().StringVar(&opts.fidelity, "fidelity", "",
+		"how closely the edit keeps details of the input images such as faces: low or high (GPT Image 1)")
+	cmd.Flags().IntVarP(&opts.count, "count", "n", 0,
+		"number of edited images (OpenAI)")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0,
+		"seed for reproducibility (Stability AI)")
+	cmd.Flags().StringVar(&opts.negativePrompt, "negative-prompt", "",
+		"negative prompt (Stability AI)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate placeholder images without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.autoCorrect, "auto-correct", false,
+		"use the closest matching model if the model is not found")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false,
+		"fail instead of warning when the model is deprecated")
+	cmd.Flags().StringSliceVar(&opts.crops, "crops", nil,
+		"also save centered crops with these aspect ratios, or none (default from output.crops)")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().IntVar(&opts.palette, "palette", 0,
+		"record the N dominant colors of each image in its metadata sidecar (default from output.palette)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed edits to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("image")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// loadInputImages reads the --image and --mask files of an edit into the
+// request
+func loadInputImages(req *generator.Request, paths []string, mask string) error {
+	for _, path := range paths {
+		img, err := readInputImage(path)
+		if err != nil {
+			return err
+		}
+		req.InputImages = append(req.InputImages, img)
+	}
+	if mask != "" {
+		img, err := readInputImage(mask)
+		if err != nil {
+			return err
+		}
+		req.Mask = &img
+	}
+	return nil
+}
+
+// readInputImage reads an image file, detecting its type from the content
+func readInputImage(path string) (generator.InputImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return generator.InputImage{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	mimeType := http.DetectContentType(data)
+	if !slices.Contains(editImageTypes, mimeType) {
+		return generator.InputImage{}, fmt.Errorf("%s is %s, expected a PNG, JPEG or WebP image", path, mimeType)
+	}
+	return generator.InputImage{
+		Name:     filepath.Base(path),
+		MIMEType: mimeType,
+		Data:     data,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math/rand/v2"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// sweepParams lists the request parameters that can be varied per frame
+var sweepParams = []string{"seed", "steps", "quality", "style"}
+
+// sweep describes how the request changes between frames
+type sweep struct {
+	param  string
+	values []string // Explicit values; empty means consecutive seeds
+}
+
+// parseSweep parses a --vary value: "seed" for consecutive seeds, or
+// "param=v1,v2,..." for explicit values
+func parseSweep(spec string) (*sweep, error) {
+	param, list, hasValues := strings.Cut(spec, "=")
+	param = strings.TrimSpace(param)
+
+	known := false
+	for _, p := range sweepParams {
+		if p == param {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("unsupported --vary %q, supported: %s", param, strings.Join(sweepParams, ", "))
+	}
+
+	s := &sweep{param: param}
+	if !hasValues {
+		if param != "seed" {
+			return nil, fmt.Errorf("--vary %s requires values, e.g. %s=a,b,c", param, param)
+		}
+		return s, nil
+	}
+
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			s.values = append(s.values, v)
+		}
+	}
+	if len(s.values) == 0 {
+		return nil, fmt.Errorf("--vary %s has no values", param)
+	}
+	return s, nil
+}
+
+// apply sets the swept parameter of req for a frame
+func (s *sweep) apply(req *generator.Request, value string) error {
+	switch s.param {
+	case "seed":
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed %q", value)
+		}
+		req.Seed = &seed
+	case "steps":
+		steps, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid steps %q", value)
+		}
+		req.Steps = steps
+	case "quality":
+		req.Quality = value
+	case "style":
+		req.Style = value
+	}
+	return nil
+}
+
+// validateFrameOptions checks the animation flags and fills defaults
+func validateFrameOptions(opts *generateOptions) error {
+	if opts.vary == "" {
+		opts.vary = "seed"
+	}
+
+	s, err := parseSweep(opts.vary)
+	if err != nil {
+		return err
+	}
+	if len(s.values) > 0 {
+		if opts.frames > 0 && opts.frames != len(s.values) {
+			return fmt.Errorf("--frames %d does not match %d --vary values", opts.frames, len(s.values))
+		}
+		opts.frames = len(s.values)
+	}
+	opts.sweep = s
+
+	if opts.frames <= 1 {
+		return nil
+	}
+
+	if opts.assemble == "" {
+		switch strings.ToLower(filepath.Ext(opts.outputPath)) {
+		case ".gif":
+			opts.assemble = "gif"
+		case ".apng":
+			opts.assemble = "apng"
+		default:
+			opts.assemble = "sheet"
+		}
+	}
+	if opts.assemble != "sheet" && opts.assemble != "gif" && opts.assemble != "apng" {
+		return fmt.Errorf("unsupported --assemble %q, supported: sheet, gif, apng", opts.assemble)
+	}
+
+	return nil
+}
+
+// generateFrames generates one image per frame. Seed sweeps without
+// explicit values use consecutive seeds from a common base so frames stay
+// coherent and reproducible. It returns the merged response and a label
+// per frame.
+func generateFrames(ctx context.Context, p provider.Provider, req *generator.Request, opts *generateOptions) (*generator.Response, []string, error) {
+	values := opts.sweep.values
+	if len(values) == 0 {
+		base := rand.Int64N(1 << 31)
+		if req.Seed != nil {
+			base = *req.Seed
+		}
+		for i := range opts.frames {
+			values = append(values, strconv.FormatInt(base+int64(i), 10))
+		}
+	}
+
+	start := time.Now()
+	var merged *generator.Response
+	labels := make([]string, 0, len(values))
+
+	for i, value := range values {
+		frameReq := *req
+		frameReq.Count = 1
+		if err := opts.sweep.apply(&frameReq, value); err != nil {
+			return nil, nil, err
+		}
+
+		label := fmt.Sprintf("%s %s", opts.sweep.param, value)
+		fmt.Printf("Frame %d/%d (%s)...\n", i+1, len(values), label)
+
+		resp, err := p.Generate(ctx, &frameReq)
+		if err != nil {
+			return nil, nil, fmt.Errorf("frame %d: %w", i+1, err)
+		}
+		if len(resp.Images) == 0 {
+			return nil, nil, fmt.Errorf("frame %d: no images in response", i+1)
+		}
+
+		img := resp.Images[0]
+		img.Seed = frameReq.Seed
+		img.Index = i
+
+		if merged == nil {
+			merged = resp
+			merged.Images = nil
+		}
+		merged.Images = append(merged.Images, img)
+		labels = append(labels, label)
+	}
+
+	merged.Duration = time.Since(start)
+	return merged, labels, nil
+}
+
+// assembleFrames combines frames into a sprite sheet, animated GIF or
+// animated PNG, optionally labeling each frame
+func assembleFrames(frames []generator.Image, labels []string, opts *generateOptions) (generator.Image, error) {
+	decoded, err := imaging.DecodeAll(frames)
+	if err != nil {
+		return generator.Image{}, err
+	}
+
+	if opts.labelFrames {
+		labeled := make([]image.Image, len(decoded))
+		for i, img := range decoded {
+			labeled[i] = imaging.Label(img, labels[i])
+		}
+		decoded = labeled
+	}
+
+	switch opts.assemble {
+	case "gif":
+		return imaging.EncodeGIF(decoded, opts.frameDelay)
+	case "apng":
+		return imaging.EncodeAPNG(decoded, opts.frameDelay)
+	default:
+		return imaging.EncodePNG(imaging.SpriteSheet(decoded, 0))
+	}
+}
+
+// withFormatExt replaces the extension of path to match format
+func withFormatExt(path, format string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" || ext == "."+format || (ext == ".apng" && format == "png") {
+		return path
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+}
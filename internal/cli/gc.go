@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/output"
+	"github.com/piligrim/llm-imager/internal/probe"
+	"github.com/piligrim/llm-imager/internal/run"
+)
+
+func newGCCmd() *cobra.Command {
+	var olderThan string
+	var keepTagged bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:         "gc",
+		Annotations: map[string]string{skipPluginsAnnotation: "true"},
+		Short:       "Prune stale cache entries, temporary files and old outputs",
+		Long: `Remove data that is no longer useful and report the reclaimed space:
+
+  - probe results older than --older-than (default: cache.max_age from config)
+  - a downloaded model catalog that is not newer than the embedded one
+  - temporary files of interrupted writes older than --older-than
+  - with output.retention set, images of run directories and of the cas
+    store older than --older-than (default: output.retention), with their
+    sidecars; --keep-tagged keeps images whose sidecar records tags
+
+Images saved outside run directories and the cas store are never touched.`,
+		Example: `  llm-imager gc
+  llm-imager gc --older-than 7d --dry-run
+  llm-imager gc --older-than 30d --keep-tagged`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxAge, retention := cfg.Cache.MaxAge, cfg.Output.Retention
+			pruneOutputs := retention > 0
+			if olderThan != "" {
+				age, err := parseAge(olderThan)
+				if err != nil {
+					return err
+				}
+				maxAge, retention = age, age
+			}
+
+			now := time.Now()
+			var outputCutoff time.Time
+			if pruneOutputs {
+				outputCutoff = now.Add(-retention)
+			}
+			return runGC(now.Add(-maxAge), outputCutoff, keepTagged, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "",
+		"prune entries older than this age (e.g. 30d, 12h)")
+	cmd.Flags().BoolVar(&keepTagged, "keep-tagged", false,
+		"keep images whose metadata sidecar records tags")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"report what would be removed without removing it")
+
+	return cmd
+}
+
+// runGC prunes cache entries and temporary files older than cutoff and,
+// unless outputCutoff is zero, the images of runs and of the cas store
+// older than outputCutoff
+func runGC(cutoff, outputCutoff time.Time, keepTagged, dryRun bool) error {
+	var reclaimed int64
+
+	// Probe results
+	probePath := probe.DefaultCachePath()
+	before := fileSize(probePath)
+	cache, err := probe.Load(probePath)
+	if err != nil {
+		return err
+	}
+	if removed := cache.Prune(cutoff); removed > 0 {
+		fmt.Printf("Probe cache: %d stale entries\n", removed)
+		if !dryRun {
+			if err := cache.Save(); err != nil {
+				return fmt.Errorf("failed to save probe cache: %w", err)
+			}
+			reclaimed += before - fileSize(probePath)
+		}
+	}
+
+	// Catalog override superseded by the embedded catalog
+	overridePath := catalog.DefaultOverridePath()
+	if data, err := os.ReadFile(overridePath); err == nil {
+		override, err := catalog.Parse(data)
+		if err != nil || !override.Updated().After(catalog.Embedded().Updated()) {
+			fmt.Printf("Catalog: %s is not newer than the embedded catalog\n", overridePath)
+			if !dryRun {
+				if err := os.Remove(overridePath); err != nil {
+					return fmt.Errorf("failed to remove catalog: %w", err)
+				}
+				reclaimed += int64(len(data))
+			}
+		}
+	}
+
+	// Temporary files of interrupted writes
+	temps, err := output.PreflightTempFiles(cfg.Output.Directory, cutoff)
+	if err != nil {
+		return err
+	}
+	if cfg.Output.Layout == "cas" {
+		casTemps, err := output.CASTempFiles(cfg.Output.Directory, cutoff)
+		if err != nil {
+			return err
+		}
+		temps = append(temps, casTemps...)
+	}
+	if len(temps) > 0 {
+		fmt.Printf("Temporary files: %d\n", len(temps))
+		for _, path := range temps {
+			size, err := removeFile(path, dryRun)
+			if err != nil {
+				return err
+			}
+			reclaimed += size
+		}
+	}
+
+	// Outputs past output.retention
+	if !outputCutoff.IsZero() {
+		removed, size, err := pruneOutputs(outputCutoff, keepTagged, dryRun)
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			fmt.Printf("Outputs: %d images\n", removed)
+			reclaimed += size
+		}
+	}
+
+	if dryRun {
+		fmt.Println("Dry run: nothing removed")
+		return nil
+	}
+	fmt.Printf("Reclaimed %s\n", formatBytes(reclaimed))
+	return nil
+}
+
+// pruneOutputs removes the images of run directories and of the cas store
+// saved before cutoff, with their sidecars and alt text files. Images a run
+// saved outside its directory are left alone. It returns the number of
+// images removed and the space reclaimed.
+func pruneOutputs(cutoff time.Time, keepTagged, dryRun bool) (int, int64, error) {
+	var removed int
+	var reclaimed int64
+	prune := func(path string, saved time.Time) (bool, error) {
+		if !saved.Before(cutoff) || (keepTagged && tagged(path)) {
+			return false, nil
+		}
+		size, err := removeOutput(path, dryRun)
+		if err != nil {
+			return false, err
+		}
+		removed++
+		reclaimed += size
+		return true, nil
+	}
+
+	manifests, err := run.History(runsDir())
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, m := range manifests {
+		for _, image := range m.Images {
+			if !filepath.IsLocal(image) {
+				continue
+			}
+			path := filepath.Join(runsDir(), m.ID, image)
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if _, err := prune(path, info.ModTime()); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	if cfg.Output.Layout != "cas" {
+		return removed, reclaimed, nil
+	}
+	objects, err := output.CASObjects(cfg.Output.Directory)
+	if err != nil {
+		return 0, 0, err
+	}
+	dropped := make(map[string]bool)
+	for object, saved := range objects {
+		ok, err := prune(filepath.Join(cfg.Output.Directory, filepath.FromSlash(object)), saved)
+		if err != nil {
+			return 0, 0, err
+		}
+		if ok {
+			dropped[object] = true
+		}
+	}
+	if len(dropped) > 0 && !dryRun {
+		if err := output.DropCASObjects(cfg.Output.Directory, dropped); err != nil {
+			return 0, 0, err
+		}
+	}
+	return removed, reclaimed, nil
+}
+
+// tagged reports whether the metadata sidecar of the image at path
+// records tags
+func tagged(path string) bool {
+	data, err := os.ReadFile(path + ".json")
+	if err != nil {
+		return false
+	}
+	var meta imageMetadata
+	return json.Unmarshal(data, &meta) == nil && len(meta.Tags) > 0
+}
+
+// removeOutput removes an image with its sidecar and alt text file,
+// returning their total size
+func removeOutput(path string, dryRun bool) (int64, error) {
+	var reclaimed int64
+	altText := strings.TrimSuffix(path, filepath.Ext(path)) + ".txt"
+	for _, p := range []string{path, path + ".json", altText} {
+		size, err := removeFile(p, dryRun)
+		if err != nil {
+			return 0, err
+		}
+		reclaimed += size
+	}
+	return reclaimed, nil
+}
+
+// removeFile removes a file unless dryRun is set, returning its size.
+// A missing file is not an error.
+func removeFile(path string, dryRun bool) (int64, error) {
+	size := fileSize(path)
+	if dryRun {
+		return size, nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// parseAge parses a duration that may use a day suffix, e.g. "30d"
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (e.g. 30d, 12h)", s)
+	}
+	return d, nil
+}
+
+// fileSize returns the size of a file, or 0 if it does not exist
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
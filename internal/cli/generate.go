@@ -2,34 +2,112 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/piligrim/llm-imager/internal/catalog"
 	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
 	"github.com/piligrim/llm-imager/internal/output"
 	"github.com/piligrim/llm-imager/internal/provider"
+	"github.com/piligrim/llm-imager/internal/run"
+	"github.com/piligrim/llm-imager/pkg/httputil"
 )
 
 type generateOptions struct {
-	model          string
-	prompt         string
-	outputPath     string
-	size           string
-	quality        string
-	style          string
-	count          int
-	seed           int64
-	hasSeed        bool
-	negativePrompt string
-	aspectRatio    string
-	steps          int
-	providerName   string
-	dryRun         bool
-	hasDryRun      bool
+	model              string
+	prompt             string
+	outputPath         string
+	size               string
+	quality            string
+	style              string
+	count              int
+	seed               int64
+	hasSeed            bool
+	negativePrompt     string
+	aspectRatio        string
+	steps              int
+	providerName       string
+	dryRun             bool
+	hasDryRun          bool
+	plan               bool
+	autoCorrect        bool
+	strict             bool
+	frames             int
+	vary               string
+	assemble           string
+	frameDelay         time.Duration
+	keepFrames         bool
+	labelFrames        bool
+	sweep              *sweep
+	tile               bool
+	upscaleAfter       string
+	upscaleFactor      int
+	autoUpscale        bool
+	crops              []string
+	metadata           bool
+	hasMetadata        bool
+	palette            int
+	altText            bool
+	hasAltText         bool
+	altTextFile        bool
+	visionModel        string
+	autoTag            bool
+	hasAutoTag         bool
+	tagModel           string
+	ocr                bool
+	hasOCR             bool
+	expectText         []string
+	ocrRetries         int
+	requireSize        string
+	requireAspect      string
+	onViolation        string
+	styleType          string
+	magicPrompt        string
+	safetyTolerance    int
+	hasSafetyTolerance bool
+	imageRefs          []string
+	inputImages        []string // Images to edit (edit command)
+	mask               string
+	maskPrompt         string
+	maskModel          string
+	search             string // Object to replace (edit --search)
+	fidelity           string // low or high (edit --fidelity)
+	selectObject       string // Object to recolor (recolor command)
+	variationsOf       string // Image to make variations of (variations command)
+	outpaint           *generator.Outpaint
+	removeBackground   bool
+	vectorize          bool
+	video              bool
+	videoDuration      int // Seconds (video --duration)
+	fps                int
+	initImage          string
+	strength           float64
+	hasStrength        bool
+	styleImage         string
+	controlImage       string
+	controlMode        string
+	controlStrength    float64
+	hasControlStrength bool
+	params             []string
+	saveRawDir         string
+	urlOnly            bool
+	onConflict         string
+	run                bool
+	hasRun             bool
+	progressJSON       string
+	progress           *progressReporter
+	promptFile         string // One prompt per line (--prompt-file)
+	validateOnly       bool   // Check the request without generating (batch validation)
 }
 
 func newGenerateCmd() *cobra.Command {
@@ -45,20 +123,65 @@ or just the model name if the provider can be auto-detected.`,
 		Aliases: []string{"gen", "g"},
 		Example: `  llm-imager generate -p "a beautiful landscape" -o landscape.png
   llm-imager g -m openai/dall-e-3 -p "abstract art" -o art.png
-  llm-imager generate -m stability/stable-image-core -p "cyberpunk city" --negative-prompt "blurry" -o city.png`,
+  llm-imager generate -m stability/stable-image-core -p "cyberpunk city" --negative-prompt "blurry" -o city.png
+  llm-imager generate --prompt-file prompts.txt -o dataset/img.png`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkPromptFlags(cmd); err != nil {
+				return err
+			}
 			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasSafetyTolerance = cmd.Flags().Changed("safety-tolerance")
 			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			opts.hasAltText = cmd.Flags().Changed("alt-text")
+			opts.hasAutoTag = cmd.Flags().Changed("auto-tag")
+			opts.hasOCR = cmd.Flags().Changed("ocr")
+			opts.hasStrength = cmd.Flags().Changed("strength")
+			opts.hasControlStrength = cmd.Flags().Changed("control-strength")
+			if opts.promptFile != "" {
+				return runPromptFile(cmd.Context(), opts)
+			}
 			return runGenerate(cmd.Context(), opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
-		"model to use (e.g., google/gemini-2.5-flash-image)")
 	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
-		"text prompt for image generation (required)")
+		"text prompt for image generation (required unless --prompt-file)")
 	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
 		"output file path (required)")
+	addPromptFileFlag(cmd, opts)
+	addGenerateFlags(cmd, opts)
+
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// addPromptFileFlag registers --prompt-file on the root and generate
+// commands
+func addPromptFileFlag(cmd *cobra.Command, opts *generateOptions) {
+	cmd.Flags().StringVar(&opts.promptFile, "prompt-file", "",
+		"file with one prompt per line to generate in turn, numbering the output path (out.png -> out_001.png, ...)")
+}
+
+// checkPromptFlags requires exactly one of --prompt and --prompt-file
+func checkPromptFlags(cmd *cobra.Command) error {
+	hasPrompt, hasFile := cmd.Flags().Changed("prompt"), cmd.Flags().Changed("prompt-file")
+	if hasPrompt && hasFile {
+		return fmt.Errorf("--prompt and --prompt-file cannot be combined")
+	}
+	if !hasPrompt && !hasFile {
+		return fmt.Errorf("required flag \"prompt\" or \"prompt-file\" not set")
+	}
+	return nil
+}
+
+// addGenerateFlags registers the generation flags shared by the root and
+// generate commands; prompt and output are registered by each command
+func addGenerateFlags(cmd *cobra.Command, opts *generateOptions) {
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
+		"model to use (e.g., google/gemini-2.5-flash-image)")
 	cmd.Flags().StringVar(&opts.size, "size", "",
 		"image size (e.g., 1024x1024)")
 	cmd.Flags().StringVar(&opts.quality, "quality", "",
@@ -69,6 +192,28 @@ or just the model name if the provider can be auto-detected.`,
 		"number of images to generate")
 	cmd.Flags().Int64Var(&opts.seed, "seed", 0,
 		"seed for reproducibility")
+	cmd.Flags().StringVar(&opts.styleType, "style-type", "",
+		"provider style type (Ideogram: auto/general/realistic/design/fiction, Recraft: e.g. digital_illustration)")
+	cmd.Flags().StringVar(&opts.magicPrompt, "magic-prompt", "",
+		"automatic prompt expansion: auto/on/off (Ideogram, Black Forest Labs)")
+	cmd.Flags().IntVar(&opts.safetyTolerance, "safety-tolerance", 0,
+		"moderation level from 0 (strict) to 6 (permissive) (Black Forest Labs)")
+	cmd.Flags().StringArrayVar(&opts.imageRefs, "image-ref", nil,
+		"reference image URL guiding the composition, repeatable (Luma)")
+	cmd.Flags().StringVar(&opts.initImage, "init-image", "",
+		"image file to start from (image-to-image: Stability, Replicate)")
+	cmd.Flags().Float64Var(&opts.strength, "strength", 0,
+		"how much --init-image changes, from 0 (kept) to 1 (replaced)")
+	cmd.Flags().StringVar(&opts.styleImage, "style-image", "",
+		"reference image whose style the result takes (Stability, Ideogram, Replicate FLUX Redux)")
+	cmd.Flags().StringVar(&opts.controlImage, "control-image", "",
+		"image file guiding the composition: a photo, depth map or drawing (Stability, Replicate ControlNet)")
+	cmd.Flags().StringVar(&opts.controlMode, "control-mode", generator.ControlStructure,
+		"what --control-image guides: structure, sketch or style")
+	cmd.Flags().Float64Var(&opts.controlStrength, "control-strength", 0,
+		"how closely --control-image is followed, from 0 to 1")
+	cmd.Flags().StringArrayVar(&opts.params, "param", nil,
+		"provider-specific option as key=value or key:=json, repeatable (e.g. --param sampler=Euler)")
 	cmd.Flags().StringVar(&opts.negativePrompt, "negative-prompt", "",
 		"negative prompt (things to avoid)")
 	cmd.Flags().StringVar(&opts.aspectRatio, "aspect-ratio", "",
@@ -79,17 +224,135 @@ or just the model name if the provider can be auto-detected.`,
 		"explicit provider (openai/google/stability/replicate/openrouter)")
 	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
 		"generate placeholder images without API calls")
-
-	cmd.MarkFlagRequired("prompt")
-	cmd.MarkFlagRequired("output")
-
-	return cmd
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.autoCorrect, "auto-correct", false,
+		"use the closest matching model if the model is not found")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false,
+		"fail instead of warning when the model is deprecated")
+	cmd.Flags().IntVar(&opts.frames, "frames", 0,
+		"generate N variations and assemble them into a sprite sheet or GIF")
+	cmd.Flags().StringVar(&opts.vary, "vary", "seed",
+		"what to vary between frames: seed, or param=v1,v2 for seed/steps/quality/style")
+	cmd.Flags().StringVar(&opts.assemble, "assemble", "",
+		"frame assembly: sheet, gif or apng (default: from output extension)")
+	cmd.Flags().DurationVar(&opts.frameDelay, "frame-delay", 200*time.Millisecond,
+		"delay between animation frames")
+	cmd.Flags().BoolVar(&opts.keepFrames, "keep-frames", false,
+		"also save individual frames")
+	cmd.Flags().BoolVar(&opts.labelFrames, "label-frames", false,
+		"draw the varied parameter value on each frame")
+	cmd.Flags().BoolVar(&opts.tile, "tile", false,
+		"generate a seamless tiling texture (models with a tiling input)")
+	cmd.Flags().StringVar(&opts.upscaleAfter, "upscale-after", "",
+		"upscale results before saving (2x or 4x)")
+	cmd.Flags().BoolVar(&opts.autoUpscale, "auto-upscale", false,
+		"when --size exceeds the model's maximum, generate at the maximum and upscale to the size")
+	cmd.Flags().StringSliceVar(&opts.crops, "crops", nil,
+		"also save centered crops with these aspect ratios, e.g. 1:1,4:5,16:9,9:16, or none (default from output.crops)")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar with the model, seed and size next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().IntVar(&opts.palette, "palette", 0,
+		"record the N dominant colors of each image in its metadata sidecar (default from output.palette)")
+	cmd.Flags().BoolVar(&opts.altText, "alt-text", false,
+		"describe each image with a vision model and save the alt text in its metadata sidecar (default from alt_text.enabled)")
+	cmd.Flags().BoolVar(&opts.altTextFile, "alt-text-txt", false,
+		"also write the alt text to <image name>.txt; implies --alt-text (default from alt_text.txt)")
+	cmd.Flags().StringVar(&opts.visionModel, "vision-model", "",
+		"vision model writing alt text, e.g. google/gemini-2.5-flash (default from vision.model)")
+	cmd.Flags().BoolVar(&opts.autoTag, "auto-tag", false,
+		"extract keyword tags from each image with a vision model into its metadata sidecar (default from auto_tag.enabled)")
+	cmd.Flags().StringVar(&opts.tagModel, "tag-model", "",
+		"vision model extracting tags (default from auto_tag.model, then the alt text vision model)")
+	cmd.Flags().BoolVar(&opts.ocr, "ocr", false,
+		"read the text of each image with a vision model and flag images missing the quoted text of the prompt (default from ocr.enabled)")
+	cmd.Flags().StringArrayVar(&opts.expectText, "expect-text", nil,
+		"text each image must show, repeatable; implies --ocr")
+	cmd.Flags().IntVar(&opts.ocrRetries, "ocr-retries", 0,
+		"generate again up to N times while the text does not match (default from ocr.retries)")
+	cmd.Flags().StringVar(&opts.requireSize, "require-size", "",
+		"size returned images must have: WxH, >=WxH or <=WxH (default from constraints.require_size)")
+	cmd.Flags().StringVar(&opts.requireAspect, "require-aspect", "",
+		"aspect ratio returned images must have, e.g. 16:9 (default from constraints.require_aspect)")
+	cmd.Flags().StringVar(&opts.onViolation, "on-violation", "",
+		"when an image breaks --require-size or --require-aspect: warn, fail or retry (default from constraints.on_violation)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed generations to this directory")
+	cmd.Flags().BoolVar(&opts.urlOnly, "url-only", false,
+		"keep hosted images at their provider URLs instead of downloading them")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
 }
 
 func runGenerate(ctx context.Context, opts *generateOptions) error {
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	progress, err := openProgress(opts.progressJSON)
+	if err != nil {
+		return err
+	}
+	defer progress.Close()
+	opts.progress = progress
+
+	var r *run.Run
+	if runsEnabled(opts) && !opts.plan {
+		if r, err = startRun(); err != nil {
+			return err
+		}
+		opts.outputPath = filepath.Join(r.Dir, filepath.Base(opts.outputPath))
+	}
+
+	progress.emit(progressEvent{Event: progressQueued, Model: opts.model})
+	resp, paths, err := executeGenerate(ctx, opts)
+	if err != nil {
+		progress.failed(opts.model, err)
+	} else if !opts.plan {
+		fmt.Printf("Generation completed in %s\n", resp.Duration.Round(100*1e6))
+	}
+	if r != nil {
+		command := "generate"
+		if opts.outpaint != nil {
+			command = "outpaint"
+		} else if opts.removeBackground {
+			command = "remove-bg"
+		} else if opts.vectorize {
+			command = "vectorize"
+		} else if opts.video {
+			command = "video"
+		} else if opts.selectObject != "" {
+			command = "recolor"
+		} else if len(opts.inputImages) > 0 {
+			command = "edit"
+		} else if opts.variationsOf != "" {
+			command = "variations"
+		}
+		m := run.Manifest{Command: command, Model: opts.model, Images: paths}
+		if cfg.Privacy.StorePrompts {
+			m.Prompt = redactor.Apply(opts.prompt)
+		}
+		if err != nil {
+			m.Failed = 1
+		} else if len(resp.Images) == 0 && len(resp.Videos) == 0 {
+			m.Skipped = 1
+		} else {
+			m.Succeeded = 1
+			m.Cost = estimateCost(resp)
+		}
+		finishRun(r, m, err)
+	}
+
+	return err
+}
+
+// executeGenerate runs a single generation and saves the results,
+// returning the response and the saved file paths
+func executeGenerate(ctx context.Context, opts *generateOptions) (*generator.Response, []string, error) {
 	applyDefaults(opts)
 
 	var seedPtr *int64
@@ -97,64 +360,647 @@ func runGenerate(ctx context.Context, opts *generateOptions) error {
 		seedPtr = &opts.seed
 	}
 
+	var safetyTolerance *int
+	if opts.hasSafetyTolerance {
+		safetyTolerance = &opts.safetyTolerance
+	}
+
+	params, paramValues, err := parseParams(opts.params)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req := &generator.Request{
-		Model:          opts.model,
-		Prompt:         opts.prompt,
-		Size:           opts.size,
-		Quality:        opts.quality,
-		Style:          opts.style,
-		Count:          opts.count,
-		Seed:           seedPtr,
-		NegativePrompt: opts.negativePrompt,
-		AspectRatio:    opts.aspectRatio,
-		Steps:          opts.steps,
+		Model:           opts.model,
+		Prompt:          opts.prompt,
+		Size:            opts.size,
+		Quality:         opts.quality,
+		Style:           opts.style,
+		Count:           opts.count,
+		Seed:            seedPtr,
+		NegativePrompt:  opts.negativePrompt,
+		AspectRatio:     opts.aspectRatio,
+		Steps:           opts.steps,
+		Tile:            opts.tile,
+		StyleType:       opts.styleType,
+		MagicPrompt:     opts.magicPrompt,
+		SafetyTolerance: safetyTolerance,
+		ImageRefs:       opts.imageRefs,
+		Params:          params,
+		ParamValues:     paramValues,
+		URLOnly:         opts.urlOnly,
+		Outpaint:        opts.outpaint,
+		SearchPrompt:    opts.search,
+		Fidelity:        opts.fidelity,
+		SelectPrompt:    opts.selectObject,
+		VideoDuration:   opts.videoDuration,
+		FPS:             opts.fps,
+	}
+
+	if err := loadInputImages(req, opts.inputImages, opts.mask); err != nil {
+		return nil, nil, err
+	}
+	if err := loadInitImage(req, opts); err != nil {
+		return nil, nil, err
+	}
+	if err := loadControlImage(req, opts); err != nil {
+		return nil, nil, err
+	}
+	if opts.styleImage != "" {
+		img, err := readInputImage(opts.styleImage)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.StyleImage = &img
+	}
+	var variationSource *generator.InputImage
+	if opts.variationsOf != "" {
+		img, err := readInputImage(opts.variationsOf)
+		if err != nil {
+			return nil, nil, err
+		}
+		variationSource = &img
+	}
+
+	if err := validateFrameOptions(opts); err != nil {
+		return nil, nil, err
+	}
+
+	switch opts.upscaleAfter {
+	case "":
+	case "2x", "4x":
+		opts.upscaleFactor = int(opts.upscaleAfter[0] - '0')
+	default:
+		return nil, nil, fmt.Errorf("unsupported --upscale-after %q, supported: 2x, 4x", opts.upscaleAfter)
+	}
+
+	if opts.urlOnly && (opts.frames > 1 || opts.upscaleFactor > 1 || opts.autoUpscale) {
+		return nil, nil, fmt.Errorf("--url-only cannot be combined with --frames, --upscale-after or --auto-upscale")
+	}
+
+	crops, err := parseCrops(opts.crops)
+	if err != nil {
+		return nil, nil, err
+	}
+	cropName, err := parseCropName(cfg.Output.CropName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.palette < 0 {
+		return nil, nil, fmt.Errorf("--palette must not be negative")
+	}
+
+	// The content-addressed store lives in the output directory
+	preflightPath := opts.outputPath
+	if cfg.Output.Layout == "cas" {
+		preflightPath = filepath.Join(cfg.Output.Directory, filepath.Base(opts.outputPath))
+	}
+	if err := output.Preflight(preflightPath, max(opts.count, opts.frames)); err != nil {
+		return nil, nil, fmt.Errorf("output check failed: %w", err)
+	}
+
+	policy, err := output.ParseConflictPolicy(opts.onConflict)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sidecar *metadataSidecar
+	var writerOpts []output.WriterOption
+	if metadataEnabled(opts) {
+		sidecar = &metadataSidecar{prompt: opts.prompt, palette: opts.palette, altTextFile: opts.altTextFile}
+		writerOpts = append(writerOpts, sidecar.option())
+	}
+	var alt *altTexter
+	if altTextEnabled(opts) {
+		if alt, err = newAltTexter(opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	var tags *tagger
+	if autoTagEnabled(opts) {
+		if tags, err = newTagger(opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	text, err := newTextChecker(opts, req.Prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+	constraints, err := newImageConstraints(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	writer := newOutputWriter(policy, writerOpts...)
+	if opts.frames <= 1 && !opts.urlOnly {
+		if err := writer.Check(opts.outputPath, opts.count); err != nil {
+			return nil, nil, err
+		}
+		// Nothing to write under the skip policy, so nothing to pay for
+		if existing := writer.Existing(opts.outputPath, opts.count); existing != nil && !opts.validateOnly {
+			for _, path := range existing {
+				fmt.Printf("Skipped: %s already exists\n", path)
+				opts.progress.emit(progressEvent{Event: progressSkipped, Model: req.Model, Path: path})
+			}
+			return &generator.Response{Model: req.Model}, existing, nil
+		}
 	}
 
-	var p interface {
-		Name() string
-		Generate(context.Context, *generator.Request) (*generator.Response, error)
-		ValidateRequest(*generator.Request) error
+	var p provider.Provider
+	var upscale *upscaleTarget
+	// --plan prints its own summary instead
+	announce := func(format string, a ...any) {
+		if !opts.plan && !opts.validateOnly {
+			fmt.Printf(format, a...)
+		}
 	}
-	var err error
 
 	if opts.dryRun {
 		p = provider.NewDryRun()
-		fmt.Printf("Dry-run mode: generating placeholder image (%s)...\n", opts.size)
+		width, height := provider.DryRunSize(req)
+		announce("Dry-run mode: generating placeholder image (%dx%d)...\n", width, height)
 	} else {
-		if opts.providerName != "" {
-			p, err = registry.GetByName(opts.providerName)
-		} else {
-			p, err = registry.GetByModel(opts.model)
+		p, req.Model, err = resolveProvider(opts.providerName, opts.model)
+		var notFound *provider.ModelNotFoundError
+		if opts.autoCorrect && errors.As(err, &notFound) && len(notFound.Suggestions) > 0 {
+			fmt.Fprintf(os.Stderr, "Model %s not found, using %s\n", opts.model, notFound.Suggestions[0])
+			p, req.Model, err = resolveProvider("", notFound.Suggestions[0])
 		}
 		if err != nil {
-			return fmt.Errorf("failed to get provider: %w", err)
+			return nil, nil, fmt.Errorf("failed to get provider: %w", err)
+		}
+		opts.model = req.Model
+		if err := checkDeprecation(req.Model, opts.strict); err != nil {
+			return nil, nil, err
+		}
+		if err := checkImageRefs(req); err != nil {
+			return nil, nil, err
+		}
+		if err := checkInitImage(p, req); err != nil {
+			return nil, nil, err
+		}
+		if err := checkControl(p, req); err != nil {
+			return nil, nil, err
+		}
+		if err := checkStyleImage(p, req); err != nil {
+			return nil, nil, err
+		}
+		if err := checkParams(p, req); err != nil {
+			return nil, nil, err
+		}
+		if err := checkProbeCache(p, req); err != nil {
+			return nil, nil, err
+		}
+		if upscale, err = fitMaxSize(req, opts.autoUpscale); err != nil {
+			return nil, nil, err
+		}
+		if variationSource != nil {
+			announce("Making variations with %s using model %s...\n", p.Name(), opts.model)
+		} else if opts.removeBackground {
+			if _, ok := p.(provider.BackgroundRemover); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support background removal (supported: stability, replicate)", p.Name())
+			}
+			announce("Removing background with %s using model %s...\n", p.Name(), opts.model)
+		} else if opts.vectorize {
+			if _, ok := p.(provider.Vectorizer); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support vectorization (supported: recraft, replicate)", p.Name())
+			}
+			announce("Vectorizing image with %s using model %s...\n", p.Name(), opts.model)
+		} else if opts.video {
+			if _, ok := p.(provider.VideoGenerator); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support video (supported: stability, luma, replicate)", p.Name())
+			}
+			announce("Generating video with %s using model %s...\n", p.Name(), opts.model)
+		} else if req.Outpaint != nil {
+			if _, ok := p.(provider.Outpainter); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support outpainting (supported: stability)", p.Name())
+			}
+			announce("Outpainting image with %s using model %s...\n", p.Name(), opts.model)
+		} else if req.SelectPrompt != "" {
+			if _, ok := p.(provider.Recolorer); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support recoloring (supported: stability)", p.Name())
+			}
+			announce("Recoloring %q with %s using model %s...\n", req.SelectPrompt, p.Name(), opts.model)
+		} else if req.SearchPrompt != "" {
+			if _, ok := p.(provider.SearchReplacer); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support search-and-replace (supported: stability)", p.Name())
+			}
+			announce("Replacing %q with %s using model %s...\n", req.SearchPrompt, p.Name(), opts.model)
+		} else if len(req.InputImages) > 0 {
+			if _, ok := p.(provider.Editor); !ok {
+				return nil, nil, fmt.Errorf("provider %s does not support image editing (supported: openai, stability, google)", p.Name())
+			}
+			announce("Editing image with %s using model %s...\n", p.Name(), opts.model)
+		} else {
+			announce("Generating image with %s using model %s...\n", p.Name(), opts.model)
 		}
-		fmt.Printf("Generating image with %s using model %s...\n", p.Name(), opts.model)
 	}
 
-	resp, err := p.Generate(ctx, req)
-	if err != nil {
-		return fmt.Errorf("generation failed: %w", err)
+	var segmenter provider.Segmenter
+	var segmentModel string
+	if opts.maskPrompt != "" {
+		if segmenter, segmentModel, err = resolveSegmenter(p, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var vary func(context.Context, *generator.Request) (*generator.Response, error)
+	if variationSource != nil {
+		if vary, err = variationCall(p, req, *variationSource); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.plan {
+		printPlan(p, req, opts, upscale)
+		return &generator.Response{Model: req.Model}, nil, nil
+	}
+	if opts.validateOnly {
+		// Counts above the per-request limit are split into several calls,
+		// as provider.SplitCalls does, so check one call's worth
+		check := *req
+		if limit := provider.MaxImages(p, req.Model); limit > 0 && check.Count > limit {
+			check.Count = limit
+		}
+		return &generator.Response{Model: req.Model}, nil, p.ValidateRequest(&check)
+	}
+
+	if segmenter != nil {
+		fmt.Printf("Drawing the mask of %q with %s...\n", opts.maskPrompt, segmentModel)
+		if err := drawMask(ctx, p, segmenter, segmentModel, req, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var rec *httputil.Recorder
+	genCtx := opts.progress.context(ctx, req.Model)
+	if opts.saveRawDir != "" {
+		rec = &httputil.Recorder{}
+		genCtx = httputil.WithRecorder(genCtx, rec)
 	}
 
-	writer := output.NewWriter(cfg.Output.Format)
-	paths, err := writer.Write(resp.Images, opts.outputPath)
+	generate := func() (*generator.Response, []string, error) {
+		switch {
+		case vary != nil:
+			resp, err := provider.SplitCalls(genCtx, p, req, vary)
+			return resp, nil, err
+		case opts.removeBackground:
+			resp, err := p.(provider.BackgroundRemover).RemoveBackground(genCtx, req)
+			return resp, nil, err
+		case opts.vectorize:
+			resp, err := p.(provider.Vectorizer).Vectorize(genCtx, req)
+			return resp, nil, err
+		case opts.video:
+			resp, err := generateVideo(genCtx, p, req)
+			return resp, nil, err
+		case req.Outpaint != nil:
+			resp, err := p.(provider.Outpainter).Outpaint(genCtx, req)
+			return resp, nil, err
+		case req.SelectPrompt != "":
+			resp, err := p.(provider.Recolorer).Recolor(genCtx, req)
+			return resp, nil, err
+		case req.SearchPrompt != "":
+			resp, err := p.(provider.SearchReplacer).SearchReplace(genCtx, req)
+			return resp, nil, err
+		case len(req.InputImages) > 0:
+			resp, err := p.(provider.Editor).Edit(genCtx, req)
+			return resp, nil, err
+		case opts.frames > 1:
+			return generateFrames(genCtx, p, req, opts)
+		default:
+			resp, err := provider.GenerateSplit(genCtx, p, req)
+			return resp, nil, err
+		}
+	}
+	regenerate := func() (*generator.Response, error) {
+		resp, _, err := generate()
+		return resp, err
+	}
+	resp, labels, err := generate()
+	if err == nil && constraints != nil {
+		resp, err = constraints.verify(req, resp, regenerate)
+	}
+	if err == nil && text != nil {
+		resp, err = text.verify(genCtx, resp, regenerate)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to save images: %w", err)
+		if rec != nil {
+			if path, saveErr := saveRawResponses(opts.saveRawDir, p.Name(), req.Model, err, rec); saveErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save raw responses: %v\n", saveErr)
+			} else if path != "" {
+				fmt.Fprintf(os.Stderr, "Raw responses saved to %s\n", path)
+			}
+		}
+		return nil, nil, withHint(fmt.Errorf("generation failed: %w", err))
+	}
+
+	if sidecar != nil {
+		sidecar.resp = resp
+	}
+
+	if resp.Kudos > 0 {
+		fmt.Printf("Kudos spent: %.0f\n", resp.Kudos)
+	}
+
+	if opts.tile && !opts.urlOnly {
+		checkSeams(resp.Images)
+	}
+
+	if upscale != nil {
+		if err := upscaleToSize(resp.Images, upscale); err != nil {
+			return nil, nil, fmt.Errorf("upscale failed: %w", err)
+		}
+	}
+
+	if opts.upscaleFactor > 1 {
+		if err := upscaleImages(resp.Images, opts.upscaleFactor); err != nil {
+			return nil, nil, fmt.Errorf("upscale failed: %w", err)
+		}
+	}
+
+	images, outputPath := resp.Images, opts.outputPath
+	var paths []string
+	if opts.frames > 1 {
+		// Frames take their own format's extension, not the animation's:
+		// -o walk.gif keeps walk_1.png, walk_2.png, ...
+		if opts.keepFrames {
+			framePath := strings.TrimSuffix(opts.outputPath, filepath.Ext(opts.outputPath))
+			paths, err = writer.Write(resp.Images, framePath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to save frames: %w", err)
+			}
+		}
+
+		assembled, err := assembleFrames(resp.Images, labels, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to assemble frames: %w", err)
+		}
+		images = []generator.Image{assembled}
+		outputPath = withFormatExt(opts.outputPath, assembled.Format)
+	}
+
+	// Providers that return inline data still have their images saved
+	images, hosted := splitHosted(images)
+	if len(hosted) > 0 {
+		path, err := writeHostedURLs(writer, resp, hosted, outputPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to save image URLs: %w", err)
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	if len(images) > 0 {
+		if alt != nil {
+			alt.describe(ctx, images)
+		}
+		if tags != nil {
+			tags.tag(ctx, images)
+		}
+		saved, err := writer.Write(images, outputPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to save images: %w", err)
+		}
+		paths = append(paths, saved...)
+
+		// Assembled frames are animations or sheets, not crop sources
+		if len(crops) > 0 && opts.frames <= 1 {
+			cropped, err := writeCrops(writer, images, outputPath, crops, cropName)
+			paths = append(paths, cropped...)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to save crops: %w", err)
+			}
+		}
+	}
+
+	if len(resp.Videos) > 0 {
+		saved, err := writeVideos(writer, resp, outputPath)
+		paths = append(paths, saved...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to save videos: %w", err)
+		}
 	}
 
 	for _, path := range paths {
 		fmt.Printf("Saved: %s\n", path)
+		opts.progress.emit(progressEvent{Event: progressSaved, Model: req.Model, Path: path})
 	}
+	for _, path := range writer.Skipped() {
+		fmt.Printf("Skipped: %s already exists\n", path)
+		opts.progress.emit(progressEvent{Event: progressSkipped, Model: req.Model, Path: path})
+	}
+
+	return resp, paths, nil
+}
+
+// newOutputWriter returns the image writer for the configured layout
+func newOutputWriter(policy output.ConflictPolicy, extra ...output.WriterOption) *output.Writer {
+	opts := append([]output.WriterOption{output.WithConflictPolicy(policy)}, extra...)
+	if cfg.Output.Layout == "cas" {
+		opts = append(opts, output.WithContentAddressed(cfg.Output.Directory))
+	}
+	return output.NewWriter(cfg.Output.Format, opts...)
+}
+
+// checkDeprecation warns about deprecated or retired models using the
+// model catalog; in strict mode it fails instead
+func checkDeprecation(modelID string, strict bool) error {
+	m, ok := catalog.Current().Lookup(modelID)
+	if !ok || !m.Deprecated(time.Now()) {
+		return nil
+	}
+
+	msg := fmt.Sprintf("model %s is deprecated since %s", m.ID, m.DeprecatedAt)
+	if m.Sunset(time.Now()) {
+		msg = fmt.Sprintf("model %s was retired on %s and will likely fail", m.ID, m.SunsetAt)
+	}
+	if m.Replacement != "" {
+		msg += fmt.Sprintf("; use %s instead", m.Replacement)
+	}
+
+	if strict {
+		return fmt.Errorf("%s", msg)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+	return nil
+}
 
-	fmt.Printf("Generation completed in %s\n", resp.Duration.Round(100*1e6))
+// checkImageRefs rejects reference images for catalog models that do not
+// list the image_ref feature, so they are not silently dropped
+func checkImageRefs(req *generator.Request) error {
+	if len(req.ImageRefs) == 0 {
+		return nil
+	}
+	m, ok := catalog.Current().Lookup(req.Model)
+	if ok && !slices.Contains(m.Features, "image_ref") {
+		return fmt.Errorf("model %s does not accept reference images", m.ID)
+	}
+	return nil
+}
 
+// loadInitImage reads --init-image into the request and checks --strength
+func loadInitImage(req *generator.Request, opts *generateOptions) error {
+	if opts.hasStrength {
+		if opts.initImage == "" && opts.variationsOf == "" {
+			return fmt.Errorf("--strength requires --init-image")
+		}
+		if opts.strength < 0 || opts.strength > 1 {
+			return fmt.Errorf("--strength must be between 0 and 1, got %g", opts.strength)
+		}
+		req.Strength = &opts.strength
+	}
+	if opts.initImage == "" {
+		return nil
+	}
+	img, err := readInputImage(opts.initImage)
+	if err != nil {
+		return err
+	}
+	req.InitImage = &img
+	return nil
+}
+
+// checkInitImage rejects an init image for providers and models without
+// image-to-image
+func checkInitImage(p provider.Provider, req *generator.Request) error {
+	if req.InitImage == nil {
+		return nil
+	}
+	if ip, ok := p.(provider.InitImageProvider); !ok || !ip.AcceptsInitImage(req.Model) {
+		return fmt.Errorf("model %s does not support image-to-image (--init-image); "+
+			"use a Stability (stable-image-ultra, sd3-large) or Replicate model", req.Model)
+	}
 	return nil
 }
 
+// loadControlImage reads --control-image into the request with its mode
+// and strength
+func loadControlImage(req *generator.Request, opts *generateOptions) error {
+	if opts.controlImage == "" {
+		if opts.hasControlStrength {
+			return fmt.Errorf("--control-strength requires --control-image")
+		}
+		return nil
+	}
+	if !slices.Contains(generator.ControlModes, opts.controlMode) {
+		return fmt.Errorf("unsupported --control-mode %q, supported: %s", opts.controlMode, strings.Join(generator.ControlModes, ", "))
+	}
+	if req.InitImage != nil || len(req.InputImages) > 0 || opts.variationsOf != "" {
+		return fmt.Errorf("--control-image cannot be combined with an init or input image")
+	}
+
+	img, err := readInputImage(opts.controlImage)
+	if err != nil {
+		return err
+	}
+	req.Control = &generator.Control{Image: img, Mode: opts.controlMode}
+	if opts.hasControlStrength {
+		if opts.controlStrength < 0 || opts.controlStrength > 1 {
+			return fmt.Errorf("--control-strength must be between 0 and 1, got %g", opts.controlStrength)
+		}
+		req.Control.Strength = &opts.controlStrength
+	}
+	return nil
+}
+
+// checkControl rejects a control image for providers and models without
+// control in its mode
+func checkControl(p provider.Provider, req *generator.Request) error {
+	if req.Control == nil {
+		return nil
+	}
+	if cp, ok := p.(provider.ControlProvider); !ok || !cp.AcceptsControl(req.Model, req.Control.Mode) {
+		return fmt.Errorf("model %s does not support %s control (--control-image); "+
+			"use a Stability model, or for structure and sketch a Replicate ControlNet model", req.Model, req.Control.Mode)
+	}
+	return nil
+}
+
+// checkStyleImage rejects a style image for providers and models that
+// take none
+func checkStyleImage(p provider.Provider, req *generator.Request) error {
+	if req.StyleImage == nil {
+		return nil
+	}
+	if sp, ok := p.(provider.StyleImageProvider); !ok || !sp.AcceptsStyleImage(req.Model) {
+		return fmt.Errorf("model %s does not take a style image (--style-image); "+
+			"use a Stability, Ideogram or Replicate FLUX Redux model", req.Model)
+	}
+	return nil
+}
+
+// checkParams rejects provider-specific params the provider does not
+// read, so they are not silently dropped
+func checkParams(p provider.Provider, req *generator.Request) error {
+	if len(req.Params) == 0 {
+		return nil
+	}
+	if ip, ok := p.(provider.InputParamsProvider); ok && ip.AcceptsInputParams() {
+		return nil
+	}
+	pp, ok := p.(provider.ParamsProvider)
+	if !ok {
+		return fmt.Errorf("provider %s does not accept --param", p.Name())
+	}
+	supported := pp.SupportedParams()
+	for key := range req.Params {
+		if !slices.Contains(supported, key) {
+			return fmt.Errorf("provider %s does not accept param %q, supported: %s",
+				p.Name(), key, strings.Join(supported, ", "))
+		}
+	}
+	return nil
+}
+
+// checkSeams warns about images whose opposite edges do not match, which
+// happens when a backend ignores the tiling input
+func checkSeams(images []generator.Image) {
+	for _, img := range images {
+		decoded, err := imaging.Decode(img)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot check seams of image %d: %v\n", img.Index+1, err)
+			continue
+		}
+		if mismatch := imaging.SeamMismatch(decoded); mismatch > imaging.SeamThreshold {
+			fmt.Fprintf(os.Stderr, "Warning: image %d may not tile seamlessly (edge mismatch %.1f%%)\n",
+				img.Index+1, mismatch*100)
+		}
+	}
+}
+
+// upscaleImages enlarges images in place, keeping their seed and index
+func upscaleImages(images []generator.Image, factor int) error {
+	for i, img := range images {
+		decoded, err := imaging.Decode(img)
+		if err != nil {
+			return err
+		}
+		upscaled, err := imaging.EncodePNG(imaging.Upscale(decoded, factor))
+		if err != nil {
+			return err
+		}
+		upscaled.Seed, upscaled.Index = img.Seed, img.Index
+		images[i] = upscaled
+		fmt.Printf("Upscaled image %d %dx to %dx%d\n", img.Index+1, factor, upscaled.Width, upscaled.Height)
+	}
+	return nil
+}
+
+// resolveProvider selects a provider by explicit name or by model
+// reference and returns the model ID to send to it
+func resolveProvider(providerName, model string) (provider.Provider, string, error) {
+	if providerName != "" {
+		p, err := registry.GetByName(providerName)
+		return p, model, err
+	}
+	return registry.Resolve(model)
+}
+
 func applyDefaults(opts *generateOptions) {
 	if opts.model == "" {
 		opts.model = cfg.Defaults.Model
+		if settings, ok := cfg.Providers.Get(opts.providerName); ok && settings.DefaultModel != "" {
+			opts.model = settings.DefaultModel
+		}
 	}
 	if opts.size == "" && cfg.Defaults.Size != "" {
 		opts.size = cfg.Defaults.Size
@@ -171,7 +1017,40 @@ func applyDefaults(opts *generateOptions) {
 	if opts.aspectRatio == "" && cfg.Defaults.AspectRatio != "" {
 		opts.aspectRatio = cfg.Defaults.AspectRatio
 	}
+	if opts.onConflict == "" {
+		opts.onConflict = cfg.Output.OnConflict
+	}
+	if len(opts.crops) == 0 {
+		opts.crops = cfg.Output.Crops
+	}
+	if opts.palette == 0 {
+		opts.palette = cfg.Output.Palette
+	}
+	if opts.ocrRetries == 0 {
+		opts.ocrRetries = cfg.OCR.Retries
+	}
+	if opts.requireSize == "" {
+		opts.requireSize = cfg.Constraints.RequireSize
+	}
+	if opts.requireAspect == "" {
+		opts.requireAspect = cfg.Constraints.RequireAspect
+	}
+	if opts.onViolation == "" {
+		opts.onViolation = cfg.Constraints.OnViolation
+	}
+	if cfg.AltText.Text && altTextEnabled(opts) {
+		opts.altTextFile = true
+	}
 	if !opts.hasDryRun && cfg.Defaults.DryRun {
 		opts.dryRun = true
 	}
 }
+
+// withHint appends the remediation hint of a classified provider error
+func withHint(err error) error {
+	var apiErr *provider.APIError
+	if errors.As(err, &apiErr) && apiErr.Hint != "" {
+		return fmt.Errorf("%w\nHint: %s", err, apiErr.Hint)
+	}
+	return err
+}
@@ -7,7 +7,9 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/piligrim/llm-imager/internal/catalog"
 	"github.com/piligrim/llm-imager/internal/provider"
 	"github.com/spf13/cobra"
 )
@@ -53,12 +55,21 @@ func newListProvidersCmd() *cobra.Command {
 func newListModelsCmd() *cobra.Command {
 	var providerFilter string
 	var showPrices bool
+	var remote bool
+	var collection string
 
 	cmd := &cobra.Command{
 		Use:     "models",
 		Aliases: []string{"m"},
 		Short:   "List available models",
+		Example: `  llm-imager list models -p openai
+  llm-imager list models --prices
+  llm-imager list models -p replicate --remote --collection text-to-image`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if remote {
+				return listRemoteModels(cmd.Context(), providerFilter, collection)
+			}
+
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
 			if showPrices {
@@ -85,6 +96,9 @@ func newListModelsCmd() *cobra.Command {
 						continue
 					}
 					features := strings.Join(model.Features, ", ")
+					if m, ok := catalog.Current().Lookup(model.ID); ok && m.Deprecated(time.Now()) {
+						features = strings.TrimPrefix(features+" (deprecated)", " ")
+					}
 					if features == "" {
 						features = "-"
 					}
@@ -101,10 +115,117 @@ func newListModelsCmd() *cobra.Command {
 		"filter by provider")
 	cmd.Flags().BoolVar(&showPrices, "prices", false,
 		"show pricing info from OpenRouter API")
+	cmd.Flags().BoolVar(&remote, "remote", false,
+		"query the provider API for runnable models (replicate, openrouter, google, localai, invokeai)")
+	cmd.Flags().StringVar(&collection, "collection", "text-to-image",
+		"Replicate collection to list with --remote")
 
 	return cmd
 }
 
+// listRemoteModels lists models discovered from a provider API
+func listRemoteModels(ctx context.Context, providerName, collection string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	switch providerName {
+	case "replicate":
+		p, err := registry.GetByName("replicate")
+		if err != nil {
+			return err
+		}
+		remote, ok := p.(*provider.Replicate)
+		if !ok {
+			return fmt.Errorf("provider replicate does not support --remote")
+		}
+		models, err := remote.FetchCollection(ctx, collection)
+		if err != nil {
+			return fmt.Errorf("failed to fetch collection: %w", err)
+		}
+
+		fmt.Fprintln(w, "MODEL\tRUNS\tINPUTS")
+		for _, m := range models {
+			inputs := strings.Join(m.Inputs, ", ")
+			if inputs == "" {
+				inputs = "-"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\n", m.ID, m.RunCount, inputs)
+		}
+
+	case "openrouter":
+		models, err := provider.FetchImageModels(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch models: %w", err)
+		}
+
+		fmt.Fprintln(w, "MODEL\tNAME")
+		for _, m := range models {
+			fmt.Fprintf(w, "%s\t%s\n", m.ID, m.Name)
+		}
+
+	case "google":
+		p, err := registry.GetByName("google")
+		if err != nil {
+			return err
+		}
+		remote, ok := p.(*provider.Google)
+		if !ok {
+			return fmt.Errorf("provider google does not support --remote")
+		}
+		models, err := remote.FetchModels(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch models: %w", err)
+		}
+
+		fmt.Fprintln(w, "MODEL\tNAME\tMETHODS")
+		for _, m := range models {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", m.ID, m.Name, strings.Join(m.Methods, ", "))
+		}
+
+	case "localai":
+		p, err := registry.GetByName("localai")
+		if err != nil {
+			return err
+		}
+		remote, ok := p.(*provider.LocalAI)
+		if !ok {
+			return fmt.Errorf("provider localai does not support --remote")
+		}
+		models, err := remote.FetchModels(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch models: %w", err)
+		}
+
+		fmt.Fprintln(w, "MODEL")
+		for _, m := range models {
+			fmt.Fprintln(w, m.ID)
+		}
+
+	case "invokeai":
+		p, err := registry.GetByName("invokeai")
+		if err != nil {
+			return err
+		}
+		remote, ok := p.(*provider.InvokeAI)
+		if !ok {
+			return fmt.Errorf("provider invokeai does not support --remote")
+		}
+		models, err := remote.FetchModels(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch models: %w", err)
+		}
+
+		fmt.Fprintln(w, "MODEL\tBASE")
+		for _, m := range models {
+			fmt.Fprintf(w, "%s\t%s\n", m.ID, m.Base)
+		}
+
+	default:
+		return fmt.Errorf("--remote requires --provider replicate, openrouter, google, localai or invokeai")
+	}
+
+	return w.Flush()
+}
+
 func formatPrice(p *provider.Pricing) string {
 	if p == nil {
 		return "-"
@@ -127,27 +248,21 @@ func formatPrice(p *provider.Pricing) string {
 }
 
 func checkProviderAPIKey(name string) error {
-	switch name {
-	case "openai":
-		if cfg.Providers.OpenAI.APIKey == "" {
-			return fmt.Errorf("no API key")
-		}
-	case "google":
-		if cfg.Providers.Google.APIKey == "" {
-			return fmt.Errorf("no API key")
-		}
-	case "openrouter":
-		if cfg.Providers.OpenRouter.APIKey == "" {
-			return fmt.Errorf("no API key")
-		}
-	case "stability":
-		if cfg.Providers.Stability.APIKey == "" {
-			return fmt.Errorf("no API key")
-		}
-	case "replicate":
-		if cfg.Providers.Replicate.APIKey == "" {
-			return fmt.Errorf("no API key")
+	// Bedrock uses AWS credentials, resolved when a request is made,
+	// Stable Horde falls back to the anonymous key and local servers
+	// usually run without one
+	if name == "bedrock" || name == "stablehorde" || name == "localai" || name == "invokeai" {
+		return nil
+	}
+	// Hunyuan signs requests with a secret ID and key pair
+	if name == "hunyuan" {
+		if cfg.Providers.Hunyuan.SecretID == "" || cfg.Providers.Hunyuan.SecretKey == "" {
+			return fmt.Errorf("no secret ID/key")
 		}
+		return nil
+	}
+	if settings, ok := cfg.Providers.Get(name); ok && settings.APIKey == "" {
+		return fmt.Errorf("no API key")
 	}
 	return nil
 }
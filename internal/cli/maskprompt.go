@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// maskTransparent lists the providers whose edits take a mask, and
+// whether they edit its transparent areas rather than its white areas
+var maskTransparent = map[string]bool{
+	"openai":    true,
+	"stability": false,
+	"dryrun":    false,
+}
+
+// resolveSegmenter returns the segmentation model drawing the mask of
+// edit --mask-prompt, checking that p's edits take a mask
+func resolveSegmenter(p provider.Provider, opts *generateOptions) (provider.Segmenter, string, error) {
+	if _, ok := maskTransparent[p.Name()]; !ok {
+		return nil, "", fmt.Errorf("provider %s takes no mask, so --mask-prompt cannot be used (supported: openai, stability)", p.Name())
+	}
+	if opts.dryRun {
+		return provider.NewDryRun(), opts.maskModel, nil
+	}
+
+	sp, model, err := resolveProvider("", opts.maskModel)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get the mask model: %w", err)
+	}
+	segmenter, ok := sp.(provider.Segmenter)
+	if !ok {
+		return nil, "", fmt.Errorf("provider %s cannot draw masks from a prompt (supported: replicate)", sp.Name())
+	}
+	return segmenter, model, nil
+}
+
+// drawMask segments what opts.maskPrompt describes in the first input
+// image and sets it as the request mask, in the mask convention of p
+func drawMask(ctx context.Context, p provider.Provider, segmenter provider.Segmenter, model string, req *generator.Request, opts *generateOptions) error {
+	src := req.InputImages[0]
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(src.Data))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", src.Name, err)
+	}
+
+	segmented, err := segmenter.Segment(ctx, model, opts.maskPrompt, src)
+	if err != nil {
+		return withHint(fmt.Errorf("drawing the mask failed: %w", err))
+	}
+	decoded, err := imaging.Decode(segmented)
+	if err != nil {
+		return err
+	}
+	mask, err := imaging.EncodePNG(imaging.EditMask(decoded, cfg.Width, cfg.Height, maskTransparent[p.Name()]))
+	if err != nil {
+		return err
+	}
+
+	req.Mask = &generator.InputImage{
+		Name:     "mask.png",
+		MIMEType: "image/png",
+		Data:     mask.Data,
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
+)
+
+// fitMaxSize checks the requested size against the catalog maximum of the
+// model. A larger size fails unless autoUpscale is set; then the request
+// is lowered to the largest size the model produces with the closest
+// aspect ratio, and the requested size is returned as the upscale target.
+func fitMaxSize(req *generator.Request, autoUpscale bool) (*upscaleTarget, error) {
+	m, ok := catalog.Current().Lookup(req.Model)
+	if !ok {
+		return nil, nil
+	}
+	maxW, maxH, ok := m.MaxDimensions()
+	if !ok {
+		return nil, nil
+	}
+	width, height, ok := parseSizeFlag(req.Size)
+	if !ok || (width <= maxW && height <= maxH) {
+		return nil, nil
+	}
+
+	if !autoUpscale {
+		return nil, fmt.Errorf("size %s exceeds the %s maximum of %s (add --auto-upscale to generate at the maximum and upscale)",
+			req.Size, m.MaxSize, m.ID)
+	}
+
+	genW, genH := largestSize(m, width, height, maxW, maxH)
+	fmt.Printf("Size %s exceeds the %s maximum of %s, generating at %dx%d and upscaling\n",
+		req.Size, m.MaxSize, m.ID, genW, genH)
+	req.Size = fmt.Sprintf("%dx%d", genW, genH)
+	return &upscaleTarget{width: width, height: height}, nil
+}
+
+// upscaleTarget is the size the results of a generation are upscaled to
+type upscaleTarget struct {
+	width, height int
+}
+
+// largestSize returns the size to generate for a target above the model
+// maximum: among the model's listed sizes, the closest aspect ratio and
+// then the largest area; without listed sizes, the target scaled down to
+// fit the maximum, in multiples of 64
+func largestSize(m catalog.Model, width, height, maxW, maxH int) (int, int) {
+	target := math.Log(float64(width) / float64(height))
+
+	bestW, bestH := 0, 0
+	bestDiff := math.Inf(1)
+	for _, size := range m.Sizes {
+		w, h, ok := parseSizeFlag(size)
+		if !ok || w > maxW || h > maxH {
+			continue
+		}
+		diff := math.Abs(math.Log(float64(w)/float64(h)) - target)
+		if diff < bestDiff-1e-9 || (math.Abs(diff-bestDiff) < 1e-9 && w*h > bestW*bestH) {
+			bestW, bestH, bestDiff = w, h, diff
+		}
+	}
+	if bestW > 0 {
+		return bestW, bestH
+	}
+
+	scale := math.Min(float64(maxW)/float64(width), float64(maxH)/float64(height))
+	w := max(int(float64(width)*scale)/64*64, 64)
+	h := max(int(float64(height)*scale)/64*64, 64)
+	return w, h
+}
+
+// parseSizeFlag parses a WIDTHxHEIGHT size; named sizes such as 2K do not
+// parse
+func parseSizeFlag(size string) (int, int, bool) {
+	var w, h int
+	if _, err := fmt.Sscanf(strings.ToLower(size), "%dx%d", &w, &h); err != nil || w <= 0 || h <= 0 {
+		return 0, 0, false
+	}
+	return w, h, true
+}
+
+// upscaleToSize enlarges images in place to the target of fitMaxSize,
+// keeping their seed and index
+func upscaleToSize(images []generator.Image, target *upscaleTarget) error {
+	for i, img := range images {
+		decoded, err := imaging.Decode(img)
+		if err != nil {
+			return err
+		}
+		upscaled, err := imaging.EncodePNG(imaging.UpscaleTo(decoded, target.width, target.height))
+		if err != nil {
+			return err
+		}
+		upscaled.Seed, upscaled.Index = img.Seed, img.Index
+		images[i] = upscaled
+		b := decoded.Bounds()
+		fmt.Printf("Upscaled image %d from %dx%d to %dx%d\n", img.Index+1, b.Dx(), b.Dy(), upscaled.Width, upscaled.Height)
+	}
+	return nil
+}
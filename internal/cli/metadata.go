@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
+	"github.com/piligrim/llm-imager/internal/output"
+)
+
+// imageMetadata is the sidecar saved next to an image as <image>.json
+// by --metadata
+type imageMetadata struct {
+	Model        string                 `json:"model,omitempty"`
+	Provider     string                 `json:"provider,omitempty"`
+	Prompt       string                 `json:"prompt,omitempty"` // Only with privacy.store_prompts
+	Seed         *int64                 `json:"seed,omitempty"`
+	Format       string                 `json:"format"`
+	Width        int                    `json:"width,omitempty"`
+	Height       int                    `json:"height,omitempty"`
+	GeneratedAt  time.Time              `json:"generated_at"`
+	AltText      string                 `json:"alt_text,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	RenderedText string                 `json:"rendered_text,omitempty"`
+	TextMismatch bool                   `json:"text_mismatch,omitempty"`
+	Palette      []imaging.PaletteColor `json:"palette,omitempty"`
+}
+
+// metadataSidecar builds the sidecars of one generation. The response is
+// set once the generation is done, before any image is written.
+type metadataSidecar struct {
+	prompt      string
+	palette     int  // Number of palette colors, 0 for none
+	altTextFile bool // Also write alt text to <image name>.txt
+	resp        *generator.Response
+}
+
+// metadataEnabled reports whether images get metadata sidecars, from
+// --metadata or output.metadata; a palette, alt text and tags always need
+// one
+func metadataEnabled(opts *generateOptions) bool {
+	if opts.palette > 0 || altTextEnabled(opts) || autoTagEnabled(opts) {
+		return true
+	}
+	if opts.hasMetadata {
+		return opts.metadata
+	}
+	return cfg.Output.Metadata
+}
+
+// option returns the writer option saving the sidecars
+func (m *metadataSidecar) option() output.WriterOption {
+	return output.WithSidecar(func(img generator.Image, path string) (any, error) {
+		if m.altTextFile && img.AltText != "" {
+			txt := strings.TrimSuffix(path, filepath.Ext(path)) + ".txt"
+			if err := os.WriteFile(txt, []byte(img.AltText+"\n"), 0644); err != nil {
+				return nil, fmt.Errorf("failed to write alt text: %w", err)
+			}
+		}
+		return m.build(img)
+	})
+}
+
+// build returns the sidecar of a saved image
+func (m *metadataSidecar) build(img generator.Image) (*imageMetadata, error) {
+	meta := &imageMetadata{
+		Seed:    img.Seed,
+		Format:  img.Format,
+		Width:   img.Width,
+		Height:  img.Height,
+		AltText: img.AltText,
+		Tags:    img.Tags,
+
+		RenderedText: img.RenderedText,
+		TextMismatch: img.TextMismatch,
+	}
+	if m.resp != nil {
+		meta.Model, meta.Provider, meta.GeneratedAt = m.resp.Model, m.resp.Provider, m.resp.GeneratedAt
+	}
+	if cfg.Privacy.StorePrompts {
+		meta.Prompt = redactor.Apply(m.prompt)
+	}
+
+	// Vector images and videos have no pixels to measure
+	if img.Format == "svg" || img.IsVideo() || (m.palette <= 0 && meta.Width > 0 && meta.Height > 0) {
+		return meta, nil
+	}
+	decoded, err := imaging.Decode(img)
+	if err != nil {
+		return nil, err
+	}
+	b := decoded.Bounds()
+	meta.Width, meta.Height = b.Dx(), b.Dy()
+	meta.Palette = imaging.Palette(decoded, m.palette)
+	return meta, nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+)
+
+func newModelsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "models",
+		Annotations: map[string]string{skipPluginsAnnotation: "true"},
+		Short:       "Manage the model catalog",
+		Long: `Manage the model catalog that describes supported models, their sizes,
+features and approximate prices. A catalog is embedded in the binary and can be
+updated between releases with "models refresh".`,
+	}
+
+	cmd.AddCommand(newModelsRefreshCmd())
+
+	return cmd
+}
+
+func newModelsRefreshCmd() *cobra.Command {
+	var url string
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Download the latest model catalog",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if url == "" {
+				url = cfg.Catalog.URL
+			}
+
+			client := &http.Client{Timeout: 30 * time.Second}
+			path := catalog.DefaultOverridePath()
+
+			c, err := catalog.Refresh(cmd.Context(), client, url, path)
+			if err != nil {
+				return fmt.Errorf("failed to refresh catalog: %w", err)
+			}
+
+			fmt.Printf("Catalog updated: %d models (updated %s)\n", len(c.Models), c.UpdatedAt)
+			if c != catalog.Current() {
+				fmt.Printf("Embedded catalog (updated %s) is newer and stays active\n",
+					catalog.Current().UpdatedAt)
+			}
+			fmt.Printf("Saved: %s\n", path)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&url, "url", "",
+		"catalog URL (default: catalog.url from config)")
+
+	return cmd
+}
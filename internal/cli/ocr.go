@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// ocrPrompt asks a vision model to read the text of an image
+const ocrPrompt = "Transcribe all text rendered in this image exactly as written, " +
+	"one line per block of text, without commentary. Reply with NONE if there is no text."
+
+// quotedText matches the text a prompt asks to render: "..." or “...”
+var quotedText = regexp.MustCompile(`"([^"]+)"|“([^”]+)”`)
+
+// textChecker reads the text of generated images with a vision model and
+// compares it to the text the prompt asked for
+type textChecker struct {
+	describer provider.Describer
+	model     string
+	expected  []string
+	retries   int
+}
+
+// newTextChecker returns the checker of --ocr, --expect-text or
+// ocr.enabled, or nil when there is nothing to check. The expected text is
+// --expect-text, else the quoted text of the prompt.
+func newTextChecker(opts *generateOptions, prompt string) (*textChecker, error) {
+	expected := opts.expectText
+	if len(expected) == 0 {
+		enabled := cfg.OCR.Enabled
+		if opts.hasOCR {
+			enabled = opts.ocr
+		}
+		if !enabled {
+			return nil, nil
+		}
+		expected = promptText(prompt)
+	}
+	if len(expected) == 0 || opts.frames > 1 {
+		return nil, nil
+	}
+
+	describer, model, err := visionDescriber(opts, cfg.OCR.Model)
+	if err != nil {
+		return nil, err
+	}
+	return &textChecker{describer: describer, model: model, expected: expected, retries: opts.ocrRetries}, nil
+}
+
+// promptText returns the quoted strings of a prompt, the usual way of
+// asking for text on posters and logos
+func promptText(prompt string) []string {
+	var texts []string
+	for _, m := range quotedText.FindAllStringSubmatch(prompt, -1) {
+		text := strings.TrimSpace(m[1] + m[2])
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// verify checks the text of the images of resp, generating again up to
+// the retry limit while any image misses the expected text, and returns
+// the response with the fewest mismatches. Images keep what was read, and
+// the mismatches of the returned response are reported as warnings.
+func (c *textChecker) verify(ctx context.Context, resp *generator.Response, generate func() (*generator.Response, error)) (*generator.Response, error) {
+	best, bestMisses := resp, c.check(ctx, resp.Images)
+	for attempt := 1; bestMisses > 0 && attempt <= c.retries; attempt++ {
+		fmt.Printf("Text mismatch in %d image(s), generating again (retry %d of %d)...\n", bestMisses, attempt, c.retries)
+		retry, err := generate()
+		if err != nil {
+			return nil, err
+		}
+		if misses := c.check(ctx, retry.Images); misses < bestMisses {
+			best, bestMisses = retry, misses
+		}
+	}
+
+	for _, img := range best.Images {
+		if img.TextMismatch {
+			fmt.Fprintf(os.Stderr, "Warning: text of image %d does not match %q, read %q\n",
+				img.Index+1, strings.Join(c.expected, " / "), img.RenderedText)
+		}
+	}
+	return best, nil
+}
+
+// check reads the text of images, marks the ones missing any expected
+// text and returns how many there are. Images that cannot be read count
+// as matching, so a failed request never triggers a paid retry.
+func (c *textChecker) check(ctx context.Context, images []generator.Image) int {
+	misses := 0
+	for i := range images {
+		img := &images[i]
+		if !describable(*img) {
+			continue
+		}
+
+		text, err := c.describer.Describe(ctx, c.model, ocrPrompt, visionInput(*img))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: reading the text of image %d failed: %v\n", img.Index+1, err)
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(text), "NONE") {
+			text = ""
+		}
+		img.RenderedText = strings.TrimSpace(text)
+		img.TextMismatch = !containsText(img.RenderedText, c.expected)
+		if img.TextMismatch {
+			misses++
+		}
+	}
+	return misses
+}
+
+// containsText reports whether text contains all of expected, ignoring
+// case, punctuation and line breaks
+func containsText(text string, expected []string) bool {
+	normalized := " " + normalizeText(text) + " "
+	for _, want := range expected {
+		if !strings.Contains(normalized, " "+normalizeText(want)+" ") {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeText lowercases text and reduces everything but letters and
+// digits to single spaces
+func normalizeText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+func newOutpaintCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var image string
+	var creativity float64
+	out := &generator.Outpaint{}
+
+	cmd := &cobra.Command{
+		Use:   "outpaint",
+		Short: "Extend an image beyond its borders",
+		Long: `Extend the canvas of an image with Stability AI outpainting: --left,
+--right, --up and --down add that many pixels (up to 2000) on each side,
+filled to match the image. A prompt is optional and guides what fills the
+new area.`,
+		Example: `  llm-imager outpaint --image beach.png --left 512 --right 512 -o beach-wide.png
+  llm-imager outpaint --image room.png --up 300 -p "wooden ceiling beams" -o room-tall.png`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if out.Left < 0 || out.Right < 0 || out.Up < 0 || out.Down < 0 {
+				return fmt.Errorf("--left, --right, --up and --down must not be negative")
+			}
+			if out.Left+out.Right+out.Up+out.Down == 0 {
+				return fmt.Errorf("at least one of --left, --right, --up or --down is required")
+			}
+			if cmd.Flags().Changed("creativity") {
+				if creativity < 0 || creativity > 1 {
+					return fmt.Errorf("--creativity must be between 0 and 1, got %g", creativity)
+				}
+				out.Creativity = &creativity
+			}
+			opts.inputImages = []string{image}
+			opts.outpaint = out
+
+			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "",
+		"image file to extend (required)")
+	cmd.Flags().IntVar(&out.Left, "left", 0,
+		"pixels to add on the left")
+	cmd.Flags().IntVar(&out.Right, "right", 0,
+		"pixels to add on the right")
+	cmd.Flags().IntVar(&out.Up, "up", 0,
+		"pixels to add at the top")
+	cmd.Flags().IntVar(&out.Down, "down", 0,
+		"pixels to add at the bottom")
+	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
+		"optional description of the added area")
+	cmd.Flags().Float64Var(&creativity, "creativity", 0.5,
+		"how freely new content is invented, 0-1")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output file path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "stability/stable-image-core",
+		"Stability model; all use the same outpaint endpoint")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0,
+		"seed for reproducibility")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate placeholder images without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed requests to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("image")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
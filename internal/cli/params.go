@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parseParams parses --param values, in the spirit of httpie items:
+//
+//	key=value   scalar; integers, floats and true/false are detected,
+//	            anything else is a string
+//	key:=json   raw JSON, for arrays, objects and values that must keep
+//	            their JSON type (key:='"007"' is the string 007)
+//
+// It returns the values as given, read by most providers, and the typed
+// values, which are passed on as JSON inputs (Replicate, plugins).
+// Integers beyond int64 stay json.Number so they keep every digit.
+func parseParams(values []string) (map[string]string, map[string]any, error) {
+	if len(values) == 0 {
+		return nil, nil, nil
+	}
+
+	params := make(map[string]string, len(values))
+	typed := make(map[string]any, len(values))
+	for _, v := range values {
+		key, raw, isJSON, err := splitParam(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := params[key]; ok {
+			return nil, nil, fmt.Errorf("invalid --param %q: %s is given more than once", v, key)
+		}
+
+		var value any
+		if isJSON {
+			value, err = decodeParamJSON(raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid --param %q: value after := is not valid JSON: %v", v, err)
+			}
+			if value, err = convertNumbers(value); err != nil {
+				return nil, nil, fmt.Errorf("invalid --param %q: %v", v, err)
+			}
+			// Providers reading strings get JSON strings unquoted
+			if s, ok := value.(string); ok {
+				raw = s
+			}
+		} else {
+			value = detectParamType(raw)
+		}
+
+		params[key] = raw
+		typed[key] = value
+	}
+	return params, typed, nil
+}
+
+// splitParam splits a --param value into its key and raw value, reporting
+// whether the value is JSON (key:=json)
+func splitParam(v string) (key, raw string, isJSON bool, err error) {
+	key, raw, ok := strings.Cut(v, "=")
+	if !ok {
+		return "", "", false, fmt.Errorf("invalid --param %q, expected key=value or key:=json", v)
+	}
+	if strings.HasSuffix(key, ":") {
+		key, isJSON = strings.TrimSuffix(key, ":"), true
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", "", false, fmt.Errorf("invalid --param %q: missing key before =", v)
+	}
+	if i := strings.IndexFunc(key, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r) || r == ':'
+	}); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(key[i:])
+		return "", "", false, fmt.Errorf("invalid --param %q: key %q contains %q", v, key, r)
+	}
+	if isJSON && strings.TrimSpace(raw) == "" {
+		return "", "", false, fmt.Errorf("invalid --param %q: missing JSON value after :=", v)
+	}
+	return key, raw, isJSON, nil
+}
+
+// decodeParamJSON decodes a single JSON value with numbers as json.Number
+func decodeParamJSON(raw string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("value is incomplete")
+		}
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("%v at offset %d", err, syntaxErr.Offset)
+		}
+		return nil, err
+	}
+	if rest := strings.TrimSpace(raw[dec.InputOffset():]); rest != "" {
+		return nil, fmt.Errorf("unexpected %q after the value", rest)
+	}
+	return value, nil
+}
+
+// convertNumbers replaces json.Number values with int64 or float64.
+// Integers beyond int64 stay json.Number, since float64 would round them.
+func convertNumbers(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return convertNumber(v)
+	case []any:
+		for i := range v {
+			converted, err := convertNumbers(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	case map[string]any:
+		for k := range v {
+			converted, err := convertNumbers(v[k])
+			if err != nil {
+				return nil, err
+			}
+			v[k] = converted
+		}
+	}
+	return value, nil
+}
+
+// convertNumber returns a JSON number as int64, as json.Number for larger
+// integers or as float64, failing if it overflows float64
+func convertNumber(n json.Number) (any, error) {
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	if !strings.ContainsAny(n.String(), ".eE") {
+		return n, nil
+	}
+	f, err := n.Float64()
+	if err != nil || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("number %s is out of range", n)
+	}
+	return f, nil
+}
+
+// detectParamType returns the typed value of a key=value param. Only JSON
+// number literals count as numbers, so "007", "1_000", "inf" and "0x10"
+// stay strings.
+func detectParamType(raw string) any {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if raw == "" || !strings.ContainsAny(raw[:1], "-0123456789") {
+		return raw
+	}
+
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var n json.Number
+	if err := dec.Decode(&n); err != nil || dec.InputOffset() != int64(len(raw)) {
+		return raw
+	}
+	if value, err := convertNumber(n); err == nil {
+		return value
+	}
+	return raw
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzParseParams(f *testing.F) {
+	for _, seed := range []string{
+		"steps=30",
+		"ratio=1.5",
+		"hdr=true",
+		"code=007",
+		"name=",
+		`loras:=[{"path":"a","scale":0.8}]`,
+		`id:='"007"'`,
+		"big:=1e400",
+		"small:=-1e400",
+		"nested:=[1, {\"a\": 1e999}]",
+		"seed:=123456789012345678901234567890",
+		"seed=9007199254740993",
+		"huge=1e400",
+		"ké y=1",
+		"k ey:=1",
+		"=1",
+		"key:=",
+		"novalue",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, v string) {
+		params, typed, err := parseParams([]string{v})
+		if err != nil {
+			if utf8.ValidString(v) && !utf8.ValidString(err.Error()) {
+				t.Fatalf("error for %q is not valid UTF-8: %q", v, err)
+			}
+			return
+		}
+
+		for key, value := range typed {
+			if key == "" {
+				t.Fatalf("empty key from %q", v)
+			}
+			if _, ok := params[key]; !ok {
+				t.Fatalf("typed key %q of %q has no raw value", key, v)
+			}
+			// Typed values are sent as JSON, so they must always encode
+			if _, err := json.Marshal(value); err != nil {
+				t.Fatalf("value of %q does not encode as JSON: %v", v, err)
+			}
+			// Integers too large for int64 keep every digit
+			if n, ok := value.(json.Number); ok {
+				data, _ := json.Marshal(n)
+				if string(data) != n.String() {
+					t.Fatalf("number %s of %q encodes as %s", n, v, data)
+				}
+			}
+		}
+	})
+}
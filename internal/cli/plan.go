@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+	"github.com/piligrim/llm-imager/internal/run"
+)
+
+// planHiddenParams are request fields --plan prints on their own lines or
+// not at all
+var planHiddenParams = []string{"model", "prompt", "count", "input_images", "init_image", "image_refs", "mask", "control", "style_image"}
+
+// printPlan prints what a generation with p would do without sending it:
+// the endpoint, the resolved parameters and the estimated cost and time
+func printPlan(p provider.Provider, req *generator.Request, opts *generateOptions, upscale *upscaleTarget) {
+	fmt.Println("Plan (nothing is generated):")
+	fmt.Printf("  Provider:   %s\n", p.Name())
+	fmt.Printf("  Model:      %s\n", req.Model)
+
+	endpoint := "not reported by the provider"
+	if e, ok := p.(provider.Endpointer); ok && !opts.removeBackground && !opts.vectorize && !opts.video {
+		endpoint = e.Endpoint(req)
+	}
+	fmt.Printf("  Endpoint:   %s\n", endpoint)
+
+	count := max(req.Count, 1)
+	requests := 1
+	if limit := provider.MaxImages(p, req.Model); limit > 0 && opts.frames <= 1 {
+		requests = (count + limit - 1) / limit
+	}
+	if opts.frames > 1 {
+		count, requests = opts.frames, opts.frames
+	}
+	fmt.Printf("  Images:     %d in %d request(s)\n", count, requests)
+
+	if req.Prompt != "" {
+		fmt.Printf("  Prompt:     %s\n", redactor.Apply(req.Prompt))
+	}
+	if inputs := planInputs(req); len(inputs) > 0 {
+		fmt.Printf("  Inputs:     %s\n", strings.Join(inputs, ", "))
+	}
+	if opts.maskPrompt != "" {
+		fmt.Printf("  Mask:       %q, drawn by %s\n", opts.maskPrompt, opts.maskModel)
+	}
+	if params := planParams(req); len(params) > 0 {
+		fmt.Printf("  Parameters: %s\n", strings.Join(params, " "))
+	}
+	if upscale != nil {
+		fmt.Printf("  Upscale:    to %dx%d after generation\n", upscale.width, upscale.height)
+	}
+	fmt.Printf("  Output:     %s\n", opts.outputPath)
+
+	m, known := catalog.Current().Lookup(provider.QualifiedModelID(p.Name(), req.Model))
+	if known && m.PricePerImage > 0 {
+		fmt.Printf("  Cost:       ~$%.3f (%d × $%.3f)\n", m.PricePerImage*float64(count), count, m.PricePerImage)
+	} else {
+		fmt.Printf("  Cost:       unknown (no catalog price)\n")
+	}
+
+	if perImage, runs := historicalDuration(req.Model); runs > 0 {
+		fmt.Printf("  Time:       ~%s (median of %d past run(s))\n", (perImage * time.Duration(count)).Round(100*time.Millisecond), runs)
+	} else {
+		fmt.Printf("  Time:       unknown (no past runs of this model)\n")
+	}
+}
+
+// planInputs returns the input files of a request
+func planInputs(req *generator.Request) []string {
+	var inputs []string
+	for _, img := range req.InputImages {
+		inputs = append(inputs, img.Name)
+	}
+	if req.InitImage != nil {
+		inputs = append(inputs, req.InitImage.Name)
+	}
+	if req.Mask != nil {
+		inputs = append(inputs, req.Mask.Name+" (mask)")
+	}
+	if req.StyleImage != nil {
+		inputs = append(inputs, req.StyleImage.Name+" (style)")
+	}
+	if req.Control != nil {
+		control := fmt.Sprintf("%s (%s control", req.Control.Image.Name, req.Control.Mode)
+		if req.Control.Strength != nil {
+			control += fmt.Sprintf(", strength %g", *req.Control.Strength)
+		}
+		inputs = append(inputs, control+")")
+	}
+	return append(inputs, req.ImageRefs...)
+}
+
+// planParams returns the set fields of a request as sorted key=value
+// pairs, named by their JSON keys
+func planParams(req *generator.Request) []string {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	var params []string
+	for key, value := range fields {
+		if slices.Contains(planHiddenParams, key) {
+			continue
+		}
+		params = append(params, key+"="+strings.Trim(string(value), `"`))
+	}
+	sort.Strings(params)
+	return params
+}
+
+// historicalDuration returns the median time per image of the successful
+// runs of model, and how many runs it is based on
+func historicalDuration(model string) (time.Duration, int) {
+	manifests, err := run.History(runsDir())
+	if err != nil {
+		return 0, 0
+	}
+
+	var durations []time.Duration
+	for _, m := range manifests {
+		if m.Model != model || m.Status != run.StatusOK || m.Succeeded == 0 || len(m.Images) == 0 {
+			continue
+		}
+		if d := m.FinishedAt.Sub(m.StartedAt); d > 0 {
+			durations = append(durations, d/time.Duration(len(m.Images)))
+		}
+	}
+	if len(durations) == 0 {
+		return 0, 0
+	}
+	slices.Sort(durations)
+	return durations[len(durations)/2], len(durations)
+}
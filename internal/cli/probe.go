@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/probe"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+func newProbeCmd() *cobra.Command {
+	var model string
+	var providerName string
+
+	cmd := &cobra.Command{
+		Use:   "probe",
+		Short: "Check that a model exists and the API key can use it",
+		Long: `Query provider metadata endpoints to verify that a model exists, that the
+configured API key has access to it, and which optional parameters it accepts.
+
+Results are cached and used by generate to fail fast on inaccessible models
+and to warn about parameters the model ignores.`,
+		Example: `  llm-imager probe -m openai/dall-e-3
+  llm-imager probe -m replicate/black-forest-labs/flux-dev`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, model, err := resolveProvider(providerName, model)
+			if err != nil {
+				return fmt.Errorf("failed to get provider: %w", err)
+			}
+
+			prober, ok := p.(provider.Prober)
+			if !ok {
+				return fmt.Errorf("provider %s does not support probing", p.Name())
+			}
+
+			result, err := prober.Probe(cmd.Context(), model)
+			if err != nil {
+				return withHint(fmt.Errorf("probe failed: %w", err))
+			}
+
+			cache, err := probe.Load(probe.DefaultCachePath())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			} else {
+				cache.Put(result)
+				if err := cache.Save(); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to save probe cache: %v\n", err)
+				}
+			}
+
+			available := "no"
+			if result.Available {
+				available = "yes"
+			}
+			params := strings.Join(result.Params, ", ")
+			if params == "" {
+				params = "-"
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Model:\t%s\n", result.Model)
+			fmt.Fprintf(w, "Provider:\t%s\n", result.Provider)
+			fmt.Fprintf(w, "Available:\t%s\n", available)
+			fmt.Fprintf(w, "Params:\t%s\n", params)
+			if result.Message != "" {
+				fmt.Fprintf(w, "Note:\t%s\n", result.Message)
+			}
+			w.Flush()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&model, "model", "m", "",
+		"model to probe (e.g., openai/dall-e-3)")
+	cmd.Flags().StringVar(&providerName, "provider", "",
+		"explicit provider")
+
+	cmd.MarkFlagRequired("model")
+
+	return cmd
+}
+
+// checkProbeCache validates a request against a cached probe result.
+// It fails for models known to be inaccessible and warns about
+// parameters the model does not accept.
+func checkProbeCache(p provider.Provider, req *generator.Request) error {
+	cache, err := probe.Load(probe.DefaultCachePath())
+	if err != nil {
+		return nil
+	}
+
+	result, ok := cache.Get(provider.QualifiedModelID(p.Name(), req.Model))
+	if !ok {
+		return nil
+	}
+
+	if !result.Available {
+		return fmt.Errorf("model %s is not accessible with the current API key (probed %s); "+
+			"re-run 'llm-imager probe -m %s' after fixing access",
+			result.Model, result.ProbedAt.Format("2006-01-02 15:04"), result.Model)
+	}
+
+	// Only parameters without config defaults are checked, so that
+	// defaults like aspect_ratio don't produce warnings on every run
+	params := map[string]bool{
+		"seed":            req.Seed != nil,
+		"negative_prompt": req.NegativePrompt != "",
+		"steps":           req.Steps > 0,
+	}
+	for _, name := range []string{"seed", "negative_prompt", "steps"} {
+		if params[name] && !result.Accepts(name) {
+			fmt.Fprintf(os.Stderr, "Warning: model %s does not accept %s; it will be ignored\n",
+				result.Model, name)
+		}
+	}
+
+	return nil
+}
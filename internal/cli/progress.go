@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// Progress events written by --progress-json, in addition to the HTTP
+// stages reported by httputil (submitted, polling, downloading)
+const (
+	progressQueued  = "queued"
+	progressSaved   = "saved"
+	progressSkipped = "skipped"
+	progressFailed  = "failed"
+)
+
+// progressEvent is one NDJSON line of --progress-json
+type progressEvent struct {
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`
+	Row    int       `json:"row,omitempty"`   // Batch row, from 1
+	Total  int       `json:"total,omitempty"` // Batch rows
+	Model  string    `json:"model,omitempty"`
+	Method string    `json:"method,omitempty"`
+	URL    string    `json:"url,omitempty"`
+	Status int       `json:"status,omitempty"`
+	Path   string    `json:"path,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// progressReporter writes progress events as NDJSON. A nil reporter
+// discards them.
+type progressReporter struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	row    int
+	total  int
+}
+
+// openProgress opens the --progress-json target: stderr, or a file or
+// FIFO path. Opening a FIFO waits until a reader opens it.
+func openProgress(target string) (*progressReporter, error) {
+	switch target {
+	case "":
+		return nil, nil
+	case "stderr", "-":
+		return &progressReporter{enc: json.NewEncoder(os.Stderr)}, nil
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open progress output: %w", err)
+	}
+	return &progressReporter{enc: json.NewEncoder(f), closer: f}, nil
+}
+
+// setRow sets the batch row of the following events
+func (p *progressReporter) setRow(row, total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.row, p.total = row, total
+	p.mu.Unlock()
+}
+
+func (p *progressReporter) emit(e progressEvent) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e.Time = time.Now()
+	if e.Row == 0 {
+		e.Row, e.Total = p.row, p.total
+	}
+	p.enc.Encode(e)
+}
+
+// failed reports a failed generation with its redacted error
+func (p *progressReporter) failed(model string, err error) {
+	p.emit(progressEvent{Event: progressFailed, Model: model, Error: redactor.Apply(err.Error())})
+}
+
+// context returns ctx with the HTTP requests of a generation reported
+// as progress events
+func (p *progressReporter) context(ctx context.Context, model string) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return httputil.WithProgress(ctx, func(pr httputil.Progress) {
+		p.emit(progressEvent{
+			Event:  pr.Stage,
+			Model:  model,
+			Method: pr.Method,
+			URL:    pr.URL,
+			Status: pr.Status,
+		})
+	})
+}
+
+func (p *progressReporter) Close() error {
+	if p == nil || p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
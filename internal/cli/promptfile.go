@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/batch"
+	"github.com/piligrim/llm-imager/internal/run"
+)
+
+// runPromptFile generates an image for every prompt of --prompt-file,
+// numbering the output path: -o out/cat.png saves out/cat_001.png,
+// out/cat_002.png and so on. With runs enabled the images and a results
+// file go to the run directory.
+func runPromptFile(ctx context.Context, opts *generateOptions) error {
+	if opts.plan {
+		return fmt.Errorf("--plan is not supported with --prompt-file; use --dry-run to check the prompts")
+	}
+
+	rows, err := batch.LoadPrompts(opts.promptFile)
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		rows[i].OutputName = indexedOutputName(filepath.Base(opts.outputPath), i, len(rows))
+	}
+	if !runsEnabled(opts) {
+		outputDir := filepath.Dir(opts.outputPath)
+		if err := validateRows(ctx, rows, outputDir, opts); err != nil {
+			return err
+		}
+		return generateRows(ctx, rows, outputDir, "", opts, nil)
+	}
+
+	// The run directory replaces the output directory, so the rows are
+	// checked against it
+	r, err := startRun()
+	if err != nil {
+		return err
+	}
+	m := run.Manifest{Command: "generate", Model: opts.model}
+	err = validateRows(ctx, rows, r.Dir, opts)
+	if err == nil {
+		err = generateRows(ctx, rows, r.Dir, filepath.Join(r.Dir, "results.jsonl"), opts, &m)
+	}
+	finishRun(r, m, err)
+	return err
+}
+
+// indexedOutputName inserts the 1-based index of a prompt before the
+// extension of name, zero-padded to at least three digits so the files
+// sort in prompt order
+func indexedOutputName(name string, index, total int) string {
+	ext := filepath.Ext(name)
+	width := max(len(fmt.Sprint(total)), 3)
+	return fmt.Sprintf("%s_%0*d%s", strings.TrimSuffix(name, ext), width, index+1, ext)
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// rawDump is the file written by --save-raw-on-error
+type rawDump struct {
+	Time      time.Time           `json:"time"`
+	Provider  string              `json:"provider"`
+	Model     string              `json:"model"`
+	Error     string              `json:"error"`
+	Exchanges []httputil.Exchange `json:"exchanges"`
+}
+
+// saveRawResponses writes the responses recorded during a failed
+// generation to a new file in dir, applying the privacy redaction rules
+func saveRawResponses(dir, providerName, model string, genErr error, rec *httputil.Recorder) (string, error) {
+	exchanges := rec.Exchanges()
+	if len(exchanges) == 0 {
+		return "", nil
+	}
+	for i := range exchanges {
+		exchanges[i].URL = redactor.Apply(exchanges[i].URL)
+		exchanges[i].Body = redactor.Apply(exchanges[i].Body)
+	}
+
+	dump := rawDump{
+		Time:      time.Now(),
+		Provider:  providerName,
+		Model:     model,
+		Error:     redactor.Apply(genErr.Error()),
+		Exchanges: exchanges,
+	}
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, fmt.Sprintf("raw-%s-%s-*.json", providerName, dump.Time.Format("20060102-150405")))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
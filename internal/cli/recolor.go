@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newRecolorCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var image string
+
+	cmd := &cobra.Command{
+		Use:   "recolor",
+		Short: "Change the colors of an object in an image",
+		Long: `Recolor an object of an image with Stability AI search-and-recolor:
+--select describes the object, found without a mask, and --prompt the new
+colors. The rest of the image is kept.`,
+		Example: `  llm-imager recolor --image product.jpg --select "the dress" -p "emerald green" -o dress-green.png
+  llm-imager recolor --image car.png --select "the car" -p "matte black" --seed 7 -o car-black.png`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.inputImages = []string{image}
+
+			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "",
+		"image file to recolor (required)")
+	cmd.Flags().StringVar(&opts.selectObject, "select", "",
+		"object to recolor, e.g. \"the dress\" (required)")
+	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
+		"the new colors (required)")
+	cmd.Flags().StringVar(&opts.negativePrompt, "negative-prompt", "",
+		"negative prompt (things to avoid)")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output file path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "stability/stable-image-core",
+		"Stability model; all use the same search-and-recolor endpoint")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0,
+		"seed for reproducibility")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate placeholder images without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed requests to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("image")
+	cmd.MarkFlagRequired("select")
+	cmd.MarkFlagRequired("prompt")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
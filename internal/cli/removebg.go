@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+func newRemoveBgCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var image string
+
+	cmd := &cobra.Command{
+		Use:   "remove-bg",
+		Short: "Remove the background of an image",
+		Long: `Cut the subject of an image out onto a transparent background and save
+it as a PNG. Stability AI's remove-background endpoint is used when the
+Stability provider is configured, else the rembg model on Replicate; -m
+selects either provider or another Replicate background removal model.`,
+		Example: `  llm-imager remove-bg --image product.jpg -o product.png
+  llm-imager remove-bg --image portrait.png -m replicate/lucataco/remove-bg -o portrait-cut.png`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.model == "" && !opts.dryRun {
+				model, err := defaultRemoveBgModel()
+				if err != nil {
+					return err
+				}
+				opts.model = model
+			}
+			opts.inputImages = []string{image}
+			opts.removeBackground = true
+
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "",
+		"image file to cut out (required)")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output PNG path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
+		"stability/<any model> or a Replicate background removal model (default: Stability if configured, else replicate/"+provider.ReplicateRembgModel+")")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate placeholder images without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed requests to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("image")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// defaultRemoveBgModel picks Stability's remove-background endpoint when
+// the provider has an API key, else the rembg model on Replicate
+func defaultRemoveBgModel() (string, error) {
+	if _, err := registry.GetByName("stability"); err == nil && cfg.Providers.Stability.APIKey != "" {
+		return "stability/remove-background", nil
+	}
+	if _, err := registry.GetByName("replicate"); err == nil && cfg.Providers.Replicate.APIKey != "" {
+		return "replicate/" + provider.ReplicateRembgModel, nil
+	}
+	return "", fmt.Errorf("remove-bg needs an API key for Stability AI (STABILITY_API_KEY) or Replicate (REPLICATE_API_TOKEN)")
+}
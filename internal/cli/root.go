@@ -1,19 +1,28 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/piligrim/llm-imager/internal/catalog"
 	"github.com/piligrim/llm-imager/internal/config"
+	"github.com/piligrim/llm-imager/internal/output"
 	"github.com/piligrim/llm-imager/internal/provider"
+	"github.com/piligrim/llm-imager/internal/redact"
+	"github.com/piligrim/llm-imager/pkg/httputil"
 )
 
 var (
-	cfgFile  string
-	cfg      *config.Config
-	registry *provider.Registry
+	cfgFile   string
+	incognito bool
+	debug     bool
+	cfg       *config.Config
+	redactor  *redact.Redactor
+	registry  *provider.Registry
 )
 
 // NewRootCmd creates the root command
@@ -32,17 +41,31 @@ Examples:
   llm-imager -m google/gemini-2.5-flash-image -p "abstract art" -o art.png
   llm-imager -m openai/dall-e-3 -p "futuristic city" --quality hd -o city.png`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return initConfig()
+			return initConfig(cmd)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !cmd.Flags().Changed("prompt") {
+			if !cmd.Flags().Changed("prompt") && !cmd.Flags().Changed("prompt-file") {
 				return cmd.Help()
 			}
+			if err := checkPromptFlags(cmd); err != nil {
+				return err
+			}
 			if !cmd.Flags().Changed("output") {
 				return fmt.Errorf("required flag \"output\" not set")
 			}
 			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasSafetyTolerance = cmd.Flags().Changed("safety-tolerance")
 			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			opts.hasAltText = cmd.Flags().Changed("alt-text")
+			opts.hasAutoTag = cmd.Flags().Changed("auto-tag")
+			opts.hasOCR = cmd.Flags().Changed("ocr")
+			opts.hasStrength = cmd.Flags().Changed("strength")
+			opts.hasControlStrength = cmd.Flags().Changed("control-strength")
+			if opts.promptFile != "" {
+				return runPromptFile(cmd.Context(), opts)
+			}
 			return runGenerate(cmd.Context(), opts)
 		},
 		SilenceUsage: true,
@@ -50,37 +73,34 @@ Examples:
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
 		"config file (default: ~/.llm-imager.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&incognito, "incognito", false,
+		"do not write prompt text to results files (same as privacy.store_prompts: false)")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false,
+		"print raw provider responses and decoding warnings to stderr")
 
-	rootCmd.Flags().StringVarP(&opts.model, "model", "m", "",
-		"model to use (e.g., google/gemini-2.5-flash-image)")
 	rootCmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
 		"text prompt for image generation")
 	rootCmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
 		"output file path")
-	rootCmd.Flags().StringVar(&opts.size, "size", "",
-		"image size (e.g., 1024x1024)")
-	rootCmd.Flags().StringVar(&opts.quality, "quality", "",
-		"image quality (standard/hd or low/medium/high)")
-	rootCmd.Flags().StringVar(&opts.style, "style", "",
-		"image style (natural/vivid)")
-	rootCmd.Flags().IntVarP(&opts.count, "count", "n", 0,
-		"number of images to generate")
-	rootCmd.Flags().Int64Var(&opts.seed, "seed", 0,
-		"seed for reproducibility")
-	rootCmd.Flags().StringVar(&opts.negativePrompt, "negative-prompt", "",
-		"negative prompt (things to avoid)")
-	rootCmd.Flags().StringVar(&opts.aspectRatio, "aspect-ratio", "",
-		"aspect ratio (e.g., 16:9, 1:1)")
-	rootCmd.Flags().IntVar(&opts.steps, "steps", 0,
-		"number of generation steps (Stability AI, Replicate)")
-	rootCmd.Flags().StringVar(&opts.providerName, "provider", "",
-		"explicit provider (openai/google/stability/replicate/openrouter)")
-	rootCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
-		"generate placeholder images without API calls")
+	addPromptFileFlag(rootCmd, opts)
+	addGenerateFlags(rootCmd, opts)
 
 	rootCmd.AddCommand(
 		newGenerateCmd(),
+		newEditCmd(),
+		newVariationsCmd(),
+		newOutpaintCmd(),
+		newRecolorCmd(),
+		newRemoveBgCmd(),
+		newVectorizeCmd(),
+		newVideoCmd(),
+		newDescribeCmd(),
+		newBatchCmd(),
 		newListCmd(),
+		newModelsCmd(),
+		newCapabilitiesCmd(),
+		newProbeCmd(),
+		newGCCmd(),
 		newVersionCmd(),
 		newCompletionCmd(),
 	)
@@ -88,7 +108,26 @@ Examples:
 	return rootCmd
 }
 
-func initConfig() error {
+// skipPluginsAnnotation marks commands that never resolve a provider, so
+// plugin discovery is skipped for them and their subcommands
+const skipPluginsAnnotation = "skip-plugins"
+
+// usesProviders reports whether cmd may resolve a provider and so needs
+// the plugins discovered. Help and shell completion never do.
+func usesProviders(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[skipPluginsAnnotation] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func initConfig(cmd *cobra.Command) error {
 	loader := config.NewLoader()
 
 	var err error
@@ -101,21 +140,65 @@ func initConfig() error {
 		return err
 	}
 
+	if incognito {
+		cfg.Privacy.StorePrompts = false
+	}
+	if debug {
+		provider.SetDebugOutput(os.Stderr)
+	}
+
+	switch cfg.Output.Layout {
+	case "", "flat", "cas":
+	default:
+		return fmt.Errorf("unsupported output.layout %q, supported: flat, cas", cfg.Output.Layout)
+	}
+	if _, err := output.ParseConflictPolicy(cfg.Output.OnConflict); err != nil {
+		return fmt.Errorf("output.on_conflict: %w", err)
+	}
+
+	maxBandwidth, err := httputil.ParseBandwidth(cfg.Network.MaxBandwidth)
+	if err != nil {
+		return fmt.Errorf("network.max_bandwidth: %w", err)
+	}
+	httputil.SetBandwidthLimit(maxBandwidth)
+	httputil.SetUserAgent(userAgent())
+
+	rules := make([]redact.Rule, len(cfg.Privacy.Redact))
+	for i, r := range cfg.Privacy.Redact {
+		rules[i] = redact.Rule{Pattern: r.Pattern, Replacement: r.Replacement}
+	}
+	if redactor, err = redact.Compile(rules); err != nil {
+		return fmt.Errorf("privacy.redact: %w", err)
+	}
+
+	if err := catalog.LoadOverride(catalog.DefaultOverridePath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if age := time.Since(catalog.Current().Updated()); cfg.Catalog.MaxAge > 0 && age > cfg.Catalog.MaxAge {
+		fmt.Fprintf(os.Stderr, "Warning: model catalog is %d days old, run 'llm-imager models refresh'\n",
+			int(age.Hours()/24))
+	}
+
 	registry = provider.NewRegistry()
-	if err := initProviders(); err != nil {
+	registry.SetAliases(cfg.Routing.Aliases)
+	registry.SetPriority(cfg.Routing.Priority)
+	if err := initProviders(usesProviders(cmd)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func initProviders() error {
+// initProviders registers the configured providers; plugins only if
+// withPlugins
+func initProviders(withPlugins bool) error {
 	// OpenAI
 	if cfg.Providers.OpenAI.Enabled {
 		openai := provider.NewOpenAI(&provider.ProviderConfig{
 			APIKey:     cfg.Providers.OpenAI.APIKey,
 			BaseURL:    cfg.Providers.OpenAI.BaseURL,
 			MaxRetries: cfg.Providers.OpenAI.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.OpenAI.Middleware),
 		})
 		registry.Register(openai)
 	}
@@ -125,6 +208,7 @@ func initProviders() error {
 		google, err := provider.NewGoogle(&provider.ProviderConfig{
 			APIKey:     cfg.Providers.Google.APIKey,
 			MaxRetries: cfg.Providers.Google.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Google.Middleware),
 		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to initialize Google provider: %v\n", err)
@@ -139,6 +223,12 @@ func initProviders() error {
 			APIKey:     cfg.Providers.OpenRouter.APIKey,
 			BaseURL:    cfg.Providers.OpenRouter.BaseURL,
 			MaxRetries: cfg.Providers.OpenRouter.MaxRetries,
+			AppURL:     cfg.Providers.OpenRouter.AppURL,
+			AppTitle:   cfg.Providers.OpenRouter.AppTitle,
+			Headers:    cfg.Providers.OpenRouter.Headers,
+			Routing:    routingPreferences(cfg.Providers.OpenRouter.ProviderRouting),
+			LiveModels: cfg.Providers.OpenRouter.LiveModels,
+			Middleware: providerMiddleware(cfg.Providers.OpenRouter.Middleware),
 		})
 		registry.Register(openrouter)
 	}
@@ -148,6 +238,7 @@ func initProviders() error {
 		stability := provider.NewStability(&provider.ProviderConfig{
 			APIKey:     cfg.Providers.Stability.APIKey,
 			MaxRetries: cfg.Providers.Stability.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Stability.Middleware),
 		})
 		registry.Register(stability)
 	}
@@ -157,10 +248,243 @@ func initProviders() error {
 		replicate := provider.NewReplicate(&provider.ProviderConfig{
 			APIKey:     cfg.Providers.Replicate.APIKey,
 			MaxRetries: cfg.Providers.Replicate.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Replicate.Middleware),
 		})
 		registry.Register(replicate)
 	}
 
+	// Ideogram
+	if cfg.Providers.Ideogram.Enabled {
+		ideogram := provider.NewIdeogram(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Ideogram.APIKey,
+			BaseURL:    cfg.Providers.Ideogram.BaseURL,
+			MaxRetries: cfg.Providers.Ideogram.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Ideogram.Middleware),
+		})
+		registry.Register(ideogram)
+	}
+
+	// Recraft
+	if cfg.Providers.Recraft.Enabled {
+		recraft := provider.NewRecraft(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Recraft.APIKey,
+			BaseURL:    cfg.Providers.Recraft.BaseURL,
+			MaxRetries: cfg.Providers.Recraft.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Recraft.Middleware),
+		})
+		registry.Register(recraft)
+	}
+
+	// Black Forest Labs
+	if cfg.Providers.BFL.Enabled {
+		bfl := provider.NewBFL(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.BFL.APIKey,
+			BaseURL:    cfg.Providers.BFL.BaseURL,
+			MaxRetries: cfg.Providers.BFL.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.BFL.Middleware),
+		})
+		registry.Register(bfl)
+	}
+
+	// Luma
+	if cfg.Providers.Luma.Enabled {
+		luma := provider.NewLuma(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Luma.APIKey,
+			BaseURL:    cfg.Providers.Luma.BaseURL,
+			MaxRetries: cfg.Providers.Luma.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Luma.Middleware),
+		})
+		registry.Register(luma)
+	}
+
+	// Cloudflare Workers AI
+	if cfg.Providers.Cloudflare.Enabled {
+		cloudflare := provider.NewCloudflare(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Cloudflare.APIKey,
+			BaseURL:    cfg.Providers.Cloudflare.BaseURL,
+			MaxRetries: cfg.Providers.Cloudflare.MaxRetries,
+			AccountID:  cfg.Providers.Cloudflare.AccountID,
+			Middleware: providerMiddleware(cfg.Providers.Cloudflare.Middleware),
+		})
+		registry.Register(cloudflare)
+	}
+
+	// Stable Horde
+	if cfg.Providers.StableHorde.Enabled {
+		stableHorde := provider.NewStableHorde(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.StableHorde.APIKey,
+			BaseURL:    cfg.Providers.StableHorde.BaseURL,
+			MaxRetries: cfg.Providers.StableHorde.MaxRetries,
+			MaxWait:    cfg.Providers.StableHorde.MaxWait,
+			Middleware: providerMiddleware(cfg.Providers.StableHorde.Middleware),
+		})
+		registry.Register(stableHorde)
+	}
+
+	// Novita AI
+	if cfg.Providers.Novita.Enabled {
+		novita := provider.NewNovita(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Novita.APIKey,
+			BaseURL:    cfg.Providers.Novita.BaseURL,
+			MaxRetries: cfg.Providers.Novita.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Novita.Middleware),
+		})
+		registry.Register(novita)
+	}
+
+	// Tencent Hunyuan
+	if cfg.Providers.Hunyuan.Enabled {
+		hunyuan := provider.NewHunyuan(&provider.ProviderConfig{
+			BaseURL:    cfg.Providers.Hunyuan.BaseURL,
+			MaxRetries: cfg.Providers.Hunyuan.MaxRetries,
+			Region:     cfg.Providers.Hunyuan.Region,
+			SecretID:   cfg.Providers.Hunyuan.SecretID,
+			SecretKey:  cfg.Providers.Hunyuan.SecretKey,
+			Middleware: providerMiddleware(cfg.Providers.Hunyuan.Middleware),
+		})
+		registry.Register(hunyuan)
+	}
+
+	// ByteDance Seedream
+	if cfg.Providers.Seedream.Enabled {
+		seedream := provider.NewSeedream(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Seedream.APIKey,
+			BaseURL:    cfg.Providers.Seedream.BaseURL,
+			MaxRetries: cfg.Providers.Seedream.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Seedream.Middleware),
+		})
+		registry.Register(seedream)
+	}
+
+	// Runware
+	if cfg.Providers.Runware.Enabled {
+		runware := provider.NewRunware(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.Runware.APIKey,
+			BaseURL:    cfg.Providers.Runware.BaseURL,
+			MaxRetries: cfg.Providers.Runware.MaxRetries,
+			Middleware: providerMiddleware(cfg.Providers.Runware.Middleware),
+		})
+		registry.Register(runware)
+	}
+
+	// LocalAI and other self-hosted OpenAI-compatible servers
+	if cfg.Providers.LocalAI.Enabled {
+		localai := provider.NewLocalAI(&provider.ProviderConfig{
+			APIKey:     cfg.Providers.LocalAI.APIKey,
+			BaseURL:    cfg.Providers.LocalAI.BaseURL,
+			MaxRetries: cfg.Providers.LocalAI.MaxRetries,
+			Timeout:    cfg.Providers.LocalAI.Timeout,
+			Middleware: providerMiddleware(cfg.Providers.LocalAI.Middleware),
+		})
+		registry.Register(localai)
+	}
+
+	// InvokeAI
+	if cfg.Providers.InvokeAI.Enabled {
+		invokeai := provider.NewInvokeAI(&provider.ProviderConfig{
+			BaseURL:    cfg.Providers.InvokeAI.BaseURL,
+			MaxRetries: cfg.Providers.InvokeAI.MaxRetries,
+			MaxWait:    cfg.Providers.InvokeAI.MaxWait,
+			Middleware: providerMiddleware(cfg.Providers.InvokeAI.Middleware),
+		})
+		registry.Register(invokeai)
+	}
+
+	// Azure OpenAI
+	if cfg.Providers.AzureOpenAI.Enabled {
+		azure := provider.NewAzureOpenAI(&provider.ProviderConfig{
+			APIKey:      cfg.Providers.AzureOpenAI.APIKey,
+			BaseURL:     cfg.Providers.AzureOpenAI.BaseURL,
+			MaxRetries:  cfg.Providers.AzureOpenAI.MaxRetries,
+			APIVersion:  cfg.Providers.AzureOpenAI.APIVersion,
+			Deployments: cfg.Providers.AzureOpenAI.Deployments,
+			Middleware:  providerMiddleware(cfg.Providers.AzureOpenAI.Middleware),
+		})
+		registry.Register(azure)
+	}
+
+	// AWS Bedrock
+	if cfg.Providers.Bedrock.Enabled {
+		bedrock := provider.NewBedrock(&provider.ProviderConfig{
+			BaseURL:    cfg.Providers.Bedrock.BaseURL,
+			MaxRetries: cfg.Providers.Bedrock.MaxRetries,
+			Region:     cfg.Providers.Bedrock.Region,
+			Profile:    cfg.Providers.Bedrock.Profile,
+			Middleware: providerMiddleware(cfg.Providers.Bedrock.Middleware),
+		})
+		registry.Register(bedrock)
+	}
+
+	// Custom OpenAI-compatible providers, registered after the built-in
+	// ones so they cannot replace them
+	for _, custom := range cfg.CustomProviders {
+		if custom.Enabled != nil && !*custom.Enabled {
+			continue
+		}
+		maxRetries := custom.MaxRetries
+		if maxRetries == 0 {
+			maxRetries = 3
+		}
+		p, err := provider.NewOpenAICompatible(&provider.ProviderConfig{
+			Name:       custom.Name,
+			APIKey:     custom.APIKey,
+			BaseURL:    custom.BaseURL,
+			API:        custom.API,
+			Models:     custom.Models,
+			MaxRetries: maxRetries,
+			Headers:    custom.Headers,
+			Middleware: providerMiddleware(custom.Middleware),
+		})
+		if err == nil {
+			err = registry.Register(p)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: custom_providers: %v\n", err)
+		}
+	}
+
+	// Providers declared in template files
+	templates, err := config.LoadProviderTemplates(cfg.ProviderTemplates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: provider_templates: %v\n", err)
+	}
+	for _, t := range templates {
+		p, err := provider.NewHTTPTemplate(&provider.HTTPTemplateSpec{
+			Name:       t.Name,
+			APIKey:     t.APIKey,
+			Models:     t.Models,
+			Params:     t.Params,
+			MaxRetries: t.MaxRetries,
+			Method:     t.Request.Method,
+			URL:        t.Request.URL,
+			Headers:    t.Request.Headers,
+			Body:       t.Request.Body,
+			ImagesPath: t.Response.Images,
+			ErrorPath:  t.Response.Error,
+			Format:     t.Response.Format,
+		})
+		if err == nil {
+			err = registry.Register(p)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: provider_templates: %v\n", err)
+		}
+	}
+
+	// Provider plugins, whose discovery runs each one, only for commands
+	// that can use them
+	if cfg.Plugins.Enabled && withPlugins {
+		plugins, errs := provider.DiscoverPlugins(context.Background(), config.ExpandHome(cfg.Plugins.Directory))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		for _, p := range plugins {
+			if err := registry.Register(p); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: plugin %s: %v\n", p.Path(), err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -170,3 +494,44 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// providerMiddleware converts the body mutations configured for a provider
+func providerMiddleware(s *config.MiddlewareSettings) *httputil.Middleware {
+	if s == nil {
+		return nil
+	}
+	return &httputil.Middleware{
+		Request:  mutations(s.Request),
+		Response: mutations(s.Response),
+	}
+}
+
+func mutations(settings []config.MutationSettings) []httputil.Mutation {
+	result := make([]httputil.Mutation, 0, len(settings))
+	for _, s := range settings {
+		var m httputil.Mutation
+		switch {
+		case s.Set != "":
+			m = httputil.Mutation{Op: "set", Path: s.Set, Value: s.Value}
+		case s.Rename != "":
+			m = httputil.Mutation{Op: "rename", Path: s.Rename, To: s.To}
+		case s.Remove != "":
+			m = httputil.Mutation{Op: "remove", Path: s.Remove}
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// routingPreferences converts upstream routing settings for a provider
+func routingPreferences(r *config.RoutingSettings) *provider.RoutingPreferences {
+	if r == nil {
+		return nil
+	}
+	return &provider.RoutingPreferences{
+		Order:          r.Order,
+		Only:           r.Only,
+		Ignore:         r.Ignore,
+		AllowFallbacks: r.AllowFallbacks,
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/piligrim/llm-imager/internal/run"
+)
+
+// runsEnabled reports whether the invocation gets its own run directory,
+// from --run or output.runs
+func runsEnabled(opts *generateOptions) bool {
+	if opts.hasRun {
+		return opts.run
+	}
+	return cfg.Output.Runs
+}
+
+// runsDir is the directory run directories are created in
+func runsDir() string {
+	return filepath.Join(cfg.Output.Directory, "runs")
+}
+
+// startRun creates the run directory under <output.directory>/runs and
+// starts copying console output into its log. Console output is not
+// logged when prompts must not be stored, since progress lines show them.
+func startRun() (*run.Run, error) {
+	r, err := run.New(runsDir())
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Privacy.StorePrompts {
+		if err := r.Capture(); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Printf("Run: %s (%s)\n", r.ID, r.Dir)
+	return r, nil
+}
+
+// finishRun writes the manifest and report of a run, recording err as
+// the run error
+func finishRun(r *run.Run, m run.Manifest, err error) {
+	if err != nil {
+		m.Error = redactor.Apply(err.Error())
+	}
+	if finishErr := r.Finish(m); finishErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", finishErr)
+	}
+}
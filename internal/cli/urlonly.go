@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/output"
+)
+
+// hostedManifest is the JSON file written by --url-only, listing the
+// hosted images left on the provider side
+type hostedManifest struct {
+	Model       string            `json:"model"`
+	Provider    string            `json:"provider"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Images      []generator.Image `json:"images"`
+}
+
+// splitHosted separates images left at their hosted URLs from images whose
+// data was returned inline and must be written to disk
+func splitHosted(images []generator.Image) (local, hosted []generator.Image) {
+	for _, img := range images {
+		if len(img.Data) == 0 && img.URL != "" {
+			hosted = append(hosted, img)
+		} else {
+			local = append(local, img)
+		}
+	}
+	return local, hosted
+}
+
+// writeHostedURLs prints the hosted image URLs with their expiry and
+// records them in a JSON file next to the output path. The file path is
+// empty when the conflict policy keeps an existing file.
+func writeHostedURLs(writer *output.Writer, resp *generator.Response, hosted []generator.Image, outputPath string) (string, error) {
+	for _, img := range hosted {
+		if img.ExpiresAt != nil {
+			fmt.Printf("URL: %s (expires %s)\n", img.URL, img.ExpiresAt.Format(time.RFC3339))
+		} else {
+			fmt.Printf("URL: %s\n", img.URL)
+		}
+	}
+
+	data, err := json.MarshalIndent(hostedManifest{
+		Model:       resp.Model,
+		Provider:    resp.Provider,
+		GeneratedAt: resp.GeneratedAt,
+		Images:      hosted,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path, skip, err := writer.Target(withFormatExt(outputPath, "json"))
+	if err != nil || skip {
+		return "", err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
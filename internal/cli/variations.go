@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// defaultVariationStrength keeps image-to-image variations close to the
+// source image
+const defaultVariationStrength = 0.35
+
+// variationPrompt describes a variation to image-to-image models when no
+// prompt is given
+const variationPrompt = "A variation of this image with the same subject, style and composition"
+
+// variationEditPrompt asks edit models for a variation; a given prompt is
+// appended as guidance
+const variationEditPrompt = "Create a variation of this image: keep the subject, style and composition, but vary the details."
+
+func newVariationsCmd() *cobra.Command {
+	opts := &generateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "variations",
+		Short: "Make variations of an existing image",
+		Long: `Make N variations of an existing image. How depends on the model:
+
+  OpenAI DALL-E 2           variations endpoint, no prompt
+  Stability AI, Replicate   image-to-image at low strength (--strength, default 0.35)
+  Gemini, GPT Image 1       edit asking for a variation of the image
+
+The prompt is optional and guides image-to-image and edit variations.
+Variations are saved with the usual multi-image names.`,
+		Example: `  llm-imager variations --image logo.png -n 4 -m openai/dall-e-2 -o logo.png
+  llm-imager variations --image room.jpg -n 3 -m stability/sd3-large --strength 0.5 -o room.png
+  llm-imager variations --image cat.png -p "in autumn colors" -m google/gemini-2.5-flash-image -o cat.png`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			opts.hasStrength = cmd.Flags().Changed("strength")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.variationsOf, "image", "",
+		"image file to make variations of (required)")
+	cmd.Flags().IntVarP(&opts.count, "count", "n", 4,
+		"number of variations")
+	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
+		"optional guidance for image-to-image and edit variations")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output file path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
+		"model to use (e.g., openai/dall-e-2)")
+	cmd.Flags().StringVar(&opts.providerName, "provider", "",
+		"explicit provider")
+	cmd.Flags().StringVar(&opts.size, "size", "",
+		"output size (OpenAI)")
+	cmd.Flags().Float64Var(&opts.strength, "strength", defaultVariationStrength,
+		"how far image-to-image variations may depart from the image, 0-1")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0,
+		"seed for reproducibility (Stability AI, Replicate)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate placeholder images without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.autoCorrect, "auto-correct", false,
+		"use the closest matching model if the model is not found")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false,
+		"fail instead of warning when the model is deprecated")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().IntVar(&opts.palette, "palette", 0,
+		"record the N dominant colors of each image in its metadata sidecar (default from output.palette)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed requests to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("image")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// variationCall prepares req to make variations of src with p and returns
+// the call making them: the variations endpoint if the model has one, else
+// image-to-image, else an edit
+func variationCall(p provider.Provider, req *generator.Request, src generator.InputImage) (func(context.Context, *generator.Request) (*generator.Response, error), error) {
+	if v, ok := p.(provider.Variator); ok && v.AcceptsVariations(req.Model) {
+		req.InitImage = &src
+		return v.Vary, nil
+	}
+
+	if ip, ok := p.(provider.InitImageProvider); ok && ip.AcceptsInitImage(req.Model) {
+		req.InitImage = &src
+		if req.Strength == nil {
+			strength := defaultVariationStrength
+			req.Strength = &strength
+		}
+		if req.Prompt == "" {
+			req.Prompt = variationPrompt
+		}
+		return p.Generate, nil
+	}
+
+	// Edit providers reject models without an edit endpoint only once called
+	m, known := catalog.Current().Lookup(req.Model)
+	if e, ok := p.(provider.Editor); ok && (!known || slices.Contains(m.Features, "edit")) {
+		req.InputImages = []generator.InputImage{src}
+		req.Prompt = strings.TrimSpace(variationEditPrompt + " " + req.Prompt)
+		return e.Edit, nil
+	}
+
+	return nil, fmt.Errorf("model %s cannot make variations; use openai/dall-e-2, a Stability image-to-image model or a Gemini image model", req.Model)
+}
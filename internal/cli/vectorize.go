@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+func newVectorizeCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var image string
+
+	cmd := &cobra.Command{
+		Use:   "vectorize",
+		Short: "Convert a raster image into SVG",
+		Long: `Trace a PNG, JPEG or WebP image into an SVG file. Recraft's vectorize
+endpoint is used when the Recraft provider is configured, else the Recraft
+vectorize model on Replicate; -m selects either provider or another
+Replicate vectorization model. The output is always saved as .svg.`,
+		Example: `  llm-imager vectorize --image logo.png -o logo.svg
+  llm-imager vectorize --image icon.png -m replicate/` + provider.ReplicateVectorizeModel + ` -o icon.svg`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.model == "" && !opts.dryRun {
+				model, err := defaultVectorizeModel()
+				if err != nil {
+					return err
+				}
+				opts.model = model
+			}
+			opts.inputImages = []string{image}
+			opts.vectorize = true
+
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "",
+		"image file to vectorize (required)")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output SVG path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
+		"recraft/<any model> or a Replicate vectorization model (default: Recraft if configured, else replicate/"+provider.ReplicateVectorizeModel+")")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate placeholder images without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to each image as <image>.json (default from output.metadata)")
+	cmd.Flags().BoolVar(&opts.urlOnly, "url-only", false,
+		"keep the SVG at its hosted URL instead of downloading it")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed requests to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save images, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("image")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// defaultVectorizeModel picks Recraft's vectorize endpoint when the
+// provider has an API key, else the Recraft vectorize model on Replicate
+func defaultVectorizeModel() (string, error) {
+	if _, err := registry.GetByName("recraft"); err == nil && cfg.Providers.Recraft.APIKey != "" {
+		return "recraft/recraftv3", nil
+	}
+	if _, err := registry.GetByName("replicate"); err == nil && cfg.Providers.Replicate.APIKey != "" {
+		return "replicate/" + provider.ReplicateVectorizeModel, nil
+	}
+	return "", fmt.Errorf("vectorize needs an API key for Recraft (RECRAFT_API_KEY) or Replicate (REPLICATE_API_TOKEN)")
+}
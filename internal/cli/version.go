@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -15,8 +16,9 @@ var (
 
 func newVersionCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "version",
-		Short: "Print version information",
+		Use:         "version",
+		Annotations: map[string]string{skipPluginsAnnotation: "true"},
+		Short:       "Print version information",
 		Run: func(cmd *cobra.Command, args []string) {
 			fmt.Printf("llm-imager %s\n", Version)
 			fmt.Printf("  Git commit: %s\n", GitCommit)
@@ -24,3 +26,13 @@ func newVersionCmd() *cobra.Command {
 		},
 	}
 }
+
+// userAgent returns the User-Agent of provider requests, llm-imager/<version>
+// followed by network.user_agent_suffix
+func userAgent() string {
+	ua := "llm-imager/" + Version
+	if suffix := strings.TrimSpace(cfg.Network.UserAgentSuffix); suffix != "" {
+		ua += " " + suffix
+	}
+	return ua
+}
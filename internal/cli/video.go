@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/output"
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// videoNoticeInterval is how often a running video job is reported as
+// still running
+const videoNoticeInterval = 30 * time.Second
+
+func newVideoCmd() *cobra.Command {
+	opts := &generateOptions{}
+	var image string
+
+	cmd := &cobra.Command{
+		Use:   "video",
+		Short: "Generate a video from a prompt or an image",
+		Long: `Generate a short video from the prompt with a Luma Ray model or a Replicate
+text-to-video model, or animate --image into one with Stability AI
+image-to-video, Luma or a Replicate image-to-video model, guided by the
+prompt where the model takes one. The video is saved as MP4 (or WebM, if
+that is what the model returns) under the extension of its format.
+
+With --image, Stability AI is used when configured, else
+` + provider.ReplicateVideoModel + ` on Replicate; without it,
+` + provider.ReplicateTextVideoModel + ` on Replicate, else luma/ray-2. Luma
+fetches the image itself, so --image must be a public URL there; Stability
+AI and Replicate take a file. --duration and --fps are passed to models
+that take them. Video jobs take minutes: progress is reported every 30
+seconds.`,
+		Example: `  llm-imager video -p "waves rolling onto a beach at sunset" --duration 5 -o beach.mp4
+  llm-imager video --image product.png -p "slow orbit around the product" -o product.mp4
+  llm-imager video --image https://example.com/cat.jpg -p "the cat yawns" -m luma/ray-2 -o cat.mp4
+  llm-imager video -p "a paper boat drifting" -m replicate/kwaivgi/kling-v2.1 --duration 10 -o boat.mp4`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if image == "" && opts.prompt == "" {
+				return fmt.Errorf("video needs --prompt, --image or both")
+			}
+			if opts.videoDuration < 0 || opts.fps < 0 {
+				return fmt.Errorf("--duration and --fps must not be negative")
+			}
+			if opts.model == "" && !opts.dryRun {
+				model, err := defaultVideoModel(image == "")
+				if err != nil {
+					return err
+				}
+				opts.model = model
+			}
+			if strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") {
+				opts.imageRefs = []string{image}
+			} else if image != "" {
+				opts.inputImages = []string{image}
+			}
+			opts.video = true
+
+			opts.hasSeed = cmd.Flags().Changed("seed")
+			opts.hasDryRun = cmd.Flags().Changed("dry-run")
+			opts.hasRun = cmd.Flags().Changed("run")
+			opts.hasMetadata = cmd.Flags().Changed("metadata")
+			return runGenerate(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "",
+		"image file to animate, or its public URL for Luma")
+	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "",
+		"description of the video, or of the motion with --image")
+	cmd.Flags().StringVar(&opts.negativePrompt, "negative-prompt", "",
+		"negative prompt (Replicate models taking one)")
+	cmd.Flags().StringVar(&opts.aspectRatio, "aspect-ratio", "",
+		"aspect ratio without --image, e.g. 16:9 (Luma, Replicate models taking one)")
+	cmd.Flags().IntVar(&opts.videoDuration, "duration", 0,
+		"video length in seconds (Luma, Replicate models taking one; default: the model's)")
+	cmd.Flags().IntVar(&opts.fps, "fps", 0,
+		"frames per second (Replicate models taking one; default: the model's)")
+	cmd.Flags().StringVarP(&opts.outputPath, "output", "o", "",
+		"output video path (required)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "",
+		"stability/<any model>, luma/ray-2, luma/ray-flash-2 or a Replicate video model (default: Stability if configured, else replicate/"+provider.ReplicateVideoModel+"; without --image replicate/"+provider.ReplicateTextVideoModel+", else luma/ray-2)")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0,
+		"seed for reproducibility")
+	cmd.Flags().StringArrayVar(&opts.params, "param", nil,
+		"model parameter as key=value, repeatable (Replicate inputs)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"generate a placeholder animated GIF without API calls")
+	cmd.Flags().BoolVar(&opts.plan, "plan", false,
+		"print the provider, endpoint, parameters and estimated cost and time without generating")
+	cmd.Flags().BoolVar(&opts.metadata, "metadata", false,
+		"save a JSON sidecar next to the video as <video>.json (default from output.metadata)")
+	cmd.Flags().BoolVar(&opts.urlOnly, "url-only", false,
+		"keep the video at its hosted URL instead of downloading it (Luma, Replicate)")
+	cmd.Flags().StringVar(&opts.saveRawDir, "save-raw-on-error", "",
+		"save the raw provider responses of failed requests to this directory")
+	cmd.Flags().StringVar(&opts.onConflict, "on-conflict", "",
+		"when an output file exists: error, overwrite, suffix or skip (default from output.on_conflict)")
+	cmd.Flags().BoolVar(&opts.run, "run", false,
+		"save the video, manifest, log and report under <output directory>/runs/<run ID> (default from output.runs)")
+	cmd.Flags().StringVar(&opts.progressJSON, "progress-json", "",
+		"write NDJSON progress events to stderr, or to this file or FIFO")
+	cmd.Flags().Lookup("progress-json").NoOptDefVal = "stderr"
+
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// defaultVideoModel picks Stability's image-to-video endpoint when the
+// provider has an API key, else the default video model on Replicate.
+// Stability cannot generate from text alone, so text-to-video falls back
+// to Luma instead.
+func defaultVideoModel(textOnly bool) (string, error) {
+	replicateKey := cfg.Providers.Replicate.APIKey != ""
+	if _, err := registry.GetByName("replicate"); err != nil {
+		replicateKey = false
+	}
+	if textOnly {
+		if replicateKey {
+			return "replicate/" + provider.ReplicateTextVideoModel, nil
+		}
+		if _, err := registry.GetByName("luma"); err == nil && cfg.Providers.Luma.APIKey != "" {
+			return "luma/ray-2", nil
+		}
+		return "", fmt.Errorf("text-to-video needs an API key for Replicate (REPLICATE_API_TOKEN) or Luma (LUMA_API_KEY)")
+	}
+	if _, err := registry.GetByName("stability"); err == nil && cfg.Providers.Stability.APIKey != "" {
+		return "stability/stable-video-diffusion", nil
+	}
+	if replicateKey {
+		return "replicate/" + provider.ReplicateVideoModel, nil
+	}
+	return "", fmt.Errorf("video needs an API key for Stability AI (STABILITY_API_KEY) or Replicate (REPLICATE_API_TOKEN), or -m luma/ray-2")
+}
+
+// generateVideo runs a video job, printing how long it has been running
+// every videoNoticeInterval until it finishes
+func generateVideo(ctx context.Context, p provider.Provider, req *generator.Request) (*generator.Response, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		start := time.Now()
+		ticker := time.NewTicker(videoNoticeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Printf("Still generating the video (%s elapsed)...\n", time.Since(start).Round(time.Second))
+			}
+		}
+	}()
+	return p.(provider.VideoGenerator).GenerateVideo(ctx, req)
+}
+
+// writeVideos saves the videos of resp like images, recording hosted
+// ones in the JSON file of --url-only, and returns the saved paths
+func writeVideos(writer *output.Writer, resp *generator.Response, outputPath string) ([]string, error) {
+	files := make([]generator.Image, 0, len(resp.Videos))
+	for _, v := range resp.Videos {
+		files = append(files, v.Image)
+	}
+
+	local, hosted := splitHosted(files)
+	var paths []string
+	if len(hosted) > 0 {
+		path, err := writeHostedURLs(writer, resp, hosted, outputPath)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if len(local) > 0 {
+		saved, err := writer.Write(local, outputPath)
+		paths = append(paths, saved...)
+		if err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
@@ -4,9 +4,24 @@ import "time"
 
 // Config is the root configuration structure
 type Config struct {
-	Defaults  DefaultsConfig  `mapstructure:"defaults"`
-	Providers ProvidersConfig `mapstructure:"providers"`
-	Output    OutputConfig    `mapstructure:"output"`
+	Defaults    DefaultsConfig            `mapstructure:"defaults"`
+	Providers   ProvidersConfig           `mapstructure:"providers"`
+	Output      OutputConfig              `mapstructure:"output"`
+	Routing     RoutingConfig             `mapstructure:"routing"`
+	Catalog     CatalogConfig             `mapstructure:"catalog"`
+	Cache       CacheConfig               `mapstructure:"cache"`
+	Plugins     PluginsConfig             `mapstructure:"plugins"`
+	Privacy     PrivacyConfig             `mapstructure:"privacy"`
+	Vision      VisionConfig              `mapstructure:"vision"`
+	AltText     AltTextConfig             `mapstructure:"alt_text"`
+	AutoTag     AutoTagConfig             `mapstructure:"auto_tag"`
+	OCR         OCRConfig                 `mapstructure:"ocr"`
+	Constraints ConstraintsConfig         `mapstructure:"constraints"`
+	Network     NetworkConfig             `mapstructure:"network"`
+	Templates   map[string]TemplateConfig `mapstructure:"templates"`
+
+	CustomProviders   []CustomProviderConfig `mapstructure:"custom_providers"`
+	ProviderTemplates []string               `mapstructure:"provider_templates"` // Template files or globs
 }
 
 // DefaultsConfig contains default generation settings
@@ -23,24 +38,236 @@ type DefaultsConfig struct {
 
 // ProvidersConfig contains settings for all providers
 type ProvidersConfig struct {
-	OpenAI     ProviderSettings `mapstructure:"openai"`
-	Google     ProviderSettings `mapstructure:"google"`
-	Stability  ProviderSettings `mapstructure:"stability"`
-	Replicate  ProviderSettings `mapstructure:"replicate"`
-	OpenRouter ProviderSettings `mapstructure:"openrouter"`
+	OpenAI      ProviderSettings `mapstructure:"openai"`
+	Google      ProviderSettings `mapstructure:"google"`
+	Stability   ProviderSettings `mapstructure:"stability"`
+	Replicate   ProviderSettings `mapstructure:"replicate"`
+	OpenRouter  ProviderSettings `mapstructure:"openrouter"`
+	AzureOpenAI ProviderSettings `mapstructure:"azure_openai"`
+	Bedrock     ProviderSettings `mapstructure:"bedrock"`
+	Ideogram    ProviderSettings `mapstructure:"ideogram"`
+	Recraft     ProviderSettings `mapstructure:"recraft"`
+	BFL         ProviderSettings `mapstructure:"bfl"`
+	Luma        ProviderSettings `mapstructure:"luma"`
+	Cloudflare  ProviderSettings `mapstructure:"cloudflare"`
+	StableHorde ProviderSettings `mapstructure:"stablehorde"`
+	Novita      ProviderSettings `mapstructure:"novita"`
+	Hunyuan     ProviderSettings `mapstructure:"hunyuan"`
+	Seedream    ProviderSettings `mapstructure:"seedream"`
+	Runware     ProviderSettings `mapstructure:"runware"`
+	LocalAI     ProviderSettings `mapstructure:"localai"`
+	InvokeAI    ProviderSettings `mapstructure:"invokeai"`
+}
+
+// Get returns the settings of a provider by name
+func (p ProvidersConfig) Get(name string) (ProviderSettings, bool) {
+	switch name {
+	case "openai":
+		return p.OpenAI, true
+	case "google":
+		return p.Google, true
+	case "stability":
+		return p.Stability, true
+	case "replicate":
+		return p.Replicate, true
+	case "openrouter":
+		return p.OpenRouter, true
+	case "azure-openai":
+		return p.AzureOpenAI, true
+	case "bedrock":
+		return p.Bedrock, true
+	case "ideogram":
+		return p.Ideogram, true
+	case "recraft":
+		return p.Recraft, true
+	case "bfl":
+		return p.BFL, true
+	case "luma":
+		return p.Luma, true
+	case "cloudflare":
+		return p.Cloudflare, true
+	case "stablehorde":
+		return p.StableHorde, true
+	case "novita":
+		return p.Novita, true
+	case "hunyuan":
+		return p.Hunyuan, true
+	case "seedream":
+		return p.Seedream, true
+	case "runware":
+		return p.Runware, true
+	case "localai":
+		return p.LocalAI, true
+	case "invokeai":
+		return p.InvokeAI, true
+	}
+	return ProviderSettings{}, false
 }
 
 // ProviderSettings contains settings for a single provider
 type ProviderSettings struct {
-	APIKey     string        `mapstructure:"api_key"`
-	BaseURL    string        `mapstructure:"base_url"`
-	Timeout    time.Duration `mapstructure:"timeout"`
-	MaxRetries int           `mapstructure:"max_retries"`
-	Enabled    bool          `mapstructure:"enabled"`
+	APIKey       string        `mapstructure:"api_key"`
+	DefaultModel string        `mapstructure:"default_model"`
+	BaseURL      string        `mapstructure:"base_url"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxRetries   int           `mapstructure:"max_retries"`
+	Enabled      bool          `mapstructure:"enabled"`
+
+	APIVersion      string            `mapstructure:"api_version"`      // Azure OpenAI
+	Deployments     map[string]string `mapstructure:"deployments"`      // Azure OpenAI: model -> deployment
+	Region          string            `mapstructure:"region"`           // Bedrock
+	Profile         string            `mapstructure:"profile"`          // Bedrock: AWS credentials profile
+	AccountID       string            `mapstructure:"account_id"`       // Cloudflare
+	SecretID        string            `mapstructure:"secret_id"`        // Hunyuan: Tencent Cloud secret ID
+	SecretKey       string            `mapstructure:"secret_key"`       // Hunyuan: Tencent Cloud secret key
+	MaxWait         time.Duration     `mapstructure:"max_wait"`         // Stable Horde, InvokeAI: longest wait in the queue
+	AppURL          string            `mapstructure:"app_url"`          // OpenRouter: HTTP-Referer attribution
+	AppTitle        string            `mapstructure:"app_title"`        // OpenRouter: X-Title attribution
+	Headers         map[string]string `mapstructure:"headers"`          // OpenRouter: extra request headers
+	ProviderRouting *RoutingSettings  `mapstructure:"provider_routing"` // OpenRouter: upstream provider selection
+	LiveModels      bool              `mapstructure:"live_models"`      // OpenRouter: list models from the live catalogue
+
+	Middleware *MiddlewareSettings `mapstructure:"middleware"` // JSON body mutations
+}
+
+// CustomProviderConfig declares an OpenAI-compatible provider
+type CustomProviderConfig struct {
+	Name       string              `mapstructure:"name"`
+	BaseURL    string              `mapstructure:"base_url"`
+	APIKey     string              `mapstructure:"api_key"` // May reference $VAR
+	API        string              `mapstructure:"api"`     // images (default) or chat
+	Models     []string            `mapstructure:"models"`
+	MaxRetries int                 `mapstructure:"max_retries"`
+	Headers    map[string]string   `mapstructure:"headers"`
+	Middleware *MiddlewareSettings `mapstructure:"middleware"`
+	Enabled    *bool               `mapstructure:"enabled"` // Defaults to true
 }
 
 // OutputConfig contains output settings
 type OutputConfig struct {
-	Directory string `mapstructure:"directory"`
-	Format    string `mapstructure:"format"`
+	Directory  string `mapstructure:"directory"`
+	Format     string `mapstructure:"format"`
+	Layout     string `mapstructure:"layout"`      // flat, or cas to store images by SHA256 under directory
+	OnConflict string `mapstructure:"on_conflict"` // error, overwrite, suffix or skip when a file exists
+	Runs       bool   `mapstructure:"runs"`        // Give every invocation a directory under directory/runs
+
+	// Age after which "gc" prunes the images of runs and of the cas
+	// store, 0 to keep them
+	Retention time.Duration `mapstructure:"retention"`
+
+	Crops    []string `mapstructure:"crops"`     // Aspect ratios of crop variants saved with each image, e.g. 4:5
+	CropName string   `mapstructure:"crop_name"` // Go template naming crop variants
+
+	Metadata bool `mapstructure:"metadata"` // Save a JSON sidecar next to each image
+	Palette  int  `mapstructure:"palette"`  // Dominant colors recorded in the sidecar, 0 for none
+}
+
+// RoutingConfig controls how bare model names are resolved to providers
+type RoutingConfig struct {
+	Aliases  map[string]string `mapstructure:"aliases"`  // alias -> provider/model
+	Priority []string          `mapstructure:"priority"` // provider order for ambiguous names
+}
+
+// CatalogConfig contains model catalog settings
+type CatalogConfig struct {
+	URL    string        `mapstructure:"url"`     // Hosted catalog for "models refresh"
+	MaxAge time.Duration `mapstructure:"max_age"` // Warn when the catalog is older (0 disables)
+}
+
+// CacheConfig contains retention settings for local cache files
+type CacheConfig struct {
+	MaxAge time.Duration `mapstructure:"max_age"` // Age after which "gc" prunes entries
+}
+
+// PluginsConfig controls the discovery of provider plugin executables
+type PluginsConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Directory string `mapstructure:"directory"` // A leading ~/ is the home directory
+}
+
+// RoutingSettings pins requests to upstream providers of an aggregator
+type RoutingSettings struct {
+	Order          []string `mapstructure:"order"`
+	Only           []string `mapstructure:"only"`
+	Ignore         []string `mapstructure:"ignore"`
+	AllowFallbacks *bool    `mapstructure:"allow_fallbacks"`
+}
+
+// MiddlewareSettings rewrites the JSON bodies exchanged with a provider,
+// for APIs that change shape before a release catches up
+type MiddlewareSettings struct {
+	Request  []MutationSettings `mapstructure:"request"`
+	Response []MutationSettings `mapstructure:"response"`
+}
+
+// MutationSettings is one body edit. Exactly one of Set, Rename and
+// Remove names the dot-separated field path to edit.
+type MutationSettings struct {
+	Set    string `mapstructure:"set"`    // Field to add or overwrite with Value
+	Value  any    `mapstructure:"value"`  // New value for Set
+	Rename string `mapstructure:"rename"` // Field to move to To
+	To     string `mapstructure:"to"`     // Destination for Rename
+	Remove string `mapstructure:"remove"` // Field to delete
+}
+
+// PrivacyConfig controls what is written to disk besides images
+type PrivacyConfig struct {
+	StorePrompts bool         `mapstructure:"store_prompts"` // Keep prompt text in results files
+	Redact       []RedactRule `mapstructure:"redact"`        // Applied to prompts in output and results files
+}
+
+// VisionConfig selects the vision model that describes saved images
+type VisionConfig struct {
+	Model string `mapstructure:"model"` // provider/model of an OpenAI, Google or OpenRouter vision model
+}
+
+// AltTextConfig controls accessibility descriptions of saved images
+type AltTextConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Describe every saved image, like --alt-text
+	Prompt  string `mapstructure:"prompt"`  // Instruction sent to the vision model with the image
+	Text    bool   `mapstructure:"txt"`     // Also write the description to <image name>.txt
+}
+
+// AutoTagConfig controls keyword tags of saved images
+type AutoTagConfig struct {
+	Enabled bool   `mapstructure:"enabled"`  // Tag every saved image, like --auto-tag
+	Model   string `mapstructure:"model"`    // Vision model for tags; empty uses vision.model
+	MaxTags int    `mapstructure:"max_tags"` // Most tags kept per image
+}
+
+// OCRConfig controls checking the text rendered in saved images
+type OCRConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Check prompts with quoted text, like --ocr
+	Model   string `mapstructure:"model"`   // Vision model reading the text; empty uses vision.model
+	Retries int    `mapstructure:"retries"` // Generations repeated while the text does not match
+}
+
+// ConstraintsConfig checks the size of returned images, which providers
+// do not always match to the request
+type ConstraintsConfig struct {
+	RequireSize   string `mapstructure:"require_size"`   // WxH, >=WxH or <=WxH
+	RequireAspect string `mapstructure:"require_aspect"` // W:H
+	OnViolation   string `mapstructure:"on_violation"`   // warn, fail or retry
+	Retries       int    `mapstructure:"retries"`        // Generations repeated by the retry action
+}
+
+// NetworkConfig controls the traffic with provider APIs
+type NetworkConfig struct {
+	MaxBandwidth    string `mapstructure:"max_bandwidth"`     // Cap on uploads and downloads together, e.g. 2MB/s or 8Mbit/s
+	UserAgentSuffix string `mapstructure:"user_agent_suffix"` // Appended to the llm-imager/<version> User-Agent, e.g. a contact
+}
+
+// RedactRule replaces matches of a regular expression in recorded prompts
+type RedactRule struct {
+	Pattern     string `mapstructure:"pattern"`
+	Replacement string `mapstructure:"replacement"` // Default "[redacted]"
+}
+
+// TemplateConfig is a named prompt template for batch manifests.
+// Prompt and NegativePrompt use Go template syntax, e.g. {{.name}}.
+type TemplateConfig struct {
+	Prompt         string `mapstructure:"prompt"`
+	NegativePrompt string `mapstructure:"negative_prompt"`
+	Model          string `mapstructure:"model"`
+	Size           string `mapstructure:"size"`
 }
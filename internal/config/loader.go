@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
 )
 
 // Loader loads configuration from file and environment variables
@@ -37,10 +39,35 @@ func bindEnvVariables(v *viper.Viper) {
 	v.BindEnv("providers.stability.api_key", "STABILITY_API_KEY")
 	v.BindEnv("providers.replicate.api_key", "REPLICATE_API_TOKEN")
 	v.BindEnv("providers.openrouter.api_key", "OPENROUTER_API_KEY")
+	v.BindEnv("providers.azure_openai.api_key", "AZURE_OPENAI_API_KEY")
+	v.BindEnv("providers.ideogram.api_key", "IDEOGRAM_API_KEY")
+	v.BindEnv("providers.recraft.api_key", "RECRAFT_API_KEY")
+	v.BindEnv("providers.bfl.api_key", "BFL_API_KEY")
+	v.BindEnv("providers.luma.api_key", "LUMAAI_API_KEY", "LUMA_API_KEY")
+	v.BindEnv("providers.cloudflare.api_key", "CLOUDFLARE_API_TOKEN")
+	v.BindEnv("providers.stablehorde.api_key", "STABLE_HORDE_API_KEY", "AI_HORDE_API_KEY")
+	v.BindEnv("providers.novita.api_key", "NOVITA_API_KEY")
+	v.BindEnv("providers.hunyuan.secret_id", "TENCENTCLOUD_SECRET_ID")
+	v.BindEnv("providers.hunyuan.secret_key", "TENCENTCLOUD_SECRET_KEY")
+	v.BindEnv("providers.hunyuan.region", "TENCENTCLOUD_REGION")
+	v.BindEnv("providers.seedream.api_key", "ARK_API_KEY")
+	v.BindEnv("providers.runware.api_key", "RUNWARE_API_KEY")
+	v.BindEnv("providers.localai.api_key", "LOCALAI_API_KEY")
 
 	// Base URLs for proxy/custom endpoints
 	v.BindEnv("providers.openai.base_url", "OPENAI_BASE_URL")
 	v.BindEnv("providers.openrouter.base_url", "OPENROUTER_BASE_URL")
+	v.BindEnv("providers.openrouter.app_url", "OPENROUTER_APP_URL")
+	v.BindEnv("providers.openrouter.app_title", "OPENROUTER_APP_TITLE")
+	v.BindEnv("providers.azure_openai.base_url", "AZURE_OPENAI_ENDPOINT")
+	v.BindEnv("providers.azure_openai.api_version", "OPENAI_API_VERSION")
+	v.BindEnv("providers.localai.base_url", "LOCALAI_BASE_URL")
+	v.BindEnv("providers.invokeai.base_url", "INVOKEAI_BASE_URL")
+
+	// AWS settings for Bedrock
+	v.BindEnv("providers.bedrock.region", "AWS_REGION", "AWS_DEFAULT_REGION")
+	v.BindEnv("providers.cloudflare.account_id", "CLOUDFLARE_ACCOUNT_ID")
+	v.BindEnv("providers.bedrock.profile", "AWS_PROFILE")
 }
 
 // Load loads configuration from file and environment
@@ -110,6 +137,25 @@ func DefaultConfigPath() string {
 	return filepath.Join(home, ".llm-imager.yaml")
 }
 
+// DefaultPluginDir returns the default provider plugin directory
+func DefaultPluginDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "llm-imager", "plugins")
+}
+
+// ExpandHome replaces a leading ~/ in path with the home directory
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
 func setDefaults(v *viper.Viper) {
 	// Defaults
 	v.SetDefault("defaults.model", "openai/dall-e-3")
@@ -140,7 +186,103 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("providers.openrouter.max_retries", 3)
 	v.SetDefault("providers.openrouter.enabled", true)
 
+	v.SetDefault("providers.ideogram.timeout", 120*time.Second)
+	v.SetDefault("providers.ideogram.max_retries", 3)
+	v.SetDefault("providers.ideogram.enabled", true)
+
+	v.SetDefault("providers.recraft.timeout", 120*time.Second)
+	v.SetDefault("providers.recraft.max_retries", 3)
+	v.SetDefault("providers.recraft.enabled", true)
+
+	v.SetDefault("providers.bfl.timeout", 120*time.Second)
+	v.SetDefault("providers.bfl.max_retries", 3)
+	v.SetDefault("providers.bfl.enabled", true)
+
+	v.SetDefault("providers.luma.timeout", 180*time.Second)
+	v.SetDefault("providers.luma.max_retries", 3)
+	v.SetDefault("providers.luma.enabled", true)
+
+	v.SetDefault("providers.cloudflare.timeout", 120*time.Second)
+	v.SetDefault("providers.cloudflare.max_retries", 3)
+	v.SetDefault("providers.cloudflare.enabled", true)
+
+	v.SetDefault("providers.stablehorde.timeout", 60*time.Second)
+	v.SetDefault("providers.stablehorde.max_retries", 3)
+	v.SetDefault("providers.stablehorde.max_wait", 10*time.Minute)
+	v.SetDefault("providers.stablehorde.enabled", true)
+
+	v.SetDefault("providers.novita.timeout", 120*time.Second)
+	v.SetDefault("providers.novita.max_retries", 3)
+	v.SetDefault("providers.novita.enabled", true)
+
+	v.SetDefault("providers.hunyuan.timeout", 120*time.Second)
+	v.SetDefault("providers.hunyuan.max_retries", 3)
+	v.SetDefault("providers.hunyuan.enabled", true)
+
+	v.SetDefault("providers.seedream.timeout", 120*time.Second)
+	v.SetDefault("providers.seedream.max_retries", 3)
+	v.SetDefault("providers.seedream.enabled", true)
+
+	v.SetDefault("providers.runware.timeout", 120*time.Second)
+	v.SetDefault("providers.runware.max_retries", 3)
+	v.SetDefault("providers.runware.enabled", true)
+
+	// LocalAI needs no key; local generation is slow, so the timeout is
+	// long and failed requests are retried once
+	v.SetDefault("providers.localai.base_url", "http://localhost:8080/v1")
+	v.SetDefault("providers.localai.timeout", 10*time.Minute)
+	v.SetDefault("providers.localai.max_retries", 1)
+	v.SetDefault("providers.localai.enabled", true)
+
+	v.SetDefault("providers.invokeai.base_url", "http://localhost:9090")
+	v.SetDefault("providers.invokeai.timeout", 120*time.Second)
+	v.SetDefault("providers.invokeai.max_retries", 3)
+	v.SetDefault("providers.invokeai.max_wait", 10*time.Minute)
+	v.SetDefault("providers.invokeai.enabled", true)
+
+	// Azure OpenAI is opt-in: it needs a resource endpoint and its model
+	// names overlap with OpenAI
+	v.SetDefault("providers.azure_openai.timeout", 120*time.Second)
+	v.SetDefault("providers.azure_openai.max_retries", 3)
+	v.SetDefault("providers.azure_openai.enabled", false)
+
+	// Bedrock is opt-in: it needs AWS credentials with model access
+	v.SetDefault("providers.bedrock.timeout", 120*time.Second)
+	v.SetDefault("providers.bedrock.max_retries", 3)
+	v.SetDefault("providers.bedrock.enabled", false)
+
+	// Model catalog
+	v.SetDefault("catalog.url", catalog.DefaultURL)
+	v.SetDefault("catalog.max_age", 0)
+
+	// Cache
+	v.SetDefault("cache.max_age", 30*24*time.Hour)
+
+	// Plugins are opt-in: discovery runs every plugin's describe
+	v.SetDefault("plugins.enabled", false)
+	v.SetDefault("plugins.directory", DefaultPluginDir())
+
+	// Privacy
+	v.SetDefault("privacy.store_prompts", true)
+
+	// Image descriptions
+	v.SetDefault("vision.model", "openai/gpt-4o-mini")
+	v.SetDefault("alt_text.enabled", false)
+	v.SetDefault("alt_text.txt", false)
+	v.SetDefault("auto_tag.enabled", false)
+	v.SetDefault("auto_tag.max_tags", 10)
+	v.SetDefault("ocr.enabled", false)
+	v.SetDefault("ocr.retries", 0)
+	v.SetDefault("constraints.on_violation", "warn")
+	v.SetDefault("constraints.retries", 2)
+
 	// Output
 	v.SetDefault("output.directory", "./")
 	v.SetDefault("output.format", "png")
+	v.SetDefault("output.layout", "flat")
+	v.SetDefault("output.on_conflict", "overwrite")
+	v.SetDefault("output.runs", false)
+	v.SetDefault("output.retention", 0)
+	v.SetDefault("output.metadata", false)
+	v.SetDefault("output.palette", 0)
 }
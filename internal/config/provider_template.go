@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// ProviderTemplateConfig is a provider declared in a YAML template file:
+// the request to send and where to find the images in the response
+type ProviderTemplateConfig struct {
+	Name       string                 `mapstructure:"name"`
+	APIKey     string                 `mapstructure:"api_key"` // May reference $VAR
+	Models     []string               `mapstructure:"models"`
+	Params     []string               `mapstructure:"params"` // Keys accepted by --param
+	MaxRetries int                    `mapstructure:"max_retries"`
+	Request    TemplateRequestConfig  `mapstructure:"request"`
+	Response   TemplateResponseConfig `mapstructure:"response"`
+}
+
+// TemplateRequestConfig describes the HTTP request of a template provider.
+// URL, header values and body are Go templates.
+type TemplateRequestConfig struct {
+	Method  string            `mapstructure:"method"` // Default POST
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	Body    string            `mapstructure:"body"` // JSON body
+}
+
+// TemplateResponseConfig locates the images in a template provider
+// response with JSONPath expressions
+type TemplateResponseConfig struct {
+	Images string `mapstructure:"images"` // Image URLs, data URLs or base64
+	Error  string `mapstructure:"error"`  // Error message of failed requests
+	Format string `mapstructure:"format"` // Format of base64 images, detected if empty
+}
+
+// LoadProviderTemplates reads the provider template files matching the
+// given paths or glob patterns; a leading ~/ is the home directory
+func LoadProviderTemplates(patterns []string) ([]ProviderTemplateConfig, error) {
+	var templates []ProviderTemplateConfig
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(ExpandHome(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid provider_templates pattern %q: %w", pattern, err)
+		}
+		if len(paths) == 0 && !hasGlobMeta(pattern) {
+			return nil, fmt.Errorf("provider template %s not found", pattern)
+		}
+		for _, path := range paths {
+			t, err := loadProviderTemplate(path)
+			if err != nil {
+				return nil, fmt.Errorf("provider template %s: %w", path, err)
+			}
+			templates = append(templates, *t)
+		}
+	}
+	return templates, nil
+}
+
+func loadProviderTemplate(path string) (*ProviderTemplateConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var t ProviderTemplateConfig
+	if err := v.Unmarshal(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// hasGlobMeta reports whether a path contains glob metacharacters
+func hasGlobMeta(path string) bool {
+	for _, c := range path {
+		switch c {
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
@@ -2,14 +2,67 @@ package generator
 
 // Request represents an image generation request
 type Request struct {
-	Model          string `json:"model"`
-	Prompt         string `json:"prompt"`
-	Size           string `json:"size,omitempty"`
-	Quality        string `json:"quality,omitempty"`
-	Style          string `json:"style,omitempty"`
-	Count          int    `json:"count,omitempty"`
-	Seed           *int64 `json:"seed,omitempty"`
-	NegativePrompt string `json:"negative_prompt,omitempty"`
-	AspectRatio    string `json:"aspect_ratio,omitempty"`
-	Steps          int    `json:"steps,omitempty"`
+	Model           string            `json:"model"`
+	Prompt          string            `json:"prompt"`
+	Size            string            `json:"size,omitempty"`
+	Quality         string            `json:"quality,omitempty"`
+	Style           string            `json:"style,omitempty"`
+	Count           int               `json:"count,omitempty"`
+	Seed            *int64            `json:"seed,omitempty"`
+	NegativePrompt  string            `json:"negative_prompt,omitempty"`
+	AspectRatio     string            `json:"aspect_ratio,omitempty"`
+	Steps           int               `json:"steps,omitempty"`
+	Tile            bool              `json:"tile,omitempty"`             // Seamless tiling texture
+	StyleType       string            `json:"style_type,omitempty"`       // Ideogram style type
+	MagicPrompt     string            `json:"magic_prompt,omitempty"`     // Prompt expansion: auto/on/off
+	SafetyTolerance *int              `json:"safety_tolerance,omitempty"` // Moderation level (BFL: 0 strict to 6 permissive)
+	ImageRefs       []string          `json:"image_refs,omitempty"`       // Reference image URLs guiding composition
+	Params          map[string]string `json:"params,omitempty"`           // Provider-specific options, e.g. sampler
+	ParamValues     map[string]any    `json:"param_values,omitempty"`     // Params typed: int64, float64, json.Number, bool, string or decoded JSON
+	URLOnly         bool              `json:"url_only,omitempty"`         // Keep hosted images at their URLs instead of downloading
+	InputImages     []InputImage      `json:"input_images,omitempty"`     // Images to edit (edit command)
+	Mask            *InputImage       `json:"mask,omitempty"`             // Areas of the first input image to edit
+	InitImage       *InputImage       `json:"init_image,omitempty"`       // Starting image for image-to-image generation
+	Strength        *float64          `json:"strength,omitempty"`         // How much the init image changes, 0 to 1
+	Outpaint        *Outpaint         `json:"outpaint,omitempty"`         // How far to extend the input image (outpaint command)
+	SearchPrompt    string            `json:"search_prompt,omitempty"`    // Object of the input image to replace with the prompt (edit --search)
+	Fidelity        string            `json:"fidelity,omitempty"`         // How closely an edit keeps details of the input images: low or high
+	SelectPrompt    string            `json:"select_prompt,omitempty"`    // Object of the input image to recolor as the prompt says (recolor command)
+	Control         *Control          `json:"control,omitempty"`          // Image guiding the composition or style
+	StyleImage      *InputImage       `json:"style_image,omitempty"`      // Reference image whose style the result takes
+	VideoDuration   int               `json:"video_duration,omitempty"`   // Video length in seconds (video command)
+	FPS             int               `json:"fps,omitempty"`              // Video frames per second (video command)
+}
+
+// Outpaint is how far to extend an image beyond each border, in pixels
+type Outpaint struct {
+	Left       int      `json:"left,omitempty"`
+	Right      int      `json:"right,omitempty"`
+	Up         int      `json:"up,omitempty"`
+	Down       int      `json:"down,omitempty"`
+	Creativity *float64 `json:"creativity,omitempty"` // How freely new content is invented, 0 to 1
+}
+
+// Control modes: what of the control image a generation follows
+const (
+	ControlStructure = "structure" // The shapes and depth of a photo or depth map
+	ControlSketch    = "sketch"    // The lines of a drawing
+	ControlStyle     = "style"     // The style, not the composition
+)
+
+// ControlModes are the supported control modes
+var ControlModes = []string{ControlStructure, ControlSketch, ControlStyle}
+
+// Control is an image guiding a generation
+type Control struct {
+	Image    InputImage `json:"image"`
+	Mode     string     `json:"mode"`
+	Strength *float64   `json:"strength,omitempty"` // How closely the image is followed, 0 to 1
+}
+
+// InputImage is an image file attached to a request
+type InputImage struct {
+	Name     string `json:"name"`      // File name, sent with multipart uploads
+	MIMEType string `json:"mime_type"` // e.g. image/png
+	Data     []byte `json:"-"`
 }
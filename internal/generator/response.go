@@ -1,13 +1,18 @@
 package generator
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Response represents the result of image generation
 type Response struct {
 	Images        []Image       `json:"images"`
+	Videos        []Video       `json:"videos,omitempty"`
 	Model         string        `json:"model"`
 	Provider      string        `json:"provider"`
 	RevisedPrompt string        `json:"revised_prompt,omitempty"`
+	Kudos         float64       `json:"kudos,omitempty"` // Stable Horde kudos consumed
 	GeneratedAt   time.Time     `json:"generated_at"`
 	Duration      time.Duration `json:"duration"`
 }
@@ -21,4 +26,27 @@ type Image struct {
 	Height int    `json:"height,omitempty"`
 	Seed   *int64 `json:"seed,omitempty"`
 	Index  int    `json:"index"`
+
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // When a hosted URL stops working, if known
+	AltText   string     `json:"alt_text,omitempty"`   // Accessibility description from a vision model
+	Tags      []string   `json:"tags,omitempty"`       // Search keywords from a vision model
+
+	RenderedText string `json:"rendered_text,omitempty"` // Text read from the image by a vision model
+	TextMismatch bool   `json:"text_mismatch,omitempty"` // The rendered text misses the expected text
+}
+
+// Video represents a generated video. The embedded Image holds the file:
+// its data or hosted URL, a format of VideoFormats, and its size.
+type Video struct {
+	Image
+	Seconds float64 `json:"seconds,omitempty"` // Length, if known
+	FPS     int     `json:"fps,omitempty"`     // Frames per second, if known
+}
+
+// VideoFormats are the formats of video files
+var VideoFormats = []string{"mp4", "webm"}
+
+// IsVideo reports whether the image is a video file
+func (img Image) IsVideo() bool {
+	return slices.Contains(VideoFormats, img.Format)
 }
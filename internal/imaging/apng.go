@@ -0,0 +1,119 @@
+package imaging
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"io"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// EncodeAPNG encodes frames as a looping animated PNG. Frames are
+// written as 8-bit RGBA on a canvas fitting the largest frame.
+func EncodeAPNG(frames []image.Image, delay time.Duration) (generator.Image, error) {
+	if len(frames) == 0 {
+		return generator.Image{}, fmt.Errorf("no frames to encode")
+	}
+
+	cellW, cellH := cellSize(frames)
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(cellW))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(cellH))
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // color type: RGBA
+	writeChunk(&buf, "IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	writeChunk(&buf, "acTL", actl)
+
+	// Delay is stored as a fraction of a second in milliseconds
+	delayMs := uint16(min(delay.Milliseconds(), 65535))
+
+	var seq uint32
+	for i, f := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(cellW))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(cellH))
+		binary.BigEndian.PutUint16(fctl[20:], delayMs)
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		fctl[24] = 0 // dispose: none
+		fctl[25] = 0 // blend: source
+		writeChunk(&buf, "fcTL", fctl)
+		seq++
+
+		data, err := compressFrame(f, cellW, cellH)
+		if err != nil {
+			return generator.Image{}, err
+		}
+
+		if i == 0 {
+			writeChunk(&buf, "IDAT", data)
+			continue
+		}
+
+		fdat := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		copy(fdat[4:], data)
+		writeChunk(&buf, "fdAT", fdat)
+		seq++
+	}
+
+	writeChunk(&buf, "IEND", nil)
+
+	return generator.Image{
+		Data:   buf.Bytes(),
+		Format: "png",
+		Width:  cellW,
+		Height: cellH,
+	}, nil
+}
+
+// compressFrame returns zlib-compressed, unfiltered RGBA scanlines of
+// the frame drawn on a width x height canvas
+func compressFrame(f image.Image, width, height int) ([]byte, error) {
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, f.Bounds().Sub(f.Bounds().Min), f, f.Bounds().Min, draw.Src)
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	for y := range height {
+		row := canvas.Pix[y*canvas.Stride : y*canvas.Stride+width*4]
+		zw.Write([]byte{0}) // filter: none
+		zw.Write(row)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress frame: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeChunk writes a PNG chunk with its length and CRC
+func writeChunk(w io.Writer, name string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	w.Write(header[:])
+	w.Write(data)
+	binary.Write(w, binary.BigEndian, crc.Sum32())
+}
@@ -0,0 +1,179 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	_ "golang.org/x/image/webp"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// Decode decodes the data of a generated image
+func Decode(img generator.Image) (image.Image, error) {
+	decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image: %w", img.Format, err)
+	}
+	return decoded, nil
+}
+
+// DecodeAll decodes the data of several generated images
+func DecodeAll(images []generator.Image) ([]image.Image, error) {
+	decoded := make([]image.Image, 0, len(images))
+	for _, img := range images {
+		d, err := Decode(img)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, d)
+	}
+	return decoded, nil
+}
+
+// EncodePNG encodes an image as a generator.Image in PNG format
+func EncodePNG(img image.Image) (generator.Image, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return generator.Image{}, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	b := img.Bounds()
+	return generator.Image{
+		Data:   buf.Bytes(),
+		Format: "png",
+		Width:  b.Dx(),
+		Height: b.Dy(),
+	}, nil
+}
+
+// cellSize returns the largest width and height among frames
+func cellSize(frames []image.Image) (int, int) {
+	var w, h int
+	for _, f := range frames {
+		w = max(w, f.Bounds().Dx())
+		h = max(h, f.Bounds().Dy())
+	}
+	return w, h
+}
+
+// SpriteSheet lays out frames on a grid, left to right and top to bottom.
+// If columns is 0, a near-square grid is used.
+func SpriteSheet(frames []image.Image, columns int) image.Image {
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(frames)))))
+	}
+	rows := (len(frames) + columns - 1) / columns
+
+	cellW, cellH := cellSize(frames)
+	sheet := image.NewRGBA(image.Rect(0, 0, cellW*columns, cellH*rows))
+
+	for i, f := range frames {
+		origin := image.Pt((i%columns)*cellW, (i/columns)*cellH)
+		draw.Draw(sheet, f.Bounds().Sub(f.Bounds().Min).Add(origin), f, f.Bounds().Min, draw.Src)
+	}
+
+	return sheet
+}
+
+// EncodeGIF encodes frames as a looping animated GIF
+func EncodeGIF(frames []image.Image, delay time.Duration) (generator.Image, error) {
+	cellW, cellH := cellSize(frames)
+	bounds := image.Rect(0, 0, cellW, cellH)
+
+	anim := &gif.GIF{}
+	for _, f := range frames {
+		paletted := image.NewPaletted(bounds, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, f.Bounds().Sub(f.Bounds().Min), f, f.Bounds().Min)
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, int(delay/(10*time.Millisecond)))
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return generator.Image{}, fmt.Errorf("failed to encode GIF: %w", err)
+	}
+
+	return generator.Image{
+		Data:   buf.Bytes(),
+		Format: "gif",
+		Width:  cellW,
+		Height: cellH,
+	}, nil
+}
+
+// Upscale enlarges an image by an integer factor using Catmull-Rom
+// resampling
+func Upscale(img image.Image, factor int) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Src, nil)
+	return dst
+}
+
+// CropAspect returns the largest centered crop of an image with the
+// aspect ratio width:height
+func CropAspect(img image.Image, width, height int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w*height > h*width {
+		w = h * width / height
+	} else {
+		h = w * height / width
+	}
+	w, h = max(w, 1), max(h, 1)
+	origin := image.Pt(b.Min.X+(b.Dx()-w)/2, b.Min.Y+(b.Dy()-h)/2)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), img, origin, draw.Src)
+	return dst
+}
+
+// upscaleTile is the side of the output tiles UpscaleTo resamples
+// concurrently
+const upscaleTile = 512
+
+// UpscaleTo enlarges an image to width x height with Catmull-Rom
+// resampling, scaling it to cover the size and cropping the overflow
+// evenly. The output is resampled in tiles, one goroutine per CPU; every
+// tile samples the whole source, so there are no seams.
+func UpscaleTo(img image.Image, width, height int) image.Image {
+	b := img.Bounds()
+	scale := max(float64(width)/float64(b.Dx()), float64(height)/float64(b.Dy()))
+	offsetX := (float64(b.Dx())*scale - float64(width)) / 2
+	offsetY := (float64(b.Dy())*scale - float64(height)) / 2
+	s2d := f64.Aff3{
+		scale, 0, -float64(b.Min.X)*scale - offsetX,
+		0, scale, -float64(b.Min.Y)*scale - offsetY,
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for y := 0; y < height; y += upscaleTile {
+		for x := 0; x < width; x += upscaleTile {
+			tile := image.Rect(x, y, min(x+upscaleTile, width), min(y+upscaleTile, height))
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				xdraw.CatmullRom.Transform(dst.SubImage(tile).(*image.RGBA), s2d, img, b, xdraw.Src, nil)
+				<-sem
+			}()
+		}
+	}
+	wg.Wait()
+	return dst
+}
@@ -0,0 +1,40 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Label returns a copy of img with text drawn in its bottom-left corner
+// on a dark background strip
+func Label(img image.Image, text string) image.Image {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+
+	face := basicfont.Face7x13
+	const padding = 4
+	textW := font.MeasureString(face, text).Ceil()
+	lineH := face.Metrics().Height.Ceil()
+
+	strip := image.Rect(0, b.Dy()-lineH-2*padding, textW+2*padding, b.Dy())
+	draw.Draw(out, strip, image.NewUniform(color.RGBA{A: 180}), image.Point{}, draw.Over)
+
+	d := &font.Drawer{
+		Dst:  out,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.I(padding),
+			Y: fixed.I(b.Dy() - padding - face.Metrics().Descent.Ceil()),
+		},
+	}
+	d.DrawString(text)
+
+	return out
+}
@@ -0,0 +1,46 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// maskThreshold is the luminance from which a mask pixel marks the area
+// to edit
+const maskThreshold = 0x8000
+
+// EditMask turns a segmentation mask, light where the object is, into an
+// edit mask of width x height. With transparent the object becomes
+// transparent on opaque black, as OpenAI expects; otherwise it becomes
+// white on black, as Stability AI expects. The mask is stretched to the
+// size and its soft edges are cut at half luminance.
+func EditMask(mask image.Image, width, height int, transparent bool) image.Image {
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), mask, mask.Bounds(), xdraw.Src, nil)
+
+	var dst draw.Image = image.NewGray(scaled.Bounds())
+	if transparent {
+		dst = image.NewNRGBA(scaled.Bounds())
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Premultiplied, so transparent pixels count as black
+			r, g, b, _ := scaled.At(x, y).RGBA()
+			selected := (299*r+587*g+114*b)/1000 >= maskThreshold
+			switch {
+			case transparent && selected:
+				dst.Set(x, y, color.NRGBA{})
+			case transparent:
+				dst.Set(x, y, color.NRGBA{A: 0xff})
+			case selected:
+				dst.Set(x, y, color.White)
+			default:
+				dst.Set(x, y, color.Black)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// paletteSamples caps the pixels Palette looks at; larger images are
+// sampled on an even grid
+const paletteSamples = 128 * 128
+
+// PaletteColor is one of the dominant colors of an image
+type PaletteColor struct {
+	Hex   string  `json:"hex"`   // #rrggbb
+	Share float64 `json:"share"` // Fraction of the opaque pixels, 0..1
+}
+
+// Palette returns up to n dominant colors of an image, most common first.
+// Colors are found by median cut over the opaque pixels: the pixels are
+// split at the median of their widest channel until there are n groups,
+// and each group is reported as its average color.
+func Palette(img image.Image, n int) []PaletteColor {
+	pixels := samplePixels(img)
+	if n <= 0 || len(pixels) == 0 {
+		return nil
+	}
+
+	boxes := [][][3]uint8{pixels}
+	for len(boxes) < n {
+		// Split the box with the widest channel range, larger boxes first
+		best, bestRange := -1, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			if _, r := widestChannel(box); r > bestRange || (r == bestRange && best >= 0 && len(box) > len(boxes[best])) {
+				best, bestRange = i, r
+			}
+		}
+		if best < 0 || bestRange == 0 {
+			break
+		}
+
+		box := boxes[best]
+		ch, _ := widestChannel(box)
+		slices.SortFunc(box, func(a, b [3]uint8) int { return cmp.Compare(a[ch], b[ch]) })
+		mid := len(box) / 2
+		boxes[best] = box[:mid]
+		boxes = append(boxes, box[mid:])
+	}
+
+	// Boxes can average to the same color, which is then reported once
+	counts := make(map[string]int, len(boxes))
+	for _, box := range boxes {
+		counts[averageHex(box)] += len(box)
+	}
+	palette := make([]PaletteColor, 0, len(counts))
+	for hex, count := range counts {
+		share := float64(count) / float64(len(pixels))
+		palette = append(palette, PaletteColor{Hex: hex, Share: math.Round(share*1000) / 1000})
+	}
+	slices.SortFunc(palette, func(a, b PaletteColor) int {
+		if c := cmp.Compare(b.Share, a.Share); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Hex, b.Hex)
+	})
+	return palette
+}
+
+// samplePixels returns the RGB values of the opaque pixels of an image,
+// at most about paletteSamples of them
+func samplePixels(img image.Image) [][3]uint8 {
+	b := img.Bounds()
+	if b.Empty() {
+		return nil
+	}
+	step := max(int(math.Ceil(math.Sqrt(float64(b.Dx()*b.Dy())/paletteSamples))), 1)
+
+	pixels := make([][3]uint8, 0, min(b.Dx()*b.Dy(), 2*paletteSamples))
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				continue
+			}
+			pixels = append(pixels, [3]uint8{c.R, c.G, c.B})
+		}
+	}
+	return pixels
+}
+
+// widestChannel returns the RGB channel with the largest value range
+// among pixels, and the range
+func widestChannel(pixels [][3]uint8) (int, int) {
+	lo := pixels[0]
+	hi := pixels[0]
+	for _, p := range pixels[1:] {
+		for ch := range 3 {
+			lo[ch] = min(lo[ch], p[ch])
+			hi[ch] = max(hi[ch], p[ch])
+		}
+	}
+	best, bestRange := 0, -1
+	for ch := range 3 {
+		if r := int(hi[ch]) - int(lo[ch]); r > bestRange {
+			best, bestRange = ch, r
+		}
+	}
+	return best, bestRange
+}
+
+// averageHex returns the average color of pixels as #rrggbb
+func averageHex(pixels [][3]uint8) string {
+	var sum [3]int
+	for _, p := range pixels {
+		for ch := range 3 {
+			sum[ch] += int(p[ch])
+		}
+	}
+	n := len(pixels)
+	return fmt.Sprintf("#%02x%02x%02x", (sum[0]+n/2)/n, (sum[1]+n/2)/n, (sum[2]+n/2)/n)
+}
@@ -0,0 +1,49 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+)
+
+// SeamThreshold is the edge mismatch above which an image is not
+// considered seamlessly tileable
+const SeamThreshold = 0.06
+
+// SeamMismatch measures how well an image tiles by comparing opposite
+// edges. It returns the mean absolute channel difference between the
+// left and right columns and the top and bottom rows, from 0 (identical)
+// to 1.
+func SeamMismatch(img image.Image) float64 {
+	b := img.Bounds()
+	if b.Dx() < 2 || b.Dy() < 2 {
+		return 0
+	}
+
+	var sum float64
+	var n int
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		sum += colorDistance(img.At(b.Min.X, y), img.At(b.Max.X-1, y))
+		n++
+	}
+	for x := b.Min.X; x < b.Max.X; x++ {
+		sum += colorDistance(img.At(x, b.Min.Y), img.At(x, b.Max.Y-1))
+		n++
+	}
+	return sum / float64(n)
+}
+
+// colorDistance returns the mean absolute RGB difference of two colors,
+// normalized to 0..1
+func colorDistance(a, b color.Color) float64 {
+	r1, g1, b1, _ := a.RGBA()
+	r2, g2, b2, _ := b.RGBA()
+	diff := absDiff(r1, r2) + absDiff(g1, g2) + absDiff(b1, b2)
+	return float64(diff) / (3 * 0xffff)
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
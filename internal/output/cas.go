@@ -0,0 +1,250 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// casIndexFile is the index of a content-addressed store, one JSON line
+// per saved image
+const casIndexFile = "index.jsonl"
+
+// Prefixes of the temporary files of store objects and preflight checks
+const (
+	casTempPrefix       = ".tmp-"
+	preflightTempPrefix = ".llm-imager-preflight-"
+)
+
+// CASEntry is a line of the content-addressed store index, mapping the
+// requested output name to the stored object
+type CASEntry struct {
+	Name   string    `json:"name"`
+	Object string    `json:"object"`
+	SHA256 string    `json:"sha256"`
+	Bytes  int       `json:"bytes"`
+	Time   time.Time `json:"time"`
+}
+
+// writeCAS stores images as <root>/<ab>/<cdef...>.<ext> by the SHA256 of
+// their data. Identical images share one object; every save is appended
+// to the index under its requested name.
+func (w *Writer) writeCAS(images []generator.Image, outputPath string) ([]string, error) {
+	if err := os.MkdirAll(w.casRoot, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", w.casRoot, err)
+	}
+	index, err := os.OpenFile(filepath.Join(w.casRoot, casIndexFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store index: %w", err)
+	}
+	defer index.Close()
+
+	savedPaths := make([]string, 0, len(images))
+	for i, img := range images {
+		name := w.generatePath(outputPath, i, len(images), img.Format)
+
+		sum := sha256.Sum256(img.Data)
+		digest := hex.EncodeToString(sum[:])
+		object := filepath.Join(digest[:2], digest[2:]+filepath.Ext(name))
+		path := filepath.Join(w.casRoot, object)
+
+		if err := writeObject(path, img.Data); err != nil {
+			return nil, fmt.Errorf("failed to write image %s: %w", path, err)
+		}
+		if err := w.writeSidecar(img, path); err != nil {
+			return nil, err
+		}
+
+		line, err := json.Marshal(CASEntry{
+			Name:   name,
+			Object: filepath.ToSlash(object),
+			SHA256: digest,
+			Bytes:  len(img.Data),
+			Time:   time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := index.Write(append(line, '\n')); err != nil {
+			return nil, fmt.Errorf("failed to update store index: %w", err)
+		}
+
+		savedPaths = append(savedPaths, path)
+	}
+
+	return savedPaths, nil
+}
+
+// writeObject writes a store object unless it already exists. Objects are
+// renamed into place so a partial write never shows up under its hash.
+func writeObject(path string, data []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, casTempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CASObjects returns the objects of the content-addressed store at root,
+// as paths relative to root, with the time each was last saved. A store
+// without an index has no objects.
+func CASObjects(root string) (map[string]time.Time, error) {
+	f, err := os.Open(filepath.Join(root, casIndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store index: %w", err)
+	}
+	defer f.Close()
+
+	objects := make(map[string]time.Time)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry CASEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.Object == "" {
+			continue
+		}
+		if entry.Time.After(objects[entry.Object]) {
+			objects[entry.Object] = entry.Time
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read store index: %w", err)
+	}
+	return objects, nil
+}
+
+// DropCASObjects rewrites the index of the store at root without the
+// entries of the given objects. Lines it cannot parse are kept.
+func DropCASObjects(root string, objects map[string]bool) error {
+	path := filepath.Join(root, casIndexFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read store index: %w", err)
+	}
+
+	var kept bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		var entry CASEntry
+		if json.Unmarshal(line, &entry) == nil && objects[entry.Object] {
+			continue
+		}
+		kept.Write(line)
+	}
+	if err := writeIndex(path, kept.Bytes()); err != nil {
+		return fmt.Errorf("failed to update store index: %w", err)
+	}
+	return nil
+}
+
+// writeIndex replaces the index at path, renaming the new one into place
+// so a concurrent reader never sees it half written
+func writeIndex(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), casTempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CASTempFiles returns the partial objects left in the content-addressed
+// store at root by interrupted writes and last modified before cutoff
+func CASTempFiles(root string, cutoff time.Time) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		// Objects are stored in <ab> directories
+		if !entry.IsDir() || len(entry.Name()) != 2 {
+			continue
+		}
+		found, err := tempFiles(filepath.Join(root, entry.Name()), casTempPrefix, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// PreflightTempFiles returns the probes left in dir by interrupted output
+// checks and last modified before cutoff
+func PreflightTempFiles(dir string, cutoff time.Time) ([]string, error) {
+	return tempFiles(dir, preflightTempPrefix, cutoff)
+}
+
+// tempFiles returns the files of dir named with prefix and last modified
+// before cutoff
+func tempFiles(dir, prefix string, cutoff time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
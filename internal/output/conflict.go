@@ -0,0 +1,135 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictPolicy decides what happens when an output file already exists
+type ConflictPolicy string
+
+// Conflict policies
+const (
+	ConflictOverwrite ConflictPolicy = "overwrite" // Replace the existing file
+	ConflictError     ConflictPolicy = "error"     // Fail without writing
+	ConflictSuffix    ConflictPolicy = "suffix"    // Write to name-1.ext, name-2.ext, ...
+	ConflictSkip      ConflictPolicy = "skip"      // Keep the existing file
+)
+
+// maxSuffix bounds the search for a free suffixed name
+const maxSuffix = 10000
+
+// ParseConflictPolicy validates a policy name; empty means overwrite
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(strings.ToLower(s)); p {
+	case "":
+		return ConflictOverwrite, nil
+	case ConflictOverwrite, ConflictError, ConflictSuffix, ConflictSkip:
+		return p, nil
+	}
+	return "", fmt.Errorf("unsupported on_conflict %q, supported: error, overwrite, suffix, skip", s)
+}
+
+// ExistsError is returned when an output file exists under the error
+// policy
+type ExistsError struct {
+	Path string
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("output file %s already exists (on_conflict: error)", e.Path)
+}
+
+// Target resolves the path a file should be written to under the conflict
+// policy. skip is true when the existing file must be kept.
+func (w *Writer) Target(path string) (target string, skip bool, err error) {
+	exists, err := fileExists(path)
+	if err != nil || !exists {
+		return path, false, err
+	}
+
+	switch w.onConflict {
+	case ConflictError:
+		return "", false, &ExistsError{Path: path}
+	case ConflictSkip:
+		w.skipped = append(w.skipped, path)
+		return path, true, nil
+	case ConflictSuffix:
+		ext := filepath.Ext(path)
+		base := strings.TrimSuffix(path, ext)
+		for n := 1; n <= maxSuffix; n++ {
+			candidate := fmt.Sprintf("%s-%d%s", base, n, ext)
+			exists, err := fileExists(candidate)
+			if err != nil {
+				return "", false, err
+			}
+			if !exists {
+				return candidate, false, nil
+			}
+		}
+		return "", false, fmt.Errorf("no free name for %s after %d suffixes", path, maxSuffix)
+	}
+	return path, false, nil
+}
+
+// Check fails early, before any generation, when the error policy is set
+// and one of the count images planned for outputPath already exists
+func (w *Writer) Check(outputPath string, count int) error {
+	if w.onConflict != ConflictError || w.casRoot != "" {
+		return nil
+	}
+	for _, path := range w.plannedPaths(outputPath, count) {
+		if exists, err := fileExists(path); err != nil {
+			return err
+		} else if exists {
+			return &ExistsError{Path: path}
+		}
+	}
+	return nil
+}
+
+// Existing returns the planned paths of count images for outputPath when
+// all of them already exist, so a generation under the skip policy can be
+// avoided; otherwise it returns nil
+func (w *Writer) Existing(outputPath string, count int) []string {
+	if w.onConflict != ConflictSkip || w.casRoot != "" {
+		return nil
+	}
+	paths := w.plannedPaths(outputPath, count)
+	for _, path := range paths {
+		if exists, _ := fileExists(path); !exists {
+			return nil
+		}
+	}
+	return paths
+}
+
+// plannedPaths returns the paths Write uses for count images, assuming
+// they come in the default format
+func (w *Writer) plannedPaths(outputPath string, count int) []string {
+	count = max(count, 1)
+	paths := make([]string, count)
+	for i := range paths {
+		paths[i] = w.generatePath(outputPath, i, count, "")
+	}
+	return paths
+}
+
+// Skipped returns the existing files kept under the skip policy
+func (w *Writer) Skipped() []string {
+	return w.skipped
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
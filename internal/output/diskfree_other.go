@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd
+
+package output
+
+// freeSpace is not implemented on this platform; the check is skipped
+func freeSpace(path string) (uint64, bool) {
+	return 0, false
+}
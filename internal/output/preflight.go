@@ -0,0 +1,65 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// estimatedImageSize is a conservative upper bound for one generated image
+const estimatedImageSize = 16 << 20
+
+// Preflight verifies that count images can be written to outputPath.
+// It is meant to run before any paid API call, so it does not create
+// missing directories; it checks the nearest existing parent instead.
+func Preflight(outputPath string, count int) error {
+	if info, err := os.Stat(outputPath); err == nil && info.IsDir() {
+		return fmt.Errorf("output path %s is a directory, expected a file name", outputPath)
+	}
+
+	dir, err := existingParent(filepath.Dir(outputPath))
+	if err != nil {
+		return err
+	}
+
+	probe, err := os.CreateTemp(dir, preflightTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	if count <= 0 {
+		count = 1
+	}
+	required := uint64(count) * estimatedImageSize
+	if free, ok := freeSpace(dir); ok && free < required {
+		return fmt.Errorf("not enough disk space in %s: %d MiB free, need about %d MiB",
+			dir, free>>20, required>>20)
+	}
+
+	return nil
+}
+
+// existingParent walks up from dir to the first path that exists
+func existingParent(dir string) (string, error) {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("output path parent %s is not a directory", dir)
+			}
+			return dir, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to check output directory %s: %w", dir, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no existing parent directory for %s", dir)
+		}
+		dir = parent
+	}
+}
@@ -1,35 +1,79 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/piligrim/llm-imager/internal/generator"
 )
 
+// fixedExtFormats are the formats always saved under their own extension
+var fixedExtFormats = append([]string{"svg", "gif"}, generator.VideoFormats...)
+
 // Writer handles saving images to disk
 type Writer struct {
 	defaultFormat string
+	casRoot       string // Content-addressed store, empty for the flat layout
+	onConflict    ConflictPolicy
+	sidecar       func(img generator.Image, path string) (any, error)
+	skipped       []string
+}
+
+// WriterOption configures a Writer
+type WriterOption func(*Writer)
+
+// WithContentAddressed stores images under root by their SHA256 instead
+// of at the output path, which is recorded in the store index
+func WithContentAddressed(root string) WriterOption {
+	return func(w *Writer) {
+		w.casRoot = root
+	}
+}
+
+// WithConflictPolicy sets what happens when an output file already exists;
+// the default is to overwrite it
+func WithConflictPolicy(policy ConflictPolicy) WriterOption {
+	return func(w *Writer) {
+		w.onConflict = policy
+	}
+}
+
+// WithSidecar saves the JSON encoding of fn(image, path) next to every
+// saved image as <image path>.json
+func WithSidecar(fn func(img generator.Image, path string) (any, error)) WriterOption {
+	return func(w *Writer) {
+		w.sidecar = fn
+	}
 }
 
 // NewWriter creates a new output writer
-func NewWriter(defaultFormat string) *Writer {
+func NewWriter(defaultFormat string, opts ...WriterOption) *Writer {
 	if defaultFormat == "" {
 		defaultFormat = "png"
 	}
-	return &Writer{
+	w := &Writer{
 		defaultFormat: defaultFormat,
+		onConflict:    ConflictOverwrite,
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
+	return w
 }
 
-// Write saves images to the specified path
-// Returns the list of saved file paths
+// Write saves images to the specified path, applying the conflict policy
+// Returns the list of saved file paths, without skipped files
 func (w *Writer) Write(images []generator.Image, outputPath string) ([]string, error) {
 	if len(images) == 0 {
 		return nil, fmt.Errorf("no images to save")
 	}
+	if w.casRoot != "" {
+		return w.writeCAS(images, outputPath)
+	}
 
 	// Ensure parent directory exists
 	dir := filepath.Dir(outputPath)
@@ -42,11 +86,20 @@ func (w *Writer) Write(images []generator.Image, outputPath string) ([]string, e
 	savedPaths := make([]string, 0, len(images))
 
 	for i, img := range images {
-		path := w.generatePath(outputPath, i, len(images), img.Format)
+		path, skip, err := w.Target(w.generatePath(outputPath, i, len(images), img.Format))
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			continue
+		}
 
 		if err := os.WriteFile(path, img.Data, 0644); err != nil {
 			return nil, fmt.Errorf("failed to write image %s: %w", path, err)
 		}
+		if err := w.writeSidecar(img, path); err != nil {
+			return nil, err
+		}
 
 		savedPaths = append(savedPaths, path)
 	}
@@ -54,6 +107,25 @@ func (w *Writer) Write(images []generator.Image, outputPath string) ([]string, e
 	return savedPaths, nil
 }
 
+// writeSidecar saves the sidecar of an image saved at path, if enabled
+func (w *Writer) writeSidecar(img generator.Image, path string) error {
+	if w.sidecar == nil {
+		return nil
+	}
+	v, err := w.sidecar(img, path)
+	if err != nil {
+		return fmt.Errorf("metadata of %s: %w", path, err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".json", append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata %s.json: %w", path, err)
+	}
+	return nil
+}
+
 // generatePath generates the output path for an image
 func (w *Writer) generatePath(basePath string, index, total int, format string) string {
 	if format == "" {
@@ -68,6 +140,12 @@ func (w *Writer) generatePath(basePath string, index, total int, format string)
 		ext = "." + format
 	}
 
+	// Vector images, animations and videos cannot be stored under a
+	// raster extension
+	if slices.Contains(fixedExtFormats, format) && !strings.EqualFold(ext, "."+format) {
+		ext = "." + format
+	}
+
 	// If multiple images, add index
 	if total > 1 {
 		return fmt.Sprintf("%s_%d%s", base, index+1, ext)
@@ -0,0 +1,95 @@
+package probe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/provider"
+)
+
+// DefaultTTL is how long a probe result is trusted for validation
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Cache stores probe results on disk, keyed by model ID
+type Cache struct {
+	path    string
+	ttl     time.Duration
+	entries map[string]provider.ProbeResult
+}
+
+// DefaultCachePath returns the default probe cache file path
+func DefaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "llm-imager", "probe.json")
+}
+
+// Load reads the cache from path; a missing file yields an empty cache
+func Load(path string) (*Cache, error) {
+	c := &Cache{
+		path:    path,
+		ttl:     DefaultTTL,
+		entries: make(map[string]provider.ProbeResult),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read probe cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to decode probe cache %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Get returns a non-expired probe result for the model
+func (c *Cache) Get(model string) (*provider.ProbeResult, bool) {
+	result, ok := c.entries[model]
+	if !ok || time.Since(result.ProbedAt) > c.ttl {
+		return nil, false
+	}
+	return &result, true
+}
+
+// Put stores a probe result
+func (c *Cache) Put(result *provider.ProbeResult) {
+	c.entries[result.Model] = *result
+}
+
+// Prune removes results probed before cutoff and returns how many were
+// removed
+func (c *Cache) Prune(cutoff time.Time) int {
+	removed := 0
+	for model, result := range c.entries {
+		if result.ProbedAt.Before(cutoff) {
+			delete(c.entries, model)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Save writes the cache to disk
+func (c *Cache) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
@@ -0,0 +1,258 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// azureOpenAIAPIVersion supports both DALL-E 3 and gpt-image-1 deployments
+const azureOpenAIAPIVersion = "2025-04-01-preview"
+
+// AzureOpenAI implements the Provider interface for OpenAI image models
+// deployed on Azure OpenAI Service
+type AzureOpenAI struct {
+	apiKey      string
+	endpoint    string
+	apiVersion  string
+	deployments map[string]string // model name -> deployment name
+	httpClient  *httputil.Client
+}
+
+// NewAzureOpenAI creates a new Azure OpenAI provider
+func NewAzureOpenAI(cfg *ProviderConfig) *AzureOpenAI {
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = azureOpenAIAPIVersion
+	}
+
+	return &AzureOpenAI{
+		apiKey:      cfg.APIKey,
+		endpoint:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiVersion:  apiVersion,
+		deployments: cfg.Deployments,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+			httputil.WithRetryPolicy(openaiShouldRetry),
+		),
+	}
+}
+
+func (a *AzureOpenAI) Name() string {
+	return "azure-openai"
+}
+
+// SupportedModels returns the catalog models plus any configured
+// deployments that are not catalog model names
+func (a *AzureOpenAI) SupportedModels() []Model {
+	models := catalogModels(a.Name())
+	for name := range a.deployments {
+		id := QualifiedModelID(a.Name(), name)
+		if !containsModel(models, id) {
+			models = append(models, Model{ID: id, Name: name, Provider: a.Name()})
+		}
+	}
+	return models
+}
+
+func (a *AzureOpenAI) ValidateRequest(req *generator.Request) error {
+	if a.apiKey == "" {
+		return fmt.Errorf("Azure OpenAI API key is required (set AZURE_OPENAI_API_KEY)")
+	}
+	if a.endpoint == "" {
+		return fmt.Errorf("Azure OpenAI endpoint is required (set AZURE_OPENAI_ENDPOINT)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Azure OpenAI does not support seamless tiling")
+	}
+
+	model := a.extractModelName(req.Model)
+
+	if model == ModelDALLE3 && req.Size != "" {
+		validSizes := map[string]bool{
+			"1024x1024": true, "1792x1024": true, "1024x1792": true,
+		}
+		if !validSizes[req.Size] {
+			return fmt.Errorf("invalid size %s for DALL-E 3", req.Size)
+		}
+	}
+
+	if req.Style != "" && req.Style != "natural" && req.Style != "vivid" {
+		return fmt.Errorf("invalid style %s, valid: natural, vivid", req.Style)
+	}
+
+	return nil
+}
+
+func (a *AzureOpenAI) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := a.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	model := a.extractModelName(req.Model)
+
+	apiReq := openaiImageRequest{
+		Model:   model,
+		Prompt:  req.Prompt,
+		N:       count,
+		Size:    req.Size,
+		Quality: req.Quality,
+	}
+	// gpt-image-1 always returns base64 and rejects response_format and style
+	if model != ModelGPTImage1 {
+		apiReq.Style = req.Style
+		apiReq.ResponseFormat = "b64_json"
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		a.deploymentURL(model, "images/generations"),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("api-key", a.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyAzureOpenAIError(resp.StatusCode, respBody, a.deployment(model))
+	}
+
+	var apiResp openaiImageResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Data))
+	for i, img := range apiResp.Data {
+		var data []byte
+		if img.B64JSON != "" {
+			data, err = base64.StdEncoding.DecodeString(img.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image: %w", err)
+			}
+		}
+
+		images = append(images, generator.Image{
+			Data:   data,
+			URL:    img.URL,
+			Format: "png",
+			Index:  i,
+		})
+	}
+
+	revisedPrompt := ""
+	if len(apiResp.Data) > 0 {
+		revisedPrompt = apiResp.Data[0].RevisedPrompt
+	}
+
+	return &generator.Response{
+		Images:        images,
+		Model:         req.Model,
+		Provider:      a.Name(),
+		RevisedPrompt: revisedPrompt,
+		GeneratedAt:   time.Now(),
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+// deployment returns the deployment name for a model. Models without a
+// configured deployment are assumed to be deployed under their own name.
+func (a *AzureOpenAI) deployment(model string) string {
+	if d, ok := a.deployments[model]; ok && d != "" {
+		return d
+	}
+	return model
+}
+
+// deploymentURL builds the URL of a deployment operation
+func (a *AzureOpenAI) deploymentURL(model, operation string) string {
+	return fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s",
+		a.endpoint, url.PathEscape(a.deployment(model)), operation, url.QueryEscape(a.apiVersion))
+}
+
+func (a *AzureOpenAI) extractModelName(model string) string {
+	return strings.TrimPrefix(model, a.Name()+"/")
+}
+
+// classifyAzureOpenAIError maps an Azure OpenAI error response to an
+// APIError. Azure uses the OpenAI error format with its own codes for
+// missing deployments and content filtering.
+func classifyAzureOpenAIError(status int, body []byte, deployment string) *APIError {
+	apiErr := classifyOpenAIError(status, body)
+	apiErr.Provider = "Azure OpenAI"
+	apiErr.Hint = ""
+
+	switch apiErr.Code {
+	case "DeploymentNotFound":
+		apiErr.Kind = ErrKindNotFound
+		apiErr.Hint = fmt.Sprintf("deployment %q does not exist on this resource; "+
+			"map the model to your deployment under providers.azure_openai.deployments", deployment)
+	case "content_filter", "contentFilter":
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = "the prompt was blocked by the Azure content filter of this deployment"
+	}
+
+	if apiErr.Hint == "" {
+		switch apiErr.Kind {
+		case ErrKindAuth:
+			apiErr.Hint = "check AZURE_OPENAI_API_KEY and that the key belongs to the configured endpoint"
+		case ErrKindQuota, ErrKindRateLimit:
+			apiErr.Hint = "the deployment is over its tokens/requests per minute quota; " +
+				"wait and retry or raise the deployment quota in the Azure portal"
+		case ErrKindContentPolicy:
+			apiErr.Hint = "the prompt was blocked by the Azure content filter of this deployment"
+		}
+	}
+
+	return apiErr
+}
+
+func containsModel(models []Model, id string) bool {
+	for _, m := range models {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
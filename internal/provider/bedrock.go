@@ -0,0 +1,351 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/awsauth"
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const bedrockService = "bedrock"
+
+// Bedrock implements the Provider interface for image models hosted on
+// AWS Bedrock (Amazon Titan Image Generator and Stability AI models)
+type Bedrock struct {
+	region     string
+	profile    string
+	baseURL    string
+	httpClient *httputil.Client
+	middleware *httputil.Middleware // Applied before signing, not by the client
+
+	credsMu sync.Mutex
+	creds   *awsauth.Credentials
+}
+
+// NewBedrock creates a new Bedrock provider. Credentials are resolved on
+// first use from the environment or the configured AWS profile.
+func NewBedrock(cfg *ProviderConfig) *Bedrock {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" && cfg.Region != "" {
+		baseURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", cfg.Region)
+	}
+
+	return &Bedrock{
+		region:  cfg.Region,
+		profile: cfg.Profile,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+		),
+		middleware: cfg.Middleware,
+	}
+}
+
+func (b *Bedrock) Name() string {
+	return "bedrock"
+}
+
+func (b *Bedrock) SupportedModels() []Model {
+	return catalogModels(b.Name())
+}
+
+func (b *Bedrock) ValidateRequest(req *generator.Request) error {
+	if b.region == "" {
+		return fmt.Errorf("AWS region is required for Bedrock (set AWS_REGION or providers.bedrock.region)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Bedrock does not support seamless tiling")
+	}
+
+	model := b.extractModelName(req.Model)
+	switch {
+	case isTitanModel(model):
+		if req.Count > 5 {
+			return fmt.Errorf("Titan Image Generator returns at most 5 images per request")
+		}
+		if req.NegativePrompt != "" && len(req.NegativePrompt) < 3 {
+			return fmt.Errorf("Titan negative prompt must be at least 3 characters")
+		}
+	case strings.HasPrefix(model, "stability."):
+		if req.Count > 1 {
+			return fmt.Errorf("Stability models on Bedrock return one image per request")
+		}
+	default:
+		return fmt.Errorf("unsupported Bedrock model %s (supported: amazon.titan-image-*, stability.*)", model)
+	}
+
+	return nil
+}
+
+type titanRequest struct {
+	TaskType              string          `json:"taskType"`
+	TextToImageParams     titanTextParams `json:"textToImageParams"`
+	ImageGenerationConfig titanGenConfig  `json:"imageGenerationConfig"`
+}
+
+type titanTextParams struct {
+	Text         string `json:"text"`
+	NegativeText string `json:"negativeText,omitempty"`
+}
+
+type titanGenConfig struct {
+	NumberOfImages int     `json:"numberOfImages"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	Quality        string  `json:"quality,omitempty"`
+	CfgScale       float64 `json:"cfgScale,omitempty"`
+	Seed           *int64  `json:"seed,omitempty"`
+}
+
+type bedrockStabilityRequest struct {
+	Prompt         string `json:"prompt"`
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	AspectRatio    string `json:"aspect_ratio,omitempty"`
+	Seed           *int64 `json:"seed,omitempty"`
+	Mode           string `json:"mode,omitempty"`
+	OutputFormat   string `json:"output_format"`
+}
+
+// bedrockImageResponse covers both Titan ("images", "error") and
+// Stability ("images", "seeds", "finish_reasons") response bodies
+type bedrockImageResponse struct {
+	Images        []string  `json:"images"`
+	Seeds         []int64   `json:"seeds,omitempty"`
+	FinishReasons []*string `json:"finish_reasons,omitempty"`
+	Error         *string   `json:"error,omitempty"`
+}
+
+func (b *Bedrock) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := b.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	model := b.extractModelName(req.Model)
+
+	var apiReq any
+	if isTitanModel(model) {
+		apiReq = b.titanRequest(req)
+	} else {
+		stabilityReq := bedrockStabilityRequest{
+			Prompt:         req.Prompt,
+			NegativePrompt: req.NegativePrompt,
+			AspectRatio:    req.AspectRatio,
+			Seed:           req.Seed,
+			OutputFormat:   "png",
+		}
+		if strings.HasPrefix(model, "stability.sd3") {
+			stabilityReq.Mode = "text-to-image"
+		}
+		apiReq = stabilityReq
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respBody, err := b.invoke(ctx, model, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResp bedrockImageResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if apiResp.Error != nil && *apiResp.Error != "" {
+		return nil, &APIError{Provider: "Bedrock", Kind: ErrKindUnknown, Message: *apiResp.Error}
+	}
+	for _, reason := range apiResp.FinishReasons {
+		if reason != nil && *reason != "" {
+			return nil, &APIError{
+				Provider: "Bedrock",
+				Kind:     ErrKindContentPolicy,
+				Code:     *reason,
+				Message:  "image was filtered: " + *reason,
+				Hint:     "rephrase the prompt; Bedrock filtered the generated image",
+			}
+		}
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Images))
+	for i, b64 := range apiResp.Images {
+		data, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+		img := generator.Image{
+			Data:   data,
+			Format: "png",
+			Index:  i,
+		}
+		if i < len(apiResp.Seeds) {
+			seed := apiResp.Seeds[i]
+			img.Seed = &seed
+		}
+		images = append(images, img)
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    b.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// titanRequest builds a Titan Image Generator text-to-image request
+func (b *Bedrock) titanRequest(req *generator.Request) titanRequest {
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	config := titanGenConfig{
+		NumberOfImages: count,
+		Quality:        "standard",
+		CfgScale:       8.0,
+		Seed:           req.Seed,
+	}
+	if req.Quality == "hd" || req.Quality == "high" || req.Quality == "premium" {
+		config.Quality = "premium"
+	}
+	if req.Size != "" {
+		width, height := parseSize(req.Size)
+		config.Width, config.Height = width, height
+	}
+
+	return titanRequest{
+		TaskType: "TEXT_IMAGE",
+		TextToImageParams: titanTextParams{
+			Text:         req.Prompt,
+			NegativeText: req.NegativePrompt,
+		},
+		ImageGenerationConfig: config,
+	}
+}
+
+// invoke calls the Bedrock InvokeModel API with a SigV4-signed request
+func (b *Bedrock) invoke(ctx context.Context, model string, body []byte) ([]byte, error) {
+	creds, err := b.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mutations must happen before signing, which covers the body
+	body, err = b.middleware.MutateRequest(body)
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/model/" + model + "/invoke"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.URL.RawPath = awsauth.EscapePath(path)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	awsauth.Sign(httpReq, body, creds, b.region, bedrockService, b.httpClient.Clock().Now())
+
+	resp, err := b.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyBedrockError(resp.StatusCode, resp.Header.Get("X-Amzn-ErrorType"), respBody)
+	}
+
+	return b.middleware.MutateResponse(respBody)
+}
+
+// credentials returns cached AWS credentials, reloading expired ones
+func (b *Bedrock) credentials(ctx context.Context) (*awsauth.Credentials, error) {
+	b.credsMu.Lock()
+	defer b.credsMu.Unlock()
+
+	if b.creds == nil || b.creds.Expired() {
+		creds, err := awsauth.LoadCredentials(ctx, b.profile)
+		if err != nil {
+			return nil, err
+		}
+		b.creds = creds
+	}
+	return b.creds, nil
+}
+
+func (b *Bedrock) extractModelName(model string) string {
+	return strings.TrimPrefix(model, b.Name()+"/")
+}
+
+func isTitanModel(model string) bool {
+	return strings.HasPrefix(model, "amazon.titan-image")
+}
+
+// classifyBedrockError maps a Bedrock error response to an APIError
+// using the X-Amzn-ErrorType header
+func classifyBedrockError(status int, errorType string, body []byte) *APIError {
+	var apiResp struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &apiResp)
+
+	// The header may carry a suffix, e.g. "ValidationException:http://..."
+	code, _, _ := strings.Cut(errorType, ":")
+
+	apiErr := &APIError{
+		Provider:   "Bedrock",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+		Code:       code,
+		Message:    apiResp.Message,
+	}
+
+	switch code {
+	case "AccessDeniedException", "UnrecognizedClientException":
+		apiErr.Kind = ErrKindAuth
+		apiErr.Hint = "check the AWS credentials and that model access is granted " +
+			"for this model in the Bedrock console of the configured region"
+	case "ResourceNotFoundException":
+		apiErr.Kind = ErrKindNotFound
+		apiErr.Hint = "the model is not available in this region; check providers.bedrock.region"
+	case "ThrottlingException":
+		apiErr.Kind = ErrKindRateLimit
+		apiErr.Hint = "Bedrock throttled the request; wait and retry or request a quota increase"
+	case "ServiceQuotaExceededException":
+		apiErr.Kind = ErrKindQuota
+	case "ValidationException":
+		apiErr.Kind = ErrKindInvalidRequest
+		if strings.Contains(strings.ToLower(apiResp.Message), "content filter") {
+			apiErr.Kind = ErrKindContentPolicy
+			apiErr.Hint = "the prompt or image was blocked by the Bedrock content filter; rephrase it"
+		}
+	case "ModelTimeoutException", "ModelNotReadyException", "InternalServerException":
+		apiErr.Kind = ErrKindServer
+	}
+
+	return apiErr
+}
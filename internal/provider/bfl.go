@@ -0,0 +1,335 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const bflBaseURL = "https://api.bfl.ai/v1"
+
+// bflResultTTL is how long result URLs stay valid
+const bflResultTTL = 10 * time.Minute
+
+// BFL implements the Provider interface for the Black Forest Labs API,
+// which serves FLUX models directly
+type BFL struct {
+	apiKey       string
+	baseURL      string
+	httpClient   *httputil.Client
+	pollInterval time.Duration
+}
+
+// NewBFL creates a new Black Forest Labs provider
+func NewBFL(cfg *ProviderConfig) *BFL {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = bflBaseURL
+	}
+
+	return &BFL{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+		pollInterval: time.Second,
+	}
+}
+
+func (b *BFL) Name() string {
+	return "bfl"
+}
+
+func (b *BFL) SupportedModels() []Model {
+	return catalogModels(b.Name())
+}
+
+func (b *BFL) ValidateRequest(req *generator.Request) error {
+	if b.apiKey == "" {
+		return fmt.Errorf("Black Forest Labs API key is required (set BFL_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Black Forest Labs does not support seamless tiling")
+	}
+
+	if req.Count > 1 {
+		return fmt.Errorf("Black Forest Labs returns one image per request")
+	}
+
+	if req.SafetyTolerance != nil && (*req.SafetyTolerance < 0 || *req.SafetyTolerance > 6) {
+		return fmt.Errorf("safety tolerance must be between 0 (strict) and 6 (permissive)")
+	}
+
+	if !b.usesAspectRatio(b.extractModelName(req.Model)) && req.Size != "" {
+		width, height, err := splitSize(req.Size)
+		if err != nil {
+			return err
+		}
+		if width%32 != 0 || height%32 != 0 || width < 256 || height < 256 || width > 1440 || height > 1440 {
+			return fmt.Errorf("invalid size %s: width and height must be multiples of 32 between 256 and 1440", req.Size)
+		}
+	}
+
+	return nil
+}
+
+type bflRequest struct {
+	Prompt           string `json:"prompt"`
+	Width            int    `json:"width,omitempty"`
+	Height           int    `json:"height,omitempty"`
+	AspectRatio      string `json:"aspect_ratio,omitempty"`
+	Seed             *int64 `json:"seed,omitempty"`
+	Steps            int    `json:"steps,omitempty"`
+	PromptUpsampling *bool  `json:"prompt_upsampling,omitempty"`
+	SafetyTolerance  *int   `json:"safety_tolerance,omitempty"`
+	OutputFormat     string `json:"output_format"`
+}
+
+type bflTask struct {
+	ID         string `json:"id"`
+	PollingURL string `json:"polling_url"`
+}
+
+type bflResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Result *struct {
+		Sample string  `json:"sample"`
+		Seed   *int64  `json:"seed,omitempty"`
+		Prompt *string `json:"prompt,omitempty"`
+	} `json:"result"`
+}
+
+func (b *BFL) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := b.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	model := b.extractModelName(req.Model)
+
+	apiReq := bflRequest{
+		Prompt:          req.Prompt,
+		Seed:            req.Seed,
+		Steps:           req.Steps,
+		SafetyTolerance: req.SafetyTolerance,
+		OutputFormat:    "png",
+	}
+	if b.usesAspectRatio(model) {
+		apiReq.AspectRatio = req.AspectRatio
+	} else if req.Size != "" {
+		apiReq.Width, apiReq.Height = parseSize(req.Size)
+	}
+	switch strings.ToLower(req.MagicPrompt) {
+	case "on":
+		upsample := true
+		apiReq.PromptUpsampling = &upsample
+	case "off":
+		upsample := false
+		apiReq.PromptUpsampling = &upsample
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		b.baseURL+"/"+model,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("x-key", b.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyBFLError(resp.StatusCode, respBody)
+	}
+
+	var task bflTask
+	if err := json.Unmarshal(respBody, &task); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if task.PollingURL == "" {
+		task.PollingURL = b.baseURL + "/get_result?id=" + task.ID
+	}
+
+	result, err := b.waitForResult(ctx, task.PollingURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Result URLs are signed and expire after a few minutes
+	var image generator.Image
+	if req.URLOnly {
+		image = hostedImage(result.Result.Sample, "jpeg", 0, bflResultTTL)
+	} else {
+		data, format, err := b.downloadImage(ctx, result.Result.Sample)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		image = generator.Image{
+			Data:   data,
+			Format: format,
+			Index:  0,
+		}
+	}
+	image.Seed = req.Seed
+	if result.Result.Seed != nil {
+		image.Seed = result.Result.Seed
+	}
+
+	revisedPrompt := ""
+	if result.Result.Prompt != nil && *result.Result.Prompt != req.Prompt {
+		revisedPrompt = *result.Result.Prompt
+	}
+
+	return &generator.Response{
+		Images:        []generator.Image{image},
+		Model:         req.Model,
+		Provider:      b.Name(),
+		RevisedPrompt: revisedPrompt,
+		GeneratedAt:   time.Now(),
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+// waitForResult polls a task until it is ready or fails
+func (b *BFL) waitForResult(ctx context.Context, pollingURL string) (*bflResult, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-b.httpClient.Clock().After(b.pollInterval):
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, pollingURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("x-key", b.apiKey)
+
+		resp, err := b.httpClient.Do(ctx, httpReq)
+		if err != nil {
+			return nil, err
+		}
+
+		var result bflResult
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result: %w", err)
+		}
+
+		switch result.Status {
+		case "Ready":
+			if result.Result == nil || result.Result.Sample == "" {
+				return nil, fmt.Errorf("no image in Black Forest Labs result")
+			}
+			return &result, nil
+		case "Pending":
+			continue
+		case "Request Moderated", "Content Moderated":
+			return nil, &APIError{
+				Provider: "Black Forest Labs",
+				Kind:     ErrKindContentPolicy,
+				Code:     result.Status,
+				Message:  strings.ToLower(result.Status),
+				Hint:     "rephrase the prompt or raise --safety-tolerance (0 strict to 6 permissive)",
+			}
+		default:
+			return nil, fmt.Errorf("Black Forest Labs generation failed: %s", result.Status)
+		}
+	}
+}
+
+func (b *BFL) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := b.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (b *BFL) extractModelName(model string) string {
+	return strings.TrimPrefix(model, b.Name()+"/")
+}
+
+// usesAspectRatio reports whether a model takes an aspect ratio instead
+// of width and height
+func (b *BFL) usesAspectRatio(model string) bool {
+	return strings.HasPrefix(model, "flux-kontext") || strings.HasSuffix(model, "-ultra")
+}
+
+// classifyBFLError maps a Black Forest Labs error response to an APIError
+func classifyBFLError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Black Forest Labs",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Detail any `json:"detail"`
+	}
+	json.Unmarshal(body, &apiResp)
+	if detail, ok := apiResp.Detail.(string); ok {
+		apiErr.Message = detail
+	}
+
+	switch apiErr.Kind {
+	case ErrKindAuth:
+		apiErr.Hint = "check BFL_API_KEY or providers.bfl.api_key"
+	case ErrKindQuota:
+		apiErr.Hint = "your Black Forest Labs credits are exhausted; top up at https://api.bfl.ai"
+	case ErrKindRateLimit:
+		apiErr.Hint = "too many active tasks; wait for running generations to finish"
+	}
+
+	return apiErr
+}
@@ -0,0 +1,22 @@
+package provider
+
+import "github.com/piligrim/llm-imager/internal/catalog"
+
+// catalogModels returns the models of a provider from the model catalog
+func catalogModels(providerName string) []Model {
+	entries := catalog.Current().ForProvider(providerName)
+
+	models := make([]Model, 0, len(entries))
+	for _, e := range entries {
+		models = append(models, Model{
+			ID:            e.ID,
+			Name:          e.Name,
+			Provider:      e.Provider,
+			Sizes:         e.Sizes,
+			Features:      e.Features,
+			PricePerImage: e.PricePerImage,
+			MaxImages:     e.MaxImages,
+		})
+	}
+	return models
+}
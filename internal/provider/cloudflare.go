@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const cloudflareBaseURL = "https://api.cloudflare.com/client/v4"
+
+// Cloudflare implements the Provider interface for Cloudflare Workers AI
+type Cloudflare struct {
+	apiToken   string
+	accountID  string
+	baseURL    string
+	httpClient *httputil.Client
+}
+
+// NewCloudflare creates a new Cloudflare Workers AI provider
+func NewCloudflare(cfg *ProviderConfig) *Cloudflare {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = cloudflareBaseURL
+	}
+
+	return &Cloudflare{
+		apiToken:  cfg.APIKey,
+		accountID: cfg.AccountID,
+		baseURL:   baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+	}
+}
+
+func (c *Cloudflare) Name() string {
+	return "cloudflare"
+}
+
+func (c *Cloudflare) SupportedModels() []Model {
+	return catalogModels(c.Name())
+}
+
+func (c *Cloudflare) ValidateRequest(req *generator.Request) error {
+	if c.apiToken == "" {
+		return fmt.Errorf("Cloudflare API token is required (set CLOUDFLARE_API_TOKEN)")
+	}
+
+	if c.accountID == "" {
+		return fmt.Errorf("Cloudflare account ID is required (set CLOUDFLARE_ACCOUNT_ID)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Cloudflare Workers AI does not support seamless tiling")
+	}
+
+	if req.Count > 1 {
+		return fmt.Errorf("Cloudflare Workers AI returns one image per request")
+	}
+
+	model := c.extractModelName(req.Model)
+	if maxSteps := c.maxSteps(model); req.Steps > maxSteps {
+		return fmt.Errorf("model %s supports at most %d steps", model, maxSteps)
+	}
+
+	return nil
+}
+
+type cloudflareRequest struct {
+	Prompt         string `json:"prompt"`
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	Width          int    `json:"width,omitempty"`
+	Height         int    `json:"height,omitempty"`
+	Steps          int    `json:"steps,omitempty"`     // FLUX
+	NumSteps       int    `json:"num_steps,omitempty"` // Stable Diffusion
+	Seed           *int64 `json:"seed,omitempty"`
+}
+
+type cloudflareResponse struct {
+	Result struct {
+		Image string `json:"image"` // Base64, returned by FLUX models
+	} `json:"result"`
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (c *Cloudflare) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := c.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	model := c.extractModelName(req.Model)
+
+	apiReq := cloudflareRequest{
+		Prompt: req.Prompt,
+		Seed:   req.Seed,
+	}
+	if c.isFlux(model) {
+		apiReq.Steps = req.Steps
+	} else {
+		apiReq.NumSteps = req.Steps
+		apiReq.NegativePrompt = req.NegativePrompt
+		if req.Size != "" {
+			apiReq.Width, apiReq.Height = parseSize(req.Size)
+		}
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/accounts/%s/ai/run/%s", c.baseURL, c.accountID, model),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyCloudflareError(resp.StatusCode, respBody)
+	}
+
+	// Stable Diffusion models return the raw image, FLUX models wrap it
+	// in JSON
+	data := respBody
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		var apiResp cloudflareResponse
+		if err := json.Unmarshal(respBody, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if apiResp.Result.Image == "" {
+			return nil, fmt.Errorf("no image in Cloudflare response")
+		}
+		data, err = base64.StdEncoding.DecodeString(apiResp.Result.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+	}
+
+	format := "png"
+	if http.DetectContentType(data) == "image/jpeg" {
+		format = "jpeg"
+	}
+
+	return &generator.Response{
+		Images: []generator.Image{{
+			Data:   data,
+			Format: format,
+			Seed:   req.Seed,
+			Index:  0,
+		}},
+		Model:       req.Model,
+		Provider:    c.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+func (c *Cloudflare) extractModelName(model string) string {
+	return strings.TrimPrefix(model, c.Name()+"/")
+}
+
+func (c *Cloudflare) isFlux(model string) bool {
+	return strings.Contains(model, "flux")
+}
+
+// maxSteps returns the step limit of a model
+func (c *Cloudflare) maxSteps(model string) int {
+	if c.isFlux(model) {
+		return 8
+	}
+	return 20
+}
+
+// classifyCloudflareError maps a Cloudflare error response to an APIError
+func classifyCloudflareError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Cloudflare",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp cloudflareResponse
+	if json.Unmarshal(body, &apiResp) == nil && len(apiResp.Errors) > 0 {
+		apiErr.Code = fmt.Sprintf("%d", apiResp.Errors[0].Code)
+		apiErr.Message = apiResp.Errors[0].Message
+	}
+
+	lower := strings.ToLower(apiErr.Message)
+	switch {
+	case strings.Contains(lower, "daily free allocation") || strings.Contains(lower, "neurons"):
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "the daily free Workers AI allocation is used up; it resets at 00:00 UTC or upgrade to Workers Paid"
+	case strings.Contains(lower, "nsfw"):
+		apiErr.Kind = ErrKindContentPolicy
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check CLOUDFLARE_API_TOKEN; the token needs the Workers AI permission"
+	case apiErr.Kind == ErrKindNotFound:
+		apiErr.Hint = "check CLOUDFLARE_ACCOUNT_ID and the model name"
+	}
+
+	return apiErr
+}
@@ -0,0 +1,357 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// Compatible APIs a config-defined provider can speak
+const (
+	CompatibleAPIImages = "images" // POST /images/generations
+	CompatibleAPIChat   = "chat"   // POST /chat/completions with image output
+)
+
+// OpenAICompatible implements the Provider interface for a vendor declared
+// in the config file that speaks the OpenAI images API or chat completions
+// with image output. Each config entry becomes its own provider.
+type OpenAICompatible struct {
+	name       string
+	api        string
+	apiKey     string
+	baseURL    string
+	models     []string
+	headers    map[string]string
+	httpClient *httputil.Client
+}
+
+// NewOpenAICompatible creates a provider from a custom provider entry.
+// The API key may reference environment variables as $VAR.
+func NewOpenAICompatible(cfg *ProviderConfig) (*OpenAICompatible, error) {
+	if err := checkProviderName(cfg.Name); err != nil {
+		return nil, err
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("provider %s: base_url is required", cfg.Name)
+	}
+
+	api := strings.ToLower(cfg.API)
+	switch api {
+	case "":
+		api = CompatibleAPIImages
+	case CompatibleAPIImages, CompatibleAPIChat:
+	default:
+		return nil, fmt.Errorf("provider %s: unsupported api %q, supported: images, chat", cfg.Name, cfg.API)
+	}
+
+	return &OpenAICompatible{
+		name:    strings.ToLower(cfg.Name),
+		api:     api,
+		apiKey:  os.ExpandEnv(cfg.APIKey),
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		models:  cfg.Models,
+		headers: expandHeaders(cfg.Headers),
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+	}, nil
+}
+
+func (c *OpenAICompatible) Name() string {
+	return c.name
+}
+
+// SupportedModels returns the models listed in the config entry
+func (c *OpenAICompatible) SupportedModels() []Model {
+	models := make([]Model, 0, len(c.models))
+	for _, m := range c.models {
+		models = append(models, Model{
+			ID:       c.name + "/" + m,
+			Name:     m,
+			Provider: c.name,
+		})
+	}
+	return models
+}
+
+func (c *OpenAICompatible) ValidateRequest(req *generator.Request) error {
+	if c.extractModelName(req.Model) == "" {
+		return fmt.Errorf("%s needs a model name, e.g. %s/<model>", c.name, c.name)
+	}
+
+	if req.Tile {
+		return fmt.Errorf("%s does not support seamless tiling", c.name)
+	}
+
+	return nil
+}
+
+type compatibleChatResponse struct {
+	Choices []struct {
+		Message map[string]any `json:"message"`
+	} `json:"choices"`
+}
+
+func (c *OpenAICompatible) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := c.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	var images []generator.Image
+	var err error
+	if c.api == CompatibleAPIChat {
+		images, err = c.generateChat(ctx, req)
+	} else {
+		images, err = c.generateImages(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    c.name,
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// generateImages calls the images API, which returns all images at once
+func (c *OpenAICompatible) generateImages(ctx context.Context, req *generator.Request) ([]generator.Image, error) {
+	apiReq := openaiImageRequest{
+		Model:          c.extractModelName(req.Model),
+		Prompt:         req.Prompt,
+		N:              req.Count,
+		Size:           req.Size,
+		ResponseFormat: "b64_json",
+	}
+	if req.URLOnly {
+		apiReq.ResponseFormat = "url"
+	}
+
+	respBody, err := c.post(ctx, "/images/generations", apiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResp openaiImageResponse
+	if err := decodeTolerant(c.name, respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Data))
+	for i, item := range apiResp.Data {
+		switch {
+		case item.B64JSON != "":
+			data, err := base64.StdEncoding.DecodeString(item.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image: %w", err)
+			}
+			images = append(images, generator.Image{Data: data, Format: "png", Index: i})
+		case item.URL != "" && req.URLOnly:
+			images = append(images, hostedImage(item.URL, "png", i, 0))
+		case item.URL != "":
+			data, format, err := c.downloadImage(ctx, item.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image: %w", err)
+			}
+			images = append(images, generator.Image{Data: data, Format: format, Index: i})
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError("")
+	}
+	return images, nil
+}
+
+// generateChat asks a chat model for images, one request per image since
+// chat models answer with a single message
+func (c *OpenAICompatible) generateChat(ctx context.Context, req *generator.Request) ([]generator.Image, error) {
+	apiReq := map[string]any{
+		"model": c.extractModelName(req.Model),
+		"messages": []map[string]string{
+			{"role": "user", "content": req.Prompt},
+		},
+		"modalities": []string{"image", "text"},
+	}
+
+	var images []generator.Image
+	for range max(req.Count, 1) {
+		respBody, err := c.post(ctx, "/chat/completions", apiReq)
+		if err != nil {
+			return nil, err
+		}
+
+		var apiResp compatibleChatResponse
+		if err := decodeTolerant(c.name, respBody, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(apiResp.Choices) == 0 {
+			return nil, noImagesError("")
+		}
+
+		msg := apiResp.Choices[0].Message
+		urls := findImageURLs(map[string]any{"images": msg["images"], "content": msg["content"]})
+		if len(urls) == 0 {
+			text, _ := msg["content"].(string)
+			return nil, noImagesError(text)
+		}
+
+		for _, url := range urls {
+			var data []byte
+			var format string
+			if strings.HasPrefix(url, "data:") {
+				data, format, err = c.parseDataURL(url)
+			} else if req.URLOnly {
+				images = append(images, hostedImage(url, "png", len(images), 0))
+				continue
+			} else {
+				data, format, err = c.downloadImage(ctx, url)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image: %w", err)
+			}
+			images = append(images, generator.Image{Data: data, Format: format, Index: len(images)})
+		}
+	}
+
+	return images, nil
+}
+
+// post sends a JSON request and returns the body of a successful response
+func (c *OpenAICompatible) post(ctx context.Context, path string, payload any) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for name, value := range c.headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.classifyError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+func (c *OpenAICompatible) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := c.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (c *OpenAICompatible) parseDataURL(dataURL string) ([]byte, string, error) {
+	// Format: data:image/png;base64,<data>
+	header, payload, ok := strings.Cut(dataURL, ",")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid data URL")
+	}
+
+	format := "png"
+	if strings.Contains(header, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(header, "webp") {
+		format = "webp"
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, format, nil
+}
+
+func (c *OpenAICompatible) extractModelName(model string) string {
+	return strings.TrimPrefix(model, c.name+"/")
+}
+
+// classifyError maps an OpenAI-style error response to an APIError
+func (c *OpenAICompatible) classifyError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   c.name,
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Error *struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Code    any    `json:"code"`
+		} `json:"error"`
+	}
+	json.Unmarshal(body, &apiResp)
+	if apiResp.Error != nil {
+		apiErr.Message = apiResp.Error.Message
+		if apiResp.Error.Code != nil {
+			apiErr.Code = fmt.Sprint(apiResp.Error.Code)
+		} else {
+			apiErr.Code = apiResp.Error.Type
+		}
+	}
+
+	switch apiErr.Kind {
+	case ErrKindAuth:
+		apiErr.Hint = fmt.Sprintf("check api_key of the %s entry in custom_providers", c.name)
+	case ErrKindNotFound:
+		apiErr.Hint = fmt.Sprintf("check base_url and the model names of the %s entry in custom_providers", c.name)
+	}
+
+	return apiErr
+}
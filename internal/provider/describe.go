@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// describeMaxTokens caps the length of image descriptions
+const describeMaxTokens = 300
+
+type chatVisionRequest struct {
+	Model     string              `json:"model"`
+	Messages  []chatVisionMessage `json:"messages"`
+	MaxTokens int                 `json:"max_tokens,omitempty"`
+}
+
+type chatVisionMessage struct {
+	Role    string           `json:"role"`
+	Content []chatVisionPart `json:"content"`
+}
+
+type chatVisionPart struct {
+	Type     string         `json:"type"` // text or image_url
+	Text     string         `json:"text,omitempty"`
+	ImageURL *chatVisionURL `json:"image_url,omitempty"`
+}
+
+type chatVisionURL struct {
+	URL string `json:"url"`
+}
+
+type chatVisionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+		Code    any    `json:"code"`
+	} `json:"error,omitempty"`
+}
+
+// describeChat asks a vision model behind an OpenAI-compatible chat
+// completions endpoint to answer the prompt about an image, sent inline as
+// a data URL
+func describeChat(ctx context.Context, client *httputil.Client, endpoint string, headers map[string]string,
+	providerName, model, prompt string, img generator.InputImage) (string, error) {
+	apiReq := chatVisionRequest{
+		Model: model,
+		Messages: []chatVisionMessage{
+			{
+				Role: "user",
+				Content: []chatVisionPart{
+					{Type: "text", Text: prompt},
+					{Type: "image_url", ImageURL: &chatVisionURL{URL: dataURL(img)}},
+				},
+			},
+		},
+		MaxTokens: describeMaxTokens,
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(ctx, httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var apiResp chatVisionResponse
+	json.Unmarshal(respBody, &apiResp)
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			Provider:   providerName,
+			Kind:       kindFromStatus(resp.StatusCode),
+			StatusCode: resp.StatusCode,
+		}
+		if apiResp.Error != nil {
+			apiErr.Message = apiResp.Error.Message
+		}
+		return "", apiErr
+	}
+
+	if len(apiResp.Choices) == 0 || strings.TrimSpace(apiResp.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("%s returned no description", providerName)
+	}
+	return strings.TrimSpace(apiResp.Choices[0].Message.Content), nil
+}
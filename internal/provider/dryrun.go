@@ -3,10 +3,15 @@ package provider
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"hash/fnv"
+	"html"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,7 +20,9 @@ import (
 	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
 
+	"github.com/piligrim/llm-imager/internal/catalog"
 	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/imaging"
 )
 
 // DryRun implements a mock provider for testing without API calls
@@ -49,15 +56,24 @@ func (d *DryRun) ValidateRequest(req *generator.Request) error {
 func (d *DryRun) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
 	start := time.Now()
 
-	width, height := parseSize(req.Size)
+	width, height := DryRunSize(req)
 	count := req.Count
 	if count <= 0 {
 		count = 1
 	}
 
+	model := req.Model
+	if model == "" {
+		model = "dryrun/placeholder"
+	}
+	style := req.Style
+	if style == "" {
+		style = req.StyleType
+	}
+
 	images := make([]generator.Image, count)
 	for i := 0; i < count; i++ {
-		data, err := generatePlaceholder(width, height, req.Prompt)
+		data, err := generatePlaceholder(width, height, req.Prompt, model, style)
 		if err != nil {
 			return nil, err
 		}
@@ -72,14 +88,226 @@ func (d *DryRun) Generate(ctx context.Context, req *generator.Request) (*generat
 
 	return &generator.Response{
 		Images:        images,
-		Model:         "dryrun/placeholder",
+		Model:         model,
 		Provider:      "dryrun",
-		RevisedPrompt: req.Prompt,
+		RevisedPrompt: revisedPrompt(req.Prompt, style),
 		GeneratedAt:   time.Now(),
 		Duration:      time.Since(start),
 	}, nil
 }
 
+// Edit returns placeholder images, as if the input images were edited
+func (d *DryRun) Edit(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	return d.Generate(ctx, req)
+}
+
+// Outpaint returns placeholder images, as if the input image was extended
+func (d *DryRun) Outpaint(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	return d.Generate(ctx, req)
+}
+
+// SearchReplace returns placeholder images, as if an object of the input
+// image was replaced
+func (d *DryRun) SearchReplace(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	return d.Generate(ctx, req)
+}
+
+// Recolor returns placeholder images, as if an object of the input image
+// was recolored
+func (d *DryRun) Recolor(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	return d.Generate(ctx, req)
+}
+
+// RemoveBackground returns placeholder images, as if the background of
+// the input image was removed
+func (d *DryRun) RemoveBackground(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	return d.Generate(ctx, req)
+}
+
+// Vectorize returns a placeholder SVG of the size of the input image,
+// bordered in the model color
+func (d *DryRun) Vectorize(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	start := time.Now()
+
+	width, height := DryRunSize(req)
+	if len(req.InputImages) > 0 {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(req.InputImages[0].Data)); err == nil {
+			width, height = cfg.Width, cfg.Height
+		}
+	}
+	model := req.Model
+	if model == "" {
+		model = "dryrun/placeholder"
+	}
+	c := modelColor(model)
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+		`<rect width="100%%" height="100%%" fill="#f0f0f0" stroke="#%02x%02x%02x" stroke-width="8"/>`+
+		`<text x="50%%" y="50%%" text-anchor="middle" font-family="monospace" font-size="16">DRY-RUN %s</text></svg>`+"\n",
+		width, height, width, height, c.R, c.G, c.B, html.EscapeString(model))
+
+	return &generator.Response{
+		Images:      []generator.Image{{Data: []byte(svg), Format: "svg", Width: width, Height: height}},
+		Model:       model,
+		Provider:    "dryrun",
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(start),
+	}, nil
+}
+
+// Segment returns a placeholder mask of the size of img: a white ellipse
+// filling its middle
+func (d *DryRun) Segment(ctx context.Context, model, prompt string, img generator.InputImage) (generator.Image, error) {
+	width, height := 512, 512
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data)); err == nil {
+		width, height = cfg.Width, cfg.Height
+	}
+
+	mask := image.NewGray(image.Rect(0, 0, width, height))
+	cx, cy := float64(width)/2, float64(height)/2
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := (float64(x)-cx)/(cx/2), (float64(y)-cy)/(cy/2)
+			if dx*dx+dy*dy <= 1 {
+				mask.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mask); err != nil {
+		return generator.Image{}, fmt.Errorf("failed to encode mask: %w", err)
+	}
+	return generator.Image{Data: buf.Bytes(), Format: "png", Width: width, Height: height}, nil
+}
+
+// dryRunVideoFrames is the number of frames of placeholder videos
+const dryRunVideoFrames = 4
+
+// GenerateVideo returns a placeholder animated GIF of the size of the
+// input image, with numbered frames spread over the requested duration; a
+// placeholder MP4 would need a video encoder
+func (d *DryRun) GenerateVideo(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	start := time.Now()
+
+	width, height := DryRunSize(req)
+	if len(req.InputImages) > 0 {
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(req.InputImages[0].Data)); err == nil {
+			width, height = cfg.Width, cfg.Height
+		}
+	}
+	model := req.Model
+	if model == "" {
+		model = "dryrun/placeholder"
+	}
+
+	frames := make([]image.Image, 0, dryRunVideoFrames)
+	for i := range dryRunVideoFrames {
+		data, err := generatePlaceholder(width, height, req.Prompt, model, fmt.Sprintf("frame %d/%d", i+1, dryRunVideoFrames))
+		if err != nil {
+			return nil, err
+		}
+		frame, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	delay := 500 * time.Millisecond
+	if req.VideoDuration > 0 {
+		delay = time.Duration(req.VideoDuration) * time.Second / dryRunVideoFrames
+	}
+	video, err := imaging.EncodeGIF(frames, delay)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generator.Response{
+		Videos: []generator.Video{{
+			Image:   video,
+			Seconds: (delay * dryRunVideoFrames).Seconds(),
+			FPS:     req.FPS,
+		}},
+		Model:       model,
+		Provider:    "dryrun",
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(start),
+	}, nil
+}
+
+// Describe returns a placeholder description with the image size
+func (d *DryRun) Describe(ctx context.Context, model, prompt string, img generator.InputImage) (string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		return "A placeholder image.", nil
+	}
+	return fmt.Sprintf("A %dx%d placeholder image.", cfg.Width, cfg.Height), nil
+}
+
+// dryRunLongSide is the longer side of placeholders sized from an aspect
+// ratio
+const dryRunLongSide = 1024
+
+// DryRunSize returns the placeholder size of a request the way the
+// requested model would size it: catalog models with the aspect_ratio
+// feature follow the aspect ratio, with a 1024 pixel longer side, others
+// the WIDTHxHEIGHT size. Without a usable size the aspect ratio applies,
+// and without either 512x512.
+func DryRunSize(req *generator.Request) (int, int) {
+	var w, h int
+	m, ok := catalog.Current().Lookup(req.Model)
+	usesAspect := ok && slices.Contains(m.Features, "aspect_ratio")
+	if _, err := fmt.Sscanf(strings.ToLower(req.Size), "%dx%d", &w, &h); err == nil && w > 0 && h > 0 && !(usesAspect && req.AspectRatio != "") {
+		return w, h
+	}
+	if _, err := fmt.Sscanf(req.AspectRatio, "%d:%d", &w, &h); err == nil && w > 0 && h > 0 {
+		if w >= h {
+			return dryRunLongSide, max(dryRunLongSide*h/w, 1)
+		}
+		return max(dryRunLongSide*w/h, 1), dryRunLongSide
+	}
+	return parseSize(req.Size)
+}
+
+// revisedPrompt fakes the prompt rewriting of real models, so pipelines
+// reading revised prompts see one that differs from the prompt
+func revisedPrompt(prompt, style string) string {
+	if style != "" {
+		prompt += ", in a " + style + " style"
+	}
+	return prompt + " (dry-run revision)"
+}
+
+// modelColor returns a border color that tells the models of placeholders
+// apart: a hue hashed from the model name
+func modelColor(model string) color.RGBA {
+	h := fnv.New32a()
+	h.Write([]byte(model))
+	hue := float64(h.Sum32()%360) / 60
+
+	// HSV with saturation 0.6 and value 0.85
+	const v, s = 0.85, 0.6
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(hue, 2)-1))
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g = c, x
+	case 1:
+		r, g = x, c
+	case 2:
+		g, b = c, x
+	case 3:
+		g, b = x, c
+	case 4:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := v - c
+	return color.RGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 255}
+}
+
 // parseSize parses size string like "1024x1024" into width and height
 func parseSize(size string) (int, int) {
 	if size == "" {
@@ -100,8 +328,9 @@ func parseSize(size string) (int, int) {
 	return width, height
 }
 
-// generatePlaceholder creates a placeholder PNG image with prompt text
-func generatePlaceholder(width, height int, prompt string) ([]byte, error) {
+// generatePlaceholder creates a placeholder PNG image with prompt text,
+// framed in the color of the model and labeled with the model and style
+func generatePlaceholder(width, height int, prompt, model, style string) ([]byte, error) {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
 
 	// Fill with light gray background
@@ -115,15 +344,15 @@ func generatePlaceholder(width, height int, prompt string) ([]byte, error) {
 		img.Set(width-1-i, i, lineColor)
 	}
 
-	// Draw border
-	borderColor := color.RGBA{R: 180, G: 180, B: 180, A: 255}
-	for x := range width {
-		img.Set(x, 0, borderColor)
-		img.Set(x, height-1, borderColor)
-	}
+	// Draw border in the model color
+	borderColor := modelColor(model)
+	border := max(min(width, height)/64, 2)
 	for y := range height {
-		img.Set(0, y, borderColor)
-		img.Set(width-1, y, borderColor)
+		for x := range width {
+			if x < border || y < border || x >= width-border || y >= height-border {
+				img.Set(x, y, borderColor)
+			}
+		}
 	}
 
 	// Draw prompt text
@@ -132,7 +361,11 @@ func generatePlaceholder(width, height int, prompt string) ([]byte, error) {
 
 	// Draw "DRY-RUN" label at bottom
 	labelColor := color.RGBA{R: 100, G: 100, B: 100, A: 255}
-	drawLabel(img, "DRY-RUN", width, height, labelColor)
+	label := "DRY-RUN " + model
+	if style != "" {
+		label += " / " + style
+	}
+	drawLabel(img, label, width, height, labelColor)
 
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, img); err != nil {
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// checkInputImages checks the input images of an edit request against
+// what a provider accepts: at most maxImages images, and a mask only if
+// masks is set
+func checkInputImages(provider string, req *generator.Request, maxImages int, masks bool) error {
+	if len(req.InputImages) == 0 {
+		return fmt.Errorf("%s edits require an input image (--image)", provider)
+	}
+	if len(req.InputImages) > maxImages {
+		return fmt.Errorf("%s edits take at most %d input images, got %d", provider, maxImages, len(req.InputImages))
+	}
+	if req.Mask != nil && !masks {
+		return fmt.Errorf("%s does not support masks; describe the area to change in the prompt", provider)
+	}
+	return nil
+}
+
+// dataURL encodes an input image as a data URL, which APIs taking image
+// URLs accept inline
+func dataURL(img generator.InputImage) string {
+	return "data:" + img.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+}
+
+var formQuoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeFormImage adds an input image to a multipart form as a file with
+// its own content type; the APIs reject application/octet-stream images
+func writeFormImage(w *multipart.Writer, field string, img generator.InputImage) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		formQuoteEscaper.Replace(field), formQuoteEscaper.Replace(img.Name)))
+	h.Set("Content-Type", img.MIMEType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(img.Data)
+	return err
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ErrorKind classifies provider failures so callers can react to them
+// without parsing vendor-specific messages
+type ErrorKind string
+
+const (
+	ErrKindAuth           ErrorKind = "auth"
+	ErrKindQuota          ErrorKind = "quota"
+	ErrKindRateLimit      ErrorKind = "rate_limit"
+	ErrKindContentPolicy  ErrorKind = "content_policy"
+	ErrKindInvalidRequest ErrorKind = "invalid_request"
+	ErrKindNotFound       ErrorKind = "not_found"
+	ErrKindServer         ErrorKind = "server"
+	ErrKindUnknown        ErrorKind = "unknown"
+)
+
+// APIError is a classified error returned by a provider API
+type APIError struct {
+	Provider   string    // Display name, e.g. "OpenAI"
+	Kind       ErrorKind // Normalized error class
+	StatusCode int       // HTTP status code (0 if unknown)
+	Code       string    // Vendor error code/type, e.g. "insufficient_quota"
+	Message    string    // Vendor error message
+	Hint       string    // Actionable remediation hint (optional)
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s API error: %s", e.Provider, e.Message)
+	}
+	return fmt.Sprintf("%s API error: status %d", e.Provider, e.StatusCode)
+}
+
+// Retryable reports whether repeating the same request may succeed
+func (e *APIError) Retryable() bool {
+	return e.Kind == ErrKindRateLimit || e.Kind == ErrKindServer
+}
+
+// kindFromStatus derives a default error kind from an HTTP status code
+func kindFromStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrKindAuth
+	case status == http.StatusPaymentRequired:
+		return ErrKindQuota
+	case status == http.StatusTooManyRequests:
+		return ErrKindRateLimit
+	case status == http.StatusNotFound:
+		return ErrKindNotFound
+	case status >= 500:
+		return ErrKindServer
+	case status >= 400:
+		return ErrKindInvalidRequest
+	default:
+		return ErrKindUnknown
+	}
+}
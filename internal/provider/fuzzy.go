@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ModelNotFoundError is returned when no provider serves a model;
+// Suggestions holds close model IDs, best match first
+type ModelNotFoundError struct {
+	Model       string
+	Suggestions []string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("no provider found for model %s", e.Model)
+	}
+	return fmt.Sprintf("no provider found for model %s, did you mean %s?",
+		e.Model, strings.Join(e.Suggestions, " or "))
+}
+
+// maxSuggestions limits how many close matches are reported
+const maxSuggestions = 3
+
+// suggestModels returns registered model IDs close to the given name.
+// The caller must hold the registry lock.
+func (r *Registry) suggestModels(name string) []string {
+	name = strings.ToLower(name)
+	maxDist := max(2, len(name)/3)
+
+	type match struct {
+		id   string
+		dist int
+	}
+	var matches []match
+
+	for id, providerName := range r.models {
+		lower := strings.ToLower(id)
+		bare := strings.TrimPrefix(lower, providerName+"/")
+		last := bare[strings.LastIndex(bare, "/")+1:]
+
+		dist := min(levenshtein(name, lower), levenshtein(name, bare), levenshtein(name, last))
+		if dist <= maxDist {
+			matches = append(matches, match{id: id, dist: dist})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].id < matches[j].id
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, m := range matches {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, m.id)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,6 +18,9 @@ import (
 
 const googleBaseURL = "https://generativelanguage.googleapis.com/v1beta"
 
+// geminiAspectRatios are the aspect ratios accepted by Gemini image models
+var geminiAspectRatios = []string{"1:1", "2:3", "3:2", "3:4", "4:3", "4:5", "5:4", "9:16", "16:9", "21:9"}
+
 // Google implements the Provider interface for Google Gemini
 type Google struct {
 	apiKey     string
@@ -32,9 +36,14 @@ func NewGoogle(cfg *ProviderConfig) (*Google, error) {
 	}
 
 	return &Google{
-		apiKey:     cfg.APIKey,
-		baseURL:    baseURL,
-		httpClient: httputil.NewClient(httputil.WithRetries(cfg.MaxRetries)),
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithMiddleware(cfg.Middleware),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+		),
 	}, nil
 }
 
@@ -43,28 +52,22 @@ func (g *Google) Name() string {
 }
 
 func (g *Google) SupportedModels() []Model {
-	return []Model{
-		{
-			ID:       "google/gemini-2.0-flash-exp-image",
-			Name:     "Gemini 2.0 Flash Exp Image",
-			Provider: "google",
-			Sizes:    []string{"1024x1024"},
-			Features: []string{},
-		},
-		{
-			ID:       "google/imagen-3.0-generate-002",
-			Name:     "Imagen 3.0",
-			Provider: "google",
-			Sizes:    []string{"1024x1024"},
-			Features: []string{"aspect_ratio"},
-		},
-	}
+	return catalogModels(g.Name())
 }
 
 func (g *Google) ValidateRequest(req *generator.Request) error {
 	if g.apiKey == "" {
 		return fmt.Errorf("Google API key is required (set GOOGLE_API_KEY or GEMINI_API_KEY)")
 	}
+
+	if req.Tile {
+		return fmt.Errorf("Google does not support seamless tiling")
+	}
+
+	if req.AspectRatio != "" && !slices.Contains(geminiAspectRatios, req.AspectRatio) {
+		return fmt.Errorf("invalid aspect ratio %s for Gemini, supported: %s",
+			req.AspectRatio, strings.Join(geminiAspectRatios, ", "))
+	}
 	return nil
 }
 
@@ -89,7 +92,13 @@ type geminiRequest struct {
 }
 
 type geminiGenConfig struct {
-	ResponseModalities []string `json:"responseModalities,omitempty"`
+	ResponseModalities []string           `json:"responseModalities,omitempty"`
+	ImageConfig        *geminiImageConfig `json:"imageConfig,omitempty"`
+}
+
+type geminiImageConfig struct {
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	ImageSize   string `json:"imageSize,omitempty"` // 1K, 2K or 4K; Gemini 3 only
 }
 
 type geminiResponse struct {
@@ -118,21 +127,106 @@ func (g *Google) Generate(ctx context.Context, req *generator.Request) (*generat
 
 	startTime := time.Now()
 
+	return g.generateContent(ctx, req, []geminiPart{{Text: req.Prompt}}, startTime)
+}
+
+// Edit sends the input images with the prompt as image+text parts. Gemini
+// has no mask input; the prompt describes what to change.
+func (g *Google) Edit(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := g.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	model := g.extractModelName(req.Model)
+	if strings.HasPrefix(model, "imagen") {
+		return nil, fmt.Errorf("Imagen models do not support edits, use a Gemini image model")
+	}
+	maxImages := 3
+	if strings.HasPrefix(model, "gemini-3") {
+		maxImages = 14
+	}
+	if err := checkInputImages("Gemini", req, maxImages, false); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	parts := []geminiPart{{Text: req.Prompt}}
+	for _, img := range req.InputImages {
+		parts = append(parts, geminiPart{InlineData: &geminiDataBlob{
+			MIMEType: img.MIMEType,
+			Data:     base64.StdEncoding.EncodeToString(img.Data),
+		}})
+	}
+
+	return g.generateContent(ctx, req, parts, startTime)
+}
+
+// generateContent sends the parts of a single user turn and returns the
+// images of the reply
+func (g *Google) generateContent(ctx context.Context, req *generator.Request, parts []geminiPart, startTime time.Time) (*generator.Response, error) {
 	model := g.extractModelName(req.Model)
 
 	apiReq := geminiRequest{
 		Contents: []geminiContent{
 			{
-				Parts: []geminiPart{
-					{Text: req.Prompt},
-				},
+				Parts: parts,
 			},
 		},
 		GenerationConfig: &geminiGenConfig{
 			ResponseModalities: []string{"TEXT", "IMAGE"},
+			ImageConfig:        g.imageConfig(model, req),
 		},
 	}
 
+	apiResp, err := g.call(ctx, model, &apiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]generator.Image, 0)
+	var text strings.Builder
+
+	if len(apiResp.Candidates) > 0 {
+		for i, part := range apiResp.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+			if part.InlineData != nil && part.InlineData.Data != "" {
+				data, err := decodeBase64(part.InlineData.Data)
+				if err != nil {
+					continue
+				}
+
+				format := "png"
+				if strings.Contains(part.InlineData.MIMEType, "jpeg") {
+					format = "jpeg"
+				} else if strings.Contains(part.InlineData.MIMEType, "webp") {
+					format = "webp"
+				}
+
+				images = append(images, generator.Image{
+					Data:   data,
+					Format: format,
+					Index:  i,
+				})
+			}
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError(text.String())
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    g.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// call sends a generateContent request
+func (g *Google) call(ctx context.Context, model string, apiReq *geminiRequest) (*geminiResponse, error) {
 	body, err := json.Marshal(apiReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -164,7 +258,7 @@ func (g *Google) Generate(ctx context.Context, req *generator.Request) (*generat
 	}
 
 	var apiResp geminiResponse
-	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+	if err := decodeTolerant("Gemini", respBody, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -176,43 +270,209 @@ func (g *Google) Generate(ctx context.Context, req *generator.Request) (*generat
 		return nil, fmt.Errorf("Gemini API error: status %d", resp.StatusCode)
 	}
 
-	images := make([]generator.Image, 0)
+	return &apiResp, nil
+}
 
+// Endpoint returns the generateContent endpoint of the model
+func (g *Google) Endpoint(req *generator.Request) string {
+	return fmt.Sprintf("POST %s/models/%s:generateContent", g.baseURL, g.extractModelName(req.Model))
+}
+
+// Describe answers the prompt about an image with a Gemini model
+func (g *Google) Describe(ctx context.Context, model, prompt string, img generator.InputImage) (string, error) {
+	if err := g.ValidateRequest(&generator.Request{}); err != nil {
+		return "", err
+	}
+
+	apiResp, err := g.call(ctx, g.extractModelName(model), &geminiRequest{
+		Contents: []geminiContent{
+			{
+				Parts: []geminiPart{
+					{Text: prompt},
+					{InlineData: &geminiDataBlob{
+						MIMEType: img.MIMEType,
+						Data:     base64.StdEncoding.EncodeToString(img.Data),
+					}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
 	if len(apiResp.Candidates) > 0 {
-		for i, part := range apiResp.Candidates[0].Content.Parts {
-			if part.InlineData != nil && part.InlineData.Data != "" {
-				data, err := decodeBase64(part.InlineData.Data)
-				if err != nil {
-					continue
-				}
+		for _, part := range apiResp.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", fmt.Errorf("Gemini returned no description")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
 
-				format := "png"
-				if strings.Contains(part.InlineData.MIMEType, "jpeg") {
-					format = "jpeg"
-				} else if strings.Contains(part.InlineData.MIMEType, "webp") {
-					format = "webp"
-				}
+// Probe checks model access via the models metadata endpoint
+func (g *Google) Probe(ctx context.Context, model string) (*ProbeResult, error) {
+	if err := g.ValidateRequest(&generator.Request{}); err != nil {
+		return nil, err
+	}
 
-				images = append(images, generator.Image{
-					Data:   data,
-					Format: format,
-					Index:  i,
-				})
+	result := newProbeResult(g, model)
+
+	url := fmt.Sprintf("%s/models/%s?key=%s", g.baseURL, g.extractModelName(model), g.apiKey)
+	resp, err := g.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Invalid keys are reported as 400 by the Generative Language API
+	status := resp.StatusCode
+	if status == http.StatusBadRequest {
+		status = http.StatusForbidden
+	}
+	if err := applyProbeStatus(result, "Gemini", status); err != nil {
+		return nil, err
+	}
+
+	if result.Available {
+		var info googleModelInfo
+		if err := json.NewDecoder(resp.Body).Decode(&info); err == nil && len(info.SupportedGenerationMethods) > 0 &&
+			!slices.Contains(info.SupportedGenerationMethods, "generateContent") {
+			result.Available = false
+			result.Message = fmt.Sprintf("model does not support generateContent (methods: %s)",
+				strings.Join(info.SupportedGenerationMethods, ", "))
+		}
+	}
+
+	return result, nil
+}
+
+// GoogleModel describes an image-capable model returned by the models.list endpoint
+type GoogleModel struct {
+	ID      string   // google/<name>
+	Name    string   // Display name
+	Methods []string // Supported generation methods
+}
+
+type googleModelInfo struct {
+	Name                       string   `json:"name"`
+	DisplayName                string   `json:"displayName"`
+	SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+}
+
+type googleModelsResponse struct {
+	Models        []googleModelInfo `json:"models"`
+	NextPageToken string            `json:"nextPageToken"`
+}
+
+// FetchModels lists the image-capable Gemini and Imagen models the API key can access
+func (g *Google) FetchModels(ctx context.Context) ([]GoogleModel, error) {
+	if err := g.ValidateRequest(&generator.Request{}); err != nil {
+		return nil, err
+	}
+
+	var models []GoogleModel
+	pageToken := ""
+	for {
+		url := fmt.Sprintf("%s/models?key=%s&pageSize=1000", g.baseURL, g.apiKey)
+		if pageToken != "" {
+			url += "&pageToken=" + pageToken
+		}
+
+		page, err := g.fetchModelsPage(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range page.Models {
+			name := strings.TrimPrefix(m.Name, "models/")
+			if !isGoogleImageModel(name) {
+				continue
 			}
+			models = append(models, GoogleModel{
+				ID:      "google/" + name,
+				Name:    m.DisplayName,
+				Methods: m.SupportedGenerationMethods,
+			})
 		}
+
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = page.NextPageToken
 	}
+}
 
-	if len(images) == 0 {
-		return nil, fmt.Errorf("no images generated")
+func (g *Google) fetchModelsPage(ctx context.Context, url string) (*googleModelsResponse, error) {
+	resp, err := g.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	return &generator.Response{
-		Images:      images,
-		Model:       req.Model,
-		Provider:    g.Name(),
-		GeneratedAt: time.Now(),
-		Duration:    time.Since(startTime),
-	}, nil
+	// Invalid keys are reported as 400 by the Generative Language API
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return nil, &APIError{
+			Provider:   "Gemini",
+			Kind:       ErrKindAuth,
+			StatusCode: resp.StatusCode,
+			Message:    "API key rejected",
+		}
+	default:
+		return nil, &APIError{
+			Provider:   "Gemini",
+			Kind:       kindFromStatus(resp.StatusCode),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	var page googleModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &page, nil
+}
+
+// isGoogleImageModel reports whether a model name belongs to an image
+// generation model (Gemini image variants and Imagen)
+func isGoogleImageModel(name string) bool {
+	return strings.HasPrefix(name, "imagen") || strings.Contains(name, "-image")
+}
+
+// imageConfig returns the image size hints for a request. Gemini 2.0
+// models do not accept them, and only Gemini 3 models take an image size.
+func (g *Google) imageConfig(model string, req *generator.Request) *geminiImageConfig {
+	if strings.HasPrefix(model, "gemini-2.0") {
+		return nil
+	}
+
+	config := &geminiImageConfig{AspectRatio: req.AspectRatio}
+	if strings.HasPrefix(model, "gemini-3") {
+		config.ImageSize = g.mapImageSize(req.Size)
+	}
+	if config.AspectRatio == "" && config.ImageSize == "" {
+		return nil
+	}
+	return config
+}
+
+// mapImageSize maps a size to a Gemini image size tier
+func (g *Google) mapImageSize(size string) string {
+	switch size {
+	case "1024x1024", "1K":
+		return "1K"
+	case "2048x2048", "2K":
+		return "2K"
+	case "4096x4096", "4K":
+		return "4K"
+	default:
+		return ""
+	}
 }
 
 func (g *Google) extractModelName(model string) string {
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// hostedImage returns an image left at its hosted URL instead of being
+// downloaded (Request.URLOnly). ttl is how long the provider keeps the URL
+// valid, 0 if it does not document it.
+func hostedImage(imageURL, format string, index int, ttl time.Duration) generator.Image {
+	image := generator.Image{
+		URL:    imageURL,
+		Format: formatFromURL(imageURL, format),
+		Index:  index,
+	}
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		image.ExpiresAt = &expires
+	}
+	return image
+}
+
+// formatFromURL guesses the image format from the URL path extension
+func formatFromURL(imageURL, fallback string) string {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return fallback
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".png":
+		return "png"
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".webp":
+		return "webp"
+	case ".svg":
+		return "svg"
+	case ".mp4":
+		return "mp4"
+	case ".webm":
+		return "webm"
+	}
+	return fallback
+}
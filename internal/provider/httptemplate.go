@@ -0,0 +1,408 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// HTTPTemplateSpec describes a provider declared in a template file: how
+// to build the request and where the images are in the response
+type HTTPTemplateSpec struct {
+	Name       string
+	APIKey     string // May reference $VAR
+	Models     []string
+	Params     []string // Keys accepted by --param, available as .Params
+	MaxRetries int      // 0 for 3, as for custom providers
+
+	Method  string            // Default POST
+	URL     string            // Template
+	Headers map[string]string // Values are templates
+	Body    string            // JSON body template, empty for no body
+
+	ImagesPath string // JSONPath to image URLs, data URLs or base64 strings
+	ErrorPath  string // JSONPath to the error message of failed requests
+	Format     string // Format of base64 images, detected if empty
+}
+
+// templateData is the data available to request templates
+type templateData struct {
+	Model          string
+	Prompt         string
+	NegativePrompt string
+	Size           string
+	Width          int
+	Height         int
+	AspectRatio    string
+	Quality        string
+	Style          string
+	Count          int
+	Seed           int64
+	HasSeed        bool
+	Steps          int
+	APIKey         string
+	Params         map[string]string
+	ParamValues    map[string]any // Params typed, e.g. {{json .ParamValues.loras}}
+}
+
+// templateFuncs are the functions available to request templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value as a JSON literal, quoting and escaping strings
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// HTTPTemplate implements the Provider interface for an API described by
+// a template file instead of code
+type HTTPTemplate struct {
+	name       string
+	apiKey     string
+	models     []string
+	params     []string
+	method     string
+	url        *template.Template
+	headers    map[string]*template.Template
+	body       *template.Template
+	images     *jsonPath
+	errorPath  *jsonPath
+	format     string
+	httpClient *httputil.Client
+}
+
+// NewHTTPTemplate compiles the templates and JSONPaths of a spec
+func NewHTTPTemplate(spec *HTTPTemplateSpec) (*HTTPTemplate, error) {
+	if err := checkProviderName(spec.Name); err != nil {
+		return nil, err
+	}
+	if spec.URL == "" {
+		return nil, fmt.Errorf("provider %s: request.url is required", spec.Name)
+	}
+	if spec.ImagesPath == "" {
+		return nil, fmt.Errorf("provider %s: response.images is required", spec.Name)
+	}
+
+	maxRetries := spec.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+
+	t := &HTTPTemplate{
+		name:    strings.ToLower(spec.Name),
+		apiKey:  os.ExpandEnv(spec.APIKey),
+		models:  spec.Models,
+		params:  spec.Params,
+		method:  strings.ToUpper(spec.Method),
+		headers: make(map[string]*template.Template, len(spec.Headers)),
+		format:  spec.Format,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(maxRetries),
+		),
+	}
+	if t.method == "" {
+		t.method = http.MethodPost
+	}
+
+	var err error
+	parse := func(field, text string) (*template.Template, error) {
+		tmpl, err := template.New(field).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: invalid %s template: %w", spec.Name, field, err)
+		}
+		return tmpl, nil
+	}
+	if t.url, err = parse("request.url", spec.URL); err != nil {
+		return nil, err
+	}
+	for name, value := range spec.Headers {
+		if t.headers[name], err = parse("request.headers."+name, value); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Body != "" {
+		if t.body, err = parse("request.body", spec.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.images, err = compileJSONPath(spec.ImagesPath); err != nil {
+		return nil, fmt.Errorf("provider %s: response.images: %w", spec.Name, err)
+	}
+	if spec.ErrorPath != "" {
+		if t.errorPath, err = compileJSONPath(spec.ErrorPath); err != nil {
+			return nil, fmt.Errorf("provider %s: response.error: %w", spec.Name, err)
+		}
+	}
+
+	return t, nil
+}
+
+func (t *HTTPTemplate) Name() string {
+	return t.name
+}
+
+// SupportedModels returns the models listed in the template file
+func (t *HTTPTemplate) SupportedModels() []Model {
+	models := make([]Model, 0, len(t.models))
+	for _, m := range t.models {
+		models = append(models, Model{
+			ID:       t.name + "/" + m,
+			Name:     m,
+			Provider: t.name,
+		})
+	}
+	return models
+}
+
+// SupportedParams returns the --param keys listed in the template file
+func (t *HTTPTemplate) SupportedParams() []string {
+	return t.params
+}
+
+func (t *HTTPTemplate) ValidateRequest(req *generator.Request) error {
+	if t.extractModelName(req.Model) == "" {
+		return fmt.Errorf("%s needs a model name, e.g. %s/<model>", t.name, t.name)
+	}
+
+	if req.Tile {
+		return fmt.Errorf("%s does not support seamless tiling", t.name)
+	}
+
+	return nil
+}
+
+func (t *HTTPTemplate) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := t.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	httpReq, err := t.buildRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, t.classifyError(resp.StatusCode, respBody)
+	}
+
+	var doc any
+	if err := decodeTolerant(t.name, respBody, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var images []generator.Image
+	for _, value := range t.images.find(doc) {
+		s, ok := value.(string)
+		if !ok || s == "" {
+			continue
+		}
+		index := len(images)
+
+		if req.URLOnly && (strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")) {
+			images = append(images, hostedImage(s, "png", index, 0))
+			continue
+		}
+
+		data, format, err := t.decodeImage(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image %d: %w", index+1, err)
+		}
+		images = append(images, generator.Image{Data: data, Format: format, Index: index})
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError("")
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    t.name,
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// buildRequest renders the URL, headers and body templates
+func (t *HTTPTemplate) buildRequest(ctx context.Context, req *generator.Request) (*http.Request, error) {
+	width, height := parseSize(req.Size)
+	data := templateData{
+		Model:          t.extractModelName(req.Model),
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Size:           req.Size,
+		Width:          width,
+		Height:         height,
+		AspectRatio:    req.AspectRatio,
+		Quality:        req.Quality,
+		Style:          req.Style,
+		Count:          max(req.Count, 1),
+		HasSeed:        req.Seed != nil,
+		Steps:          req.Steps,
+		APIKey:         t.apiKey,
+		Params:         req.Params,
+		ParamValues:    req.ParamValues,
+	}
+	if req.Seed != nil {
+		data.Seed = *req.Seed
+	}
+
+	render := func(tmpl *template.Template) (string, error) {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("%s: %w", t.name, err)
+		}
+		return b.String(), nil
+	}
+
+	url, err := render(t.url)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if t.body != nil {
+		rendered, err := render(t.body)
+		if err != nil {
+			return nil, err
+		}
+		// The rendered body is not shown, since it may hold the API key
+		var syntaxErr *json.SyntaxError
+		if err := json.Unmarshal([]byte(rendered), new(json.RawMessage)); errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("%s: request.body template did not produce valid JSON at byte %d (use {{json .Prompt}} to quote strings)",
+				t.name, syntaxErr.Offset)
+		}
+		body = bytes.NewReader([]byte(rendered))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, t.method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for name, tmpl := range t.headers {
+		value, err := render(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set(name, value)
+	}
+
+	return httpReq, nil
+}
+
+// decodeImage returns the image a response value refers to: a URL to
+// download, a data URL or raw base64
+func (t *HTTPTemplate) decodeImage(ctx context.Context, s string) ([]byte, string, error) {
+	if strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://") {
+		return t.downloadImage(ctx, s)
+	}
+
+	if strings.HasPrefix(s, "data:") {
+		_, payload, ok := strings.Cut(s, ",")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid data URL")
+		}
+		s = payload
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, t.detectFormat(data), nil
+}
+
+func (t *HTTPTemplate) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := t.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, t.detectFormat(data), nil
+}
+
+// detectFormat returns the configured format, or sniffs it from the data
+func (t *HTTPTemplate) detectFormat(data []byte) string {
+	if t.format != "" {
+		return t.format
+	}
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/webp":
+		return "webp"
+	}
+	return "png"
+}
+
+func (t *HTTPTemplate) extractModelName(model string) string {
+	return strings.TrimPrefix(model, t.name+"/")
+}
+
+// classifyError maps a failed response to an APIError, taking the message
+// from response.error when the template sets it
+func (t *HTTPTemplate) classifyError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   t.name,
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var doc any
+	if t.errorPath != nil && json.Unmarshal(body, &doc) == nil {
+		if values := t.errorPath.find(doc); len(values) > 0 {
+			apiErr.Message = fmt.Sprint(values[0])
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+		if len(apiErr.Message) > 200 {
+			apiErr.Message = apiErr.Message[:200] + "..."
+		}
+	}
+
+	if apiErr.Kind == ErrKindAuth {
+		apiErr.Hint = fmt.Sprintf("check api_key and the request headers in the %s template", t.name)
+	}
+
+	return apiErr
+}
@@ -0,0 +1,416 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/internal/tcauth"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const (
+	hunyuanBaseURL = "https://hunyuan.tencentcloudapi.com"
+	hunyuanService = "hunyuan"
+	hunyuanVersion = "2023-09-01"
+	hunyuanRegion  = "ap-guangzhou"
+
+	// hunyuanLiteModel is served synchronously by TextToImageLite; other
+	// models go through the SubmitHunyuanImageJob queue
+	hunyuanLiteModel = "hunyuan-image-lite"
+)
+
+// hunyuanResolutions maps aspect ratios to the resolutions Hunyuan accepts
+var hunyuanResolutions = map[string]string{
+	"1:1":  "1024:1024",
+	"3:4":  "768:1024",
+	"4:3":  "1024:768",
+	"9:16": "720:1280",
+	"16:9": "1280:720",
+	"3:5":  "768:1280",
+	"5:3":  "1280:768",
+}
+
+// Hunyuan implements the Provider interface for Tencent Hunyuan image
+// generation, authenticated with TC3-HMAC-SHA256 signed requests
+type Hunyuan struct {
+	creds        tcauth.Credentials
+	region       string
+	baseURL      string
+	httpClient   *httputil.Client
+	middleware   *httputil.Middleware // Applied before signing, not by the client
+	pollInterval time.Duration
+}
+
+// NewHunyuan creates a new Tencent Hunyuan provider
+func NewHunyuan(cfg *ProviderConfig) *Hunyuan {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = hunyuanBaseURL
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = hunyuanRegion
+	}
+
+	return &Hunyuan{
+		creds:   tcauth.Credentials{SecretID: cfg.SecretID, SecretKey: cfg.SecretKey},
+		region:  region,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+		),
+		middleware:   cfg.Middleware,
+		pollInterval: 2 * time.Second,
+	}
+}
+
+func (h *Hunyuan) Name() string {
+	return "hunyuan"
+}
+
+func (h *Hunyuan) SupportedModels() []Model {
+	return catalogModels(h.Name())
+}
+
+func (h *Hunyuan) ValidateRequest(req *generator.Request) error {
+	if h.creds.SecretID == "" || h.creds.SecretKey == "" {
+		return fmt.Errorf("Tencent Cloud credentials are required (set TENCENTCLOUD_SECRET_ID and TENCENTCLOUD_SECRET_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Hunyuan does not support seamless tiling")
+	}
+
+	if req.AspectRatio != "" {
+		if _, ok := hunyuanResolutions[req.AspectRatio]; !ok {
+			return fmt.Errorf("invalid aspect ratio %s for Hunyuan, supported: %s",
+				req.AspectRatio, strings.Join(slices.Sorted(maps.Keys(hunyuanResolutions)), ", "))
+		}
+	} else if req.Size != "" && !slices.Contains(hunyuanSizes(), req.Size) {
+		return fmt.Errorf("invalid size %s for Hunyuan, supported: %s",
+			req.Size, strings.Join(hunyuanSizes(), ", "))
+	}
+
+	return nil
+}
+
+type hunyuanJobRequest struct {
+	Prompt         string `json:"Prompt"`
+	NegativePrompt string `json:"NegativePrompt,omitempty"`
+	Resolution     string `json:"Resolution,omitempty"`
+	Num            int    `json:"Num,omitempty"`
+	Seed           *int64 `json:"Seed,omitempty"`
+	Revise         *int   `json:"Revise,omitempty"`
+	LogoAdd        int    `json:"LogoAdd"`
+}
+
+type hunyuanLiteRequest struct {
+	Prompt         string `json:"Prompt"`
+	NegativePrompt string `json:"NegativePrompt,omitempty"`
+	Resolution     string `json:"Resolution,omitempty"`
+	LogoAdd        int    `json:"LogoAdd"`
+	RspImgType     string `json:"RspImgType"`
+}
+
+type hunyuanError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+type hunyuanResponse struct {
+	Response struct {
+		Error         *hunyuanError `json:"Error"`
+		JobID         string        `json:"JobId"`
+		JobStatusCode string        `json:"JobStatusCode"`
+		JobErrorCode  string        `json:"JobErrorCode"`
+		JobErrorMsg   string        `json:"JobErrorMsg"`
+		ResultImage   any           `json:"ResultImage"` // []string for jobs, string for Lite
+		RequestID     string        `json:"RequestId"`
+	} `json:"Response"`
+}
+
+func (h *Hunyuan) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := h.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	var urls []string
+	var err error
+	if h.extractModelName(req.Model) == hunyuanLiteModel {
+		urls, err = h.generateLite(ctx, req)
+	} else {
+		urls, err = h.generateJob(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]generator.Image, 0, len(urls))
+	for i, u := range urls {
+		isURL := strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")
+		// Result URLs are valid for an hour
+		if isURL && req.URLOnly {
+			images = append(images, hostedImage(u, "png", i, time.Hour))
+			continue
+		}
+
+		var data []byte
+		var format string
+		if isURL {
+			data, format, err = h.downloadImage(ctx, u)
+		} else {
+			data, err = base64.StdEncoding.DecodeString(u)
+			format = "png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image: %w", err)
+		}
+		images = append(images, generator.Image{
+			Data:   data,
+			Format: format,
+			Index:  i,
+		})
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    h.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// generateLite calls the synchronous TextToImageLite action
+func (h *Hunyuan) generateLite(ctx context.Context, req *generator.Request) ([]string, error) {
+	if req.Count > 1 {
+		return nil, fmt.Errorf("%s returns one image per request", hunyuanLiteModel)
+	}
+
+	resp, err := h.call(ctx, "TextToImageLite", hunyuanLiteRequest{
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Resolution:     h.mapResolution(req),
+		RspImgType:     "url",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if u, ok := resp.Response.ResultImage.(string); ok && u != "" {
+		return []string{u}, nil
+	}
+	return nil, noImagesError("")
+}
+
+// generateJob submits a SubmitHunyuanImageJob and waits for its result
+func (h *Hunyuan) generateJob(ctx context.Context, req *generator.Request) ([]string, error) {
+	apiReq := hunyuanJobRequest{
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Resolution:     h.mapResolution(req),
+		Num:            req.Count,
+		Seed:           req.Seed,
+	}
+	switch req.MagicPrompt {
+	case "on":
+		revise := 1
+		apiReq.Revise = &revise
+	case "off":
+		revise := 0
+		apiReq.Revise = &revise
+	}
+
+	resp, err := h.call(ctx, "SubmitHunyuanImageJob", apiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.waitForJob(ctx, resp.Response.JobID)
+}
+
+// waitForJob polls a job until it completes or fails
+func (h *Hunyuan) waitForJob(ctx context.Context, jobID string) ([]string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-h.httpClient.Clock().After(h.pollInterval):
+		}
+
+		resp, err := h.call(ctx, "QueryHunyuanImageJob", map[string]string{"JobId": jobID})
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp.Response.JobStatusCode {
+		case "5": // Completed
+			var urls []string
+			if images, ok := resp.Response.ResultImage.([]any); ok {
+				for _, img := range images {
+					if u, ok := img.(string); ok && u != "" {
+						urls = append(urls, u)
+					}
+				}
+			}
+			if len(urls) == 0 {
+				return nil, noImagesError("")
+			}
+			return urls, nil
+		case "4": // Failed
+			return nil, classifyHunyuanError(&hunyuanError{
+				Code:    resp.Response.JobErrorCode,
+				Message: resp.Response.JobErrorMsg,
+			})
+		}
+	}
+}
+
+// call sends a signed request for an API action. Tencent Cloud reports
+// errors in the body of 200 responses.
+func (h *Hunyuan) call(ctx context.Context, action string, params any) (*hunyuanResponse, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Mutations must happen before signing, which covers the body
+	body, err = h.middleware.MutateRequest(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json; charset=utf-8")
+	tcauth.Sign(httpReq, body, &h.creds, hunyuanService, action, hunyuanVersion, h.region, h.httpClient.Clock().Now())
+
+	resp, err := h.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			Provider:   "Hunyuan",
+			Kind:       kindFromStatus(resp.StatusCode),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	if respBody, err = h.middleware.MutateResponse(respBody); err != nil {
+		return nil, err
+	}
+
+	var apiResp hunyuanResponse
+	if err := decodeTolerant("Hunyuan", respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if apiResp.Response.Error != nil {
+		return nil, classifyHunyuanError(apiResp.Response.Error)
+	}
+
+	return &apiResp, nil
+}
+
+// mapResolution returns the Hunyuan resolution for a request, preferring
+// the aspect ratio over the size
+func (h *Hunyuan) mapResolution(req *generator.Request) string {
+	if res, ok := hunyuanResolutions[req.AspectRatio]; ok {
+		return res
+	}
+	if req.Size != "" {
+		return strings.Replace(req.Size, "x", ":", 1)
+	}
+	return ""
+}
+
+func (h *Hunyuan) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := h.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (h *Hunyuan) extractModelName(model string) string {
+	return strings.TrimPrefix(model, h.Name()+"/")
+}
+
+// hunyuanSizes returns the accepted resolutions in WxH form
+func hunyuanSizes() []string {
+	sizes := make([]string, 0, len(hunyuanResolutions))
+	for _, res := range hunyuanResolutions {
+		sizes = append(sizes, strings.Replace(res, ":", "x", 1))
+	}
+	slices.Sort(sizes)
+	return sizes
+}
+
+// classifyHunyuanError maps a Tencent Cloud error code to an APIError
+func classifyHunyuanError(e *hunyuanError) *APIError {
+	apiErr := &APIError{
+		Provider: "Hunyuan",
+		Kind:     ErrKindUnknown,
+		Code:     e.Code,
+		Message:  e.Message,
+	}
+
+	switch {
+	case strings.HasPrefix(e.Code, "AuthFailure"):
+		apiErr.Kind = ErrKindAuth
+		apiErr.Hint = "check TENCENTCLOUD_SECRET_ID/TENCENTCLOUD_SECRET_KEY or providers.hunyuan.secret_id/secret_key"
+	case strings.Contains(e.Code, "LimitExceeded"):
+		apiErr.Kind = ErrKindRateLimit
+	case strings.Contains(e.Code, "InArrears") || strings.Contains(e.Code, "ResourcePackExhausted") ||
+		strings.HasPrefix(e.Code, "ResourcesSoldOut"):
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "enable Hunyuan image generation and check the account balance in the Tencent Cloud console"
+	case strings.Contains(e.Code, "Illegal") || strings.Contains(e.Code, "AuditFailed"):
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = "rephrase the prompt"
+	case strings.HasPrefix(e.Code, "InvalidParameter") || strings.HasPrefix(e.Code, "MissingParameter"):
+		apiErr.Kind = ErrKindInvalidRequest
+	case strings.HasPrefix(e.Code, "InternalError"):
+		apiErr.Kind = ErrKindServer
+	}
+
+	return apiErr
+}
@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const ideogramBaseURL = "https://api.ideogram.ai"
+
+// Ideogram style types and magic prompt options
+var (
+	ideogramStyleTypes   = []string{"AUTO", "GENERAL", "REALISTIC", "DESIGN", "FICTION"}
+	ideogramMagicPrompts = []string{"AUTO", "ON", "OFF"}
+)
+
+// Ideogram implements the Provider interface for Ideogram, which is
+// strongest at rendering text inside images
+type Ideogram struct {
+	apiKey     string
+	baseURL    string
+	httpClient *httputil.Client
+}
+
+// NewIdeogram creates a new Ideogram provider
+func NewIdeogram(cfg *ProviderConfig) *Ideogram {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = ideogramBaseURL
+	}
+
+	return &Ideogram{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+	}
+}
+
+func (i *Ideogram) Name() string {
+	return "ideogram"
+}
+
+func (i *Ideogram) SupportedModels() []Model {
+	return catalogModels(i.Name())
+}
+
+func (i *Ideogram) ValidateRequest(req *generator.Request) error {
+	if i.apiKey == "" {
+		return fmt.Errorf("Ideogram API key is required (set IDEOGRAM_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Ideogram does not support seamless tiling")
+	}
+
+	if req.StyleType != "" && !slices.Contains(ideogramStyleTypes, strings.ToUpper(req.StyleType)) {
+		return fmt.Errorf("invalid style type %s, valid: %s", req.StyleType, strings.Join(ideogramStyleTypes, ", "))
+	}
+
+	if req.MagicPrompt != "" && !slices.Contains(ideogramMagicPrompts, strings.ToUpper(req.MagicPrompt)) {
+		return fmt.Errorf("invalid magic prompt option %s, valid: auto, on, off", req.MagicPrompt)
+	}
+
+	if req.Count > 8 {
+		return fmt.Errorf("Ideogram returns at most 8 images per request")
+	}
+
+	return nil
+}
+
+type ideogramResponse struct {
+	Created string `json:"created"`
+	Data    []struct {
+		Prompt      string `json:"prompt"`
+		Resolution  string `json:"resolution"`
+		IsImageSafe bool   `json:"is_image_safe"`
+		Seed        int64  `json:"seed"`
+		URL         string `json:"url"`
+		StyleType   string `json:"style_type"`
+	} `json:"data"`
+}
+
+// AcceptsStyleImage reports true; Ideogram 3.0 takes style reference
+// images with every request
+func (i *Ideogram) AcceptsStyleImage(model string) bool {
+	return true
+}
+
+func (i *Ideogram) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := i.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("prompt", req.Prompt)
+	writer.WriteField("rendering_speed", ideogramRenderingSpeed(req.Quality))
+
+	if req.NegativePrompt != "" {
+		writer.WriteField("negative_prompt", req.NegativePrompt)
+	}
+
+	// Aspect ratio and resolution are mutually exclusive
+	if req.AspectRatio != "" {
+		writer.WriteField("aspect_ratio", strings.ReplaceAll(req.AspectRatio, ":", "x"))
+	} else if req.Size != "" {
+		writer.WriteField("resolution", req.Size)
+	}
+
+	if req.Seed != nil {
+		writer.WriteField("seed", strconv.FormatInt(*req.Seed, 10))
+	}
+
+	if req.Count > 1 {
+		writer.WriteField("num_images", strconv.Itoa(req.Count))
+	}
+
+	if req.StyleType != "" {
+		writer.WriteField("style_type", strings.ToUpper(req.StyleType))
+	}
+
+	if req.MagicPrompt != "" {
+		writer.WriteField("magic_prompt", strings.ToUpper(req.MagicPrompt))
+	}
+
+	if req.StyleImage != nil {
+		if err := writeFormImage(writer, "style_reference_images", *req.StyleImage); err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+	}
+
+	writer.Close()
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		i.baseURL+"/v1/ideogram-v3/generate",
+		&body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Api-Key", i.apiKey)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := i.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyIdeogramError(resp.StatusCode, respBody)
+	}
+
+	var apiResp ideogramResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Data))
+	revisedPrompt := ""
+	for _, d := range apiResp.Data {
+		// Unsafe images are returned without a URL
+		if !d.IsImageSafe || d.URL == "" {
+			continue
+		}
+
+		// Image URLs expire, so images are downloaded right away
+		image := hostedImage(d.URL, "png", len(images), 0)
+		if !req.URLOnly {
+			data, err := i.downloadImage(ctx, d.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image: %w", err)
+			}
+			image = generator.Image{Data: data, Format: "png", Index: len(images)}
+		}
+
+		seed := d.Seed
+		image.Width, image.Height = parseSize(d.Resolution)
+		image.Seed = &seed
+		images = append(images, image)
+		if revisedPrompt == "" && d.Prompt != req.Prompt {
+			revisedPrompt = d.Prompt
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, &APIError{
+			Provider: "Ideogram",
+			Kind:     ErrKindContentPolicy,
+			Message:  "all generated images were flagged as unsafe",
+			Hint:     ideogramSafetyHint,
+		}
+	}
+
+	return &generator.Response{
+		Images:        images,
+		Model:         req.Model,
+		Provider:      i.Name(),
+		RevisedPrompt: revisedPrompt,
+		GeneratedAt:   time.Now(),
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+func (i *Ideogram) downloadImage(ctx context.Context, url string) ([]byte, error) {
+	resp, err := i.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image download returned status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// ideogramRenderingSpeed maps the quality option to a rendering speed
+func ideogramRenderingSpeed(quality string) string {
+	switch quality {
+	case "low":
+		return "TURBO"
+	case "high", "hd":
+		return "QUALITY"
+	default:
+		return "DEFAULT"
+	}
+}
+
+const ideogramSafetyHint = "the prompt or result failed Ideogram's safety check; rephrase the prompt"
+
+// classifyIdeogramError maps an Ideogram error response to an APIError
+func classifyIdeogramError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Ideogram",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Error  string `json:"error"`
+		Detail any    `json:"detail"`
+	}
+	json.Unmarshal(body, &apiResp)
+	apiErr.Message = apiResp.Error
+	if apiErr.Message == "" {
+		if detail, ok := apiResp.Detail.(string); ok {
+			apiErr.Message = detail
+		}
+	}
+
+	switch {
+	case status == http.StatusUnprocessableEntity && strings.Contains(strings.ToLower(apiErr.Message), "safety"):
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = ideogramSafetyHint
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check IDEOGRAM_API_KEY or providers.ideogram.api_key"
+	case apiErr.Kind == ErrKindQuota:
+		apiErr.Hint = "your Ideogram API balance is exhausted; top up at https://ideogram.ai/manage-api"
+	}
+
+	return apiErr
+}
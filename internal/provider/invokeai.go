@@ -0,0 +1,558 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const (
+	// invokeaiBaseURL is the default address of the InvokeAI web server
+	invokeaiBaseURL = "http://localhost:9090"
+
+	// invokeaiQueue is the session queue used by the InvokeAI UI
+	invokeaiQueue = "default"
+
+	// invokeaiMaxImages bounds the queue items enqueued per request
+	invokeaiMaxImages = 16
+)
+
+// invokeaiSchedulers are the scheduler names accepted by denoise_latents
+var invokeaiSchedulers = []string{
+	"ddim", "ddpm", "deis", "lms", "lms_k", "pndm", "heun", "heun_k",
+	"euler", "euler_k", "euler_a", "kdpm_2", "kdpm_2_a", "dpmpp_2s",
+	"dpmpp_2s_k", "dpmpp_2m", "dpmpp_2m_k", "dpmpp_2m_sde", "dpmpp_2m_sde_k",
+	"dpmpp_sde", "dpmpp_sde_k", "unipc", "unipc_k", "lcm", "tcd",
+}
+
+// InvokeAI implements the Provider interface for a local InvokeAI server.
+// Requests are enqueued as a text-to-image graph with one queue item per
+// image, polled until done, then the images are fetched from the gallery.
+// Models are the main models installed on the server.
+type InvokeAI struct {
+	baseURL      string
+	httpClient   *httputil.Client
+	pollInterval time.Duration
+	maxWait      time.Duration
+}
+
+// NewInvokeAI creates a new InvokeAI provider
+func NewInvokeAI(cfg *ProviderConfig) *InvokeAI {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = invokeaiBaseURL
+	}
+	maxWait := cfg.MaxWait
+	if maxWait <= 0 {
+		maxWait = 10 * time.Minute
+	}
+
+	return &InvokeAI{
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+		pollInterval: time.Second,
+		maxWait:      maxWait,
+	}
+}
+
+func (ia *InvokeAI) Name() string {
+	return "invokeai"
+}
+
+func (ia *InvokeAI) SupportedModels() []Model {
+	return catalogModels(ia.Name())
+}
+
+// SupportedParams returns the provider-specific options read from
+// Request.Params
+func (ia *InvokeAI) SupportedParams() []string {
+	return []string{"cfg_scale", "scheduler"}
+}
+
+func (ia *InvokeAI) ValidateRequest(req *generator.Request) error {
+	if ia.extractModelName(req.Model) == "" {
+		return fmt.Errorf("InvokeAI needs the name of an installed model, e.g. invokeai/dreamshaper-8")
+	}
+
+	if req.Count > invokeaiMaxImages {
+		return fmt.Errorf("InvokeAI generates at most %d images per request", invokeaiMaxImages)
+	}
+
+	if req.Size != "" {
+		width, height, err := splitSize(req.Size)
+		if err != nil {
+			return err
+		}
+		if width%8 != 0 || height%8 != 0 || width < 64 || height < 64 {
+			return fmt.Errorf("invalid size %s: width and height must be multiples of 8, at least 64", req.Size)
+		}
+	}
+
+	_, _, err := ia.denoiseSettings(req)
+	return err
+}
+
+// invokeaiModel is a model record of the model manager, which the graph
+// references by key
+type invokeaiModel struct {
+	Key  string `json:"key"`
+	Hash string `json:"hash"`
+	Name string `json:"name"`
+	Base string `json:"base"`
+	Type string `json:"type"`
+}
+
+type invokeaiEdge struct {
+	Source      invokeaiEdgePoint `json:"source"`
+	Destination invokeaiEdgePoint `json:"destination"`
+}
+
+type invokeaiEdgePoint struct {
+	NodeID string `json:"node_id"`
+	Field  string `json:"field"`
+}
+
+type invokeaiQueueItem struct {
+	ItemID       int    `json:"item_id"`
+	Status       string `json:"status"`
+	ErrorType    string `json:"error_type"`
+	ErrorMessage string `json:"error_message"`
+	Session      struct {
+		Results map[string]struct {
+			Type  string `json:"type"`
+			Image *struct {
+				ImageName string `json:"image_name"`
+			} `json:"image"`
+		} `json:"results"`
+	} `json:"session"`
+}
+
+// denoiseSettings returns the CFG scale and scheduler from the params
+func (ia *InvokeAI) denoiseSettings(req *generator.Request) (float64, string, error) {
+	cfgScale, scheduler := 7.5, "euler"
+	for key, value := range req.Params {
+		switch key {
+		case "cfg_scale":
+			scale, err := strconv.ParseFloat(value, 64)
+			if err != nil || scale < 1 || scale > 30 {
+				return 0, "", fmt.Errorf("invalid cfg_scale %q, expected a number between 1 and 30", value)
+			}
+			cfgScale = scale
+		case "scheduler":
+			if !slices.Contains(invokeaiSchedulers, value) {
+				return 0, "", fmt.Errorf("invalid scheduler %q, supported: %s", value, strings.Join(invokeaiSchedulers, ", "))
+			}
+			scheduler = value
+		default:
+			return 0, "", fmt.Errorf("unsupported InvokeAI param %q, supported: %s",
+				key, strings.Join(ia.SupportedParams(), ", "))
+		}
+	}
+	return cfgScale, scheduler, nil
+}
+
+func (ia *InvokeAI) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := ia.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	model, err := ia.findModel(ctx, ia.extractModelName(req.Model))
+	if err != nil {
+		return nil, err
+	}
+
+	count := max(req.Count, 1)
+	seeds := make([]int64, count)
+	for i := range seeds {
+		if req.Seed != nil {
+			seeds[i] = *req.Seed + int64(i)
+		} else {
+			seeds[i] = rand.Int64N(1 << 32)
+		}
+	}
+
+	graph, err := ia.buildGraph(req, model)
+	if err != nil {
+		return nil, err
+	}
+
+	// One queue item per seed
+	body, err := json.Marshal(map[string]any{
+		"prepend": false,
+		"batch": map[string]any{
+			"graph": graph,
+			"runs":  1,
+			"data": [][]map[string]any{{{
+				"node_path":  "noise",
+				"field_name": "seed",
+				"items":      seeds,
+			}}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respBody, err := ia.call(ctx, http.MethodPost, "/api/v1/queue/"+invokeaiQueue+"/enqueue_batch", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var enqueued struct {
+		ItemIDs []int `json:"item_ids"`
+	}
+	if err := decodeTolerant("InvokeAI", respBody, &enqueued); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(enqueued.ItemIDs) == 0 {
+		return nil, fmt.Errorf("InvokeAI did not return queue item IDs; InvokeAI 5 or newer is required")
+	}
+
+	images := make([]generator.Image, 0, len(enqueued.ItemIDs))
+	for i, id := range enqueued.ItemIDs {
+		item, err := ia.waitForItem(ctx, id, enqueued.ItemIDs[i:])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range item.Session.Results {
+			if result.Type != "image_output" || result.Image == nil {
+				continue
+			}
+			data, err := ia.call(ctx, http.MethodGet, "/api/v1/images/i/"+result.Image.ImageName+"/full", nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image: %w", err)
+			}
+			seed := seeds[i]
+			images = append(images, generator.Image{
+				Data:   data,
+				Format: "png",
+				Seed:   &seed,
+				Index:  len(images),
+			})
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError("")
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    ia.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// buildGraph returns the text-to-image graph for an SD 1.x/2.x or SDXL
+// main model: model loader, prompt conditioning, noise, denoise and
+// latents-to-image nodes
+func (ia *InvokeAI) buildGraph(req *generator.Request, model *invokeaiModel) (map[string]any, error) {
+	cfgScale, scheduler, err := ia.denoiseSettings(req)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := 512, 512
+	if model.Base == "sdxl" {
+		width, height = 1024, 1024
+	}
+	if req.Size != "" {
+		width, height = parseSize(req.Size)
+	}
+	steps := req.Steps
+	if steps <= 0 {
+		steps = 30
+	}
+
+	ref := map[string]any{
+		"key": model.Key, "hash": model.Hash, "name": model.Name,
+		"base": model.Base, "type": model.Type,
+	}
+
+	nodes := map[string]any{
+		"noise": map[string]any{
+			"type": "noise", "id": "noise",
+			"width": width, "height": height, "use_cpu": true,
+		},
+		"denoise": map[string]any{
+			"type": "denoise_latents", "id": "denoise",
+			"steps": steps, "cfg_scale": cfgScale, "scheduler": scheduler,
+			"denoising_start": 0, "denoising_end": 1,
+		},
+		"l2i": map[string]any{
+			"type": "l2i", "id": "l2i", "is_intermediate": false,
+		},
+	}
+	// The unet and vae pass through a seamless node for tiling textures
+	unetVAE := "model_loader"
+	if req.Tile {
+		nodes["seamless"] = map[string]any{
+			"type": "seamless", "id": "seamless",
+			"seamless_x": true, "seamless_y": true,
+		}
+		unetVAE = "seamless"
+	}
+	edges := []invokeaiEdge{
+		invokeaiLink(unetVAE, "unet", "denoise", "unet"),
+		invokeaiLink(unetVAE, "vae", "l2i", "vae"),
+		invokeaiLink("positive", "conditioning", "denoise", "positive_conditioning"),
+		invokeaiLink("negative", "conditioning", "denoise", "negative_conditioning"),
+		invokeaiLink("noise", "noise", "denoise", "noise"),
+		invokeaiLink("denoise", "latents", "l2i", "latents"),
+	}
+	if req.Tile {
+		edges = append(edges,
+			invokeaiLink("model_loader", "unet", "seamless", "unet"),
+			invokeaiLink("model_loader", "vae", "seamless", "vae"),
+		)
+	}
+
+	switch model.Base {
+	case "sd-1", "sd-2":
+		nodes["model_loader"] = map[string]any{"type": "main_model_loader", "id": "model_loader", "model": ref}
+		nodes["positive"] = map[string]any{"type": "compel", "id": "positive", "prompt": req.Prompt}
+		nodes["negative"] = map[string]any{"type": "compel", "id": "negative", "prompt": req.NegativePrompt}
+		edges = append(edges,
+			invokeaiLink("model_loader", "clip", "positive", "clip"),
+			invokeaiLink("model_loader", "clip", "negative", "clip"),
+		)
+	case "sdxl":
+		nodes["model_loader"] = map[string]any{"type": "sdxl_model_loader", "id": "model_loader", "model": ref}
+		for id, prompt := range map[string]string{"positive": req.Prompt, "negative": req.NegativePrompt} {
+			nodes[id] = map[string]any{
+				"type": "sdxl_compel_prompt", "id": id,
+				"prompt": prompt, "style": prompt,
+				"original_width": width, "original_height": height,
+				"target_width": width, "target_height": height,
+				"crop_top": 0, "crop_left": 0,
+			}
+			edges = append(edges,
+				invokeaiLink("model_loader", "clip", id, "clip"),
+				invokeaiLink("model_loader", "clip2", id, "clip2"),
+			)
+		}
+	default:
+		return nil, fmt.Errorf("InvokeAI model %s has base %s; only SD 1.x, SD 2.x and SDXL models are supported",
+			model.Name, model.Base)
+	}
+
+	return map[string]any{"id": "llm-imager", "nodes": nodes, "edges": edges}, nil
+}
+
+func invokeaiLink(source, sourceField, destination, destinationField string) invokeaiEdge {
+	return invokeaiEdge{
+		Source:      invokeaiEdgePoint{NodeID: source, Field: sourceField},
+		Destination: invokeaiEdgePoint{NodeID: destination, Field: destinationField},
+	}
+}
+
+// InvokeAIModel is a main model installed on an InvokeAI server
+type InvokeAIModel struct {
+	ID   string
+	Name string
+	Base string
+}
+
+// FetchModels lists the main models installed on the server
+func (ia *InvokeAI) FetchModels(ctx context.Context) ([]InvokeAIModel, error) {
+	installed, err := ia.installedModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]InvokeAIModel, 0, len(installed))
+	for _, m := range installed {
+		models = append(models, InvokeAIModel{ID: ia.Name() + "/" + m.Name, Name: m.Name, Base: m.Base})
+	}
+	return models, nil
+}
+
+func (ia *InvokeAI) installedModels(ctx context.Context) ([]invokeaiModel, error) {
+	respBody, err := ia.call(ctx, http.MethodGet, "/api/v2/models/?model_type=main", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Models []invokeaiModel `json:"models"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode models: %w", err)
+	}
+	return result.Models, nil
+}
+
+// findModel looks up an installed main model by name or key
+func (ia *InvokeAI) findModel(ctx context.Context, name string) (*invokeaiModel, error) {
+	installed, err := ia.installedModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range installed {
+		if strings.EqualFold(m.Name, name) || m.Key == name {
+			return &installed[i], nil
+		}
+	}
+	return nil, &APIError{
+		Provider: "InvokeAI",
+		Kind:     ErrKindNotFound,
+		Message:  fmt.Sprintf("model %q is not installed", name),
+		Hint:     "list the installed models with 'llm-imager list models -p invokeai --remote'",
+	}
+}
+
+// waitForItem polls a queue item until it completes. On failure or
+// timeout the remaining items of the request are cancelled so the server
+// does not keep generating them.
+func (ia *InvokeAI) waitForItem(ctx context.Context, id int, pending []int) (*invokeaiQueueItem, error) {
+	deadline := ia.httpClient.Clock().Now().Add(ia.maxWait)
+	path := fmt.Sprintf("/api/v1/queue/%s/i/%d", invokeaiQueue, id)
+
+	for {
+		respBody, err := ia.call(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var item invokeaiQueueItem
+		if err := decodeTolerant("InvokeAI", respBody, &item); err != nil {
+			return nil, fmt.Errorf("failed to decode queue item: %w", err)
+		}
+
+		switch item.Status {
+		case "completed":
+			return &item, nil
+		case "failed":
+			ia.cancel(pending[1:])
+			return nil, &APIError{
+				Provider: "InvokeAI",
+				Kind:     ErrKindServer,
+				Code:     item.ErrorType,
+				Message:  item.ErrorMessage,
+			}
+		case "canceled":
+			ia.cancel(pending[1:])
+			return nil, fmt.Errorf("InvokeAI queue item %d was canceled", id)
+		}
+
+		if ia.httpClient.Clock().Now().After(deadline) {
+			ia.cancel(pending)
+			return nil, fmt.Errorf("InvokeAI queue item %d not finished after %s (status %s); "+
+				"raise providers.invokeai.max_wait", id, ia.maxWait, item.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			ia.cancel(pending)
+			return nil, ctx.Err()
+		case <-ia.httpClient.Clock().After(ia.pollInterval):
+		}
+	}
+}
+
+// cancel cancels queue items; errors are ignored since this only saves
+// work on the server
+func (ia *InvokeAI) cancel(ids []int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, id := range ids {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut,
+			fmt.Sprintf("%s/api/v1/queue/%s/i/%d/cancel", ia.baseURL, invokeaiQueue, id), nil)
+		if err != nil {
+			return
+		}
+		httputil.ApplyUserAgent(httpReq)
+		if resp, err := http.DefaultClient.Do(httpReq); err == nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// call sends a request to the InvokeAI API and returns the response body
+func (ia *InvokeAI) call(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, ia.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := ia.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach InvokeAI at %s (set providers.invokeai.base_url): %w", ia.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyInvokeAIError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+func (ia *InvokeAI) extractModelName(model string) string {
+	return strings.TrimPrefix(model, ia.Name()+"/")
+}
+
+// classifyInvokeAIError maps an InvokeAI error response to an APIError.
+// FastAPI reports errors as {"detail": "..."} or, for validation errors,
+// a list of {"loc": [...], "msg": "..."}.
+func classifyInvokeAIError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "InvokeAI",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Detail any `json:"detail"`
+	}
+	json.Unmarshal(body, &apiResp)
+	switch detail := apiResp.Detail.(type) {
+	case string:
+		apiErr.Message = detail
+	case []any:
+		var msgs []string
+		for _, d := range detail {
+			if m, ok := d.(map[string]any); ok {
+				msgs = append(msgs, fmt.Sprint(m["msg"]))
+			}
+		}
+		apiErr.Message = strings.Join(msgs, "; ")
+	}
+
+	if status == http.StatusUnprocessableEntity {
+		apiErr.Kind = ErrKindInvalidRequest
+		apiErr.Hint = "the graph was rejected; this InvokeAI version may use different node fields"
+	}
+
+	return apiErr
+}
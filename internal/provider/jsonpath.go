@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath expression supporting the subset needed
+// to point at images in a response: $, .key, ['key'], ['a','b'], [n],
+// [*], .* and ..key (recursive descent)
+type jsonPath struct {
+	expr  string
+	steps []pathStep
+}
+
+type pathStep struct {
+	keys      []string // Object keys, empty for index and wildcard steps
+	index     int      // Array index, negative counts from the end
+	isIndex   bool
+	wildcard  bool
+	recursive bool // Match key at any depth below the current nodes
+}
+
+// compileJSONPath parses a JSONPath expression
+func compileJSONPath(expr string) (*jsonPath, error) {
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+	s = s[1:]
+
+	p := &jsonPath{expr: expr}
+	for s != "" {
+		var step pathStep
+		switch {
+		case strings.HasPrefix(s, ".."):
+			s = s[2:]
+			step.recursive = true
+			name := pathName(s)
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: .. must be followed by a key", expr)
+			}
+			step.keys, s = []string{name}, s[len(name):]
+		case strings.HasPrefix(s, "."):
+			s = s[1:]
+			if strings.HasPrefix(s, "*") {
+				step.wildcard, s = true, s[1:]
+				break
+			}
+			name := pathName(s)
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key", expr)
+			}
+			step.keys, s = []string{name}, s[len(name):]
+		case strings.HasPrefix(s, "["):
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unclosed [", expr)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case inner == "*":
+				step.wildcard = true
+			case inner != "" && (inner[0] == '\'' || inner[0] == '"'):
+				for _, part := range strings.Split(inner, ",") {
+					part = strings.TrimSpace(part)
+					if len(part) < 2 || (part[0] != '\'' && part[0] != '"') || part[len(part)-1] != part[0] {
+						return nil, fmt.Errorf("invalid JSONPath %q: bad key [%s]", expr, inner)
+					}
+					step.keys = append(step.keys, part[1:len(part)-1])
+				}
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSONPath %q: bad subscript [%s]", expr, inner)
+				}
+				step.index, step.isIndex = n, true
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q at %q", expr, s)
+		}
+		p.steps = append(p.steps, step)
+	}
+	return p, nil
+}
+
+// pathName returns the key at the start of s, up to the next . or [
+func pathName(s string) string {
+	if i := strings.IndexAny(s, ".["); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// find returns the values matched by the path in a decoded JSON document
+func (p *jsonPath) find(doc any) []any {
+	nodes := []any{doc}
+	for _, step := range p.steps {
+		var next []any
+		for _, node := range nodes {
+			next = append(next, step.apply(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (s pathStep) apply(node any) []any {
+	if s.recursive {
+		var found []any
+		var walk func(v any)
+		walk = func(v any) {
+			switch n := v.(type) {
+			case map[string]any:
+				for _, k := range slices.Sorted(maps.Keys(n)) {
+					if k == s.keys[0] {
+						found = append(found, n[k])
+					}
+					walk(n[k])
+				}
+			case []any:
+				for _, item := range n {
+					walk(item)
+				}
+			}
+		}
+		walk(node)
+		return found
+	}
+
+	switch n := node.(type) {
+	case map[string]any:
+		if s.wildcard {
+			var values []any
+			for _, k := range slices.Sorted(maps.Keys(n)) {
+				values = append(values, n[k])
+			}
+			return values
+		}
+		var values []any
+		for _, key := range s.keys {
+			if v, ok := n[key]; ok {
+				values = append(values, v)
+			}
+		}
+		return values
+	case []any:
+		if s.wildcard {
+			return n
+		}
+		if s.isIndex {
+			i := s.index
+			if i < 0 {
+				i += len(n)
+			}
+			if i >= 0 && i < len(n) {
+				return []any{n[i]}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// localaiBaseURL is the default address of a LocalAI server
+const localaiBaseURL = "http://localhost:8080/v1"
+
+// LocalAI implements the Provider interface for self-hosted servers that
+// speak the OpenAI-compatible images API, such as LocalAI. Models are the
+// names configured on the server, so none are listed in the catalog.
+type LocalAI struct {
+	apiKey     string
+	baseURL    string
+	httpClient *httputil.Client
+}
+
+// NewLocalAI creates a new LocalAI provider. The API key is optional and
+// only sent when the server is started with one.
+func NewLocalAI(cfg *ProviderConfig) *LocalAI {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = localaiBaseURL
+	}
+
+	opts := []httputil.ClientOption{
+		httputil.WithRetries(cfg.MaxRetries),
+		httputil.WithMiddleware(cfg.Middleware),
+		httputil.WithTransport(cfg.Transport),
+		httputil.WithClock(cfg.Clock),
+	}
+	// Generation on local hardware often takes longer than the default
+	// timeout
+	if cfg.Timeout > 0 {
+		opts = append(opts, httputil.WithTimeout(cfg.Timeout))
+	}
+
+	return &LocalAI{
+		apiKey:     cfg.APIKey,
+		baseURL:    baseURL,
+		httpClient: httputil.NewClient(opts...),
+	}
+}
+
+func (l *LocalAI) Name() string {
+	return "localai"
+}
+
+func (l *LocalAI) SupportedModels() []Model {
+	return catalogModels(l.Name())
+}
+
+func (l *LocalAI) ValidateRequest(req *generator.Request) error {
+	if l.extractModelName(req.Model) == "" {
+		return fmt.Errorf("LocalAI needs a model name configured on the server, e.g. localai/stablediffusion")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("LocalAI does not support seamless tiling")
+	}
+
+	if req.Size != "" {
+		if _, _, err := splitSize(req.Size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type localaiImageRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`
+	Step           int    `json:"step,omitempty"`
+	Seed           *int64 `json:"seed,omitempty"`
+	ResponseFormat string `json:"response_format"`
+}
+
+type localaiImageResponse struct {
+	Data []struct {
+		URL     string `json:"url"`
+		B64JSON string `json:"b64_json"`
+	} `json:"data"`
+	Error *struct {
+		Code    any    `json:"code"` // A number on LocalAI, a string on other servers
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (l *LocalAI) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := l.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	// LocalAI takes the negative prompt after a "|" separator
+	prompt := req.Prompt
+	if req.NegativePrompt != "" {
+		prompt += "|" + req.NegativePrompt
+	}
+
+	apiReq := localaiImageRequest{
+		Model:          l.extractModelName(req.Model),
+		Prompt:         prompt,
+		N:              req.Count,
+		Size:           req.Size,
+		Step:           req.Steps,
+		Seed:           req.Seed,
+		ResponseFormat: "b64_json",
+	}
+	if req.URLOnly {
+		apiReq.ResponseFormat = "url"
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		l.baseURL+"/images/generations",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if l.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+l.apiKey)
+	}
+
+	resp, err := l.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach LocalAI at %s (set providers.localai.base_url): %w", l.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyLocalAIError(resp.StatusCode, respBody)
+	}
+
+	var apiResp localaiImageResponse
+	if err := decodeTolerant("LocalAI", respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Data))
+	for i, item := range apiResp.Data {
+		switch {
+		case item.B64JSON != "":
+			data, err := base64.StdEncoding.DecodeString(item.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image: %w", err)
+			}
+			images = append(images, generator.Image{
+				Data:   data,
+				Format: "png",
+				Seed:   req.Seed,
+				Index:  i,
+			})
+		case item.URL != "" && req.URLOnly:
+			image := hostedImage(item.URL, "png", i, 0)
+			image.Seed = req.Seed
+			images = append(images, image)
+		case item.URL != "":
+			data, format, err := l.downloadImage(ctx, item.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image: %w", err)
+			}
+			images = append(images, generator.Image{
+				Data:   data,
+				Format: format,
+				Seed:   req.Seed,
+				Index:  i,
+			})
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError("")
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    l.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// LocalAIModel is a model installed on a LocalAI server
+type LocalAIModel struct {
+	ID string
+}
+
+// FetchModels lists the models installed on the server. The list includes
+// text and audio models, since the API does not tell them apart.
+func (l *LocalAI) FetchModels(ctx context.Context) ([]LocalAIModel, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, l.baseURL+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	if l.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+l.apiKey)
+	}
+
+	resp, err := l.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach LocalAI at %s (set providers.localai.base_url): %w", l.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyLocalAIError(resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode models: %w", err)
+	}
+
+	models := make([]LocalAIModel, 0, len(result.Data))
+	for _, m := range result.Data {
+		models = append(models, LocalAIModel{ID: l.Name() + "/" + m.ID})
+	}
+	return models, nil
+}
+
+func (l *LocalAI) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := l.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (l *LocalAI) extractModelName(model string) string {
+	return strings.TrimPrefix(model, l.Name()+"/")
+}
+
+// classifyLocalAIError maps an error response of the server to an APIError
+func classifyLocalAIError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "LocalAI",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp localaiImageResponse
+	json.Unmarshal(body, &apiResp)
+	if apiResp.Error != nil {
+		apiErr.Message = apiResp.Error.Message
+		if apiResp.Error.Code != nil {
+			apiErr.Code = fmt.Sprint(apiResp.Error.Code)
+		}
+	}
+
+	switch {
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "the server requires an API key; set LOCALAI_API_KEY or providers.localai.api_key"
+	case apiErr.Kind == ErrKindNotFound,
+		strings.Contains(strings.ToLower(apiErr.Message), "could not load model"):
+		apiErr.Kind = ErrKindNotFound
+		apiErr.Hint = "check the model name with 'llm-imager list models -p localai --remote'"
+	}
+
+	return apiErr
+}
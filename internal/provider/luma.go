@@ -0,0 +1,432 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const lumaBaseURL = "https://api.lumalabs.ai/dream-machine/v1"
+
+// lumaAspectRatios are the aspect ratios accepted by Photon models
+var lumaAspectRatios = []string{"1:1", "3:4", "4:3", "9:16", "16:9", "9:21", "21:9"}
+
+// lumaMaxImageRefs is the maximum number of reference images per request
+const lumaMaxImageRefs = 4
+
+// lumaImageRefWeight is how strongly reference images guide generation
+const lumaImageRefWeight = 0.85
+
+// Luma implements the Provider interface for the Luma Dream Machine API,
+// which serves the Photon image models
+type Luma struct {
+	apiKey       string
+	baseURL      string
+	httpClient   *httputil.Client
+	pollInterval time.Duration
+}
+
+// NewLuma creates a new Luma provider
+func NewLuma(cfg *ProviderConfig) *Luma {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = lumaBaseURL
+	}
+
+	return &Luma{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+		pollInterval: 2 * time.Second,
+	}
+}
+
+func (l *Luma) Name() string {
+	return "luma"
+}
+
+func (l *Luma) SupportedModels() []Model {
+	return catalogModels(l.Name())
+}
+
+func (l *Luma) ValidateRequest(req *generator.Request) error {
+	if l.apiKey == "" {
+		return fmt.Errorf("Luma API key is required (set LUMAAI_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Luma does not support seamless tiling")
+	}
+
+	if req.Count > 1 {
+		return fmt.Errorf("Luma returns one image per request")
+	}
+
+	if req.AspectRatio != "" && !slices.Contains(lumaAspectRatios, req.AspectRatio) {
+		return fmt.Errorf("invalid aspect ratio %s for Luma, supported: %s",
+			req.AspectRatio, strings.Join(lumaAspectRatios, ", "))
+	}
+
+	if len(req.ImageRefs) > lumaMaxImageRefs {
+		return fmt.Errorf("Luma accepts at most %d reference images", lumaMaxImageRefs)
+	}
+	for _, ref := range req.ImageRefs {
+		u, err := url.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("Luma reference images must be public URLs, got %q", ref)
+		}
+	}
+
+	return nil
+}
+
+type lumaRequest struct {
+	Prompt      string         `json:"prompt"`
+	Model       string         `json:"model"`
+	AspectRatio string         `json:"aspect_ratio,omitempty"`
+	ImageRef    []lumaImageRef `json:"image_ref,omitempty"`
+}
+
+type lumaImageRef struct {
+	URL    string  `json:"url"`
+	Weight float64 `json:"weight"`
+}
+
+type lumaGeneration struct {
+	ID            string `json:"id"`
+	State         string `json:"state"`
+	FailureReason string `json:"failure_reason"`
+	Assets        *struct {
+		Image string `json:"image"`
+		Video string `json:"video"`
+	} `json:"assets"`
+}
+
+// lumaVideoRequest starts a Ray video generation from the prompt and an
+// optional start keyframe
+type lumaVideoRequest struct {
+	Prompt      string                  `json:"prompt"`
+	Model       string                  `json:"model"`
+	AspectRatio string                  `json:"aspect_ratio,omitempty"`
+	Duration    string                  `json:"duration,omitempty"`
+	Keyframes   map[string]lumaKeyframe `json:"keyframes,omitempty"`
+}
+
+type lumaKeyframe struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+func (l *Luma) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := l.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	apiReq := lumaRequest{
+		Prompt:      req.Prompt,
+		Model:       l.extractModelName(req.Model),
+		AspectRatio: req.AspectRatio,
+	}
+	for _, ref := range req.ImageRefs {
+		apiReq.ImageRef = append(apiReq.ImageRef, lumaImageRef{URL: ref, Weight: lumaImageRefWeight})
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		l.baseURL+"/generations/image",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+l.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, classifyLumaError(resp.StatusCode, respBody)
+	}
+
+	var generation lumaGeneration
+	if err := json.Unmarshal(respBody, &generation); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	result, err := l.waitForGeneration(ctx, generation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if result.Assets.Image == "" {
+		return nil, fmt.Errorf("no image in Luma generation")
+	}
+
+	image := hostedImage(result.Assets.Image, "jpeg", 0, 0)
+	if !req.URLOnly {
+		data, format, err := l.downloadImage(ctx, result.Assets.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		image = generator.Image{Data: data, Format: format, Index: 0}
+	}
+
+	return &generator.Response{
+		Images:      []generator.Image{image},
+		Model:       req.Model,
+		Provider:    l.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// GenerateVideo generates a video from the prompt with a Ray model
+// (ray-2, ray-flash-2), starting from the reference image URL if there is
+// one. Luma fetches keyframes itself, so the image must be a public URL;
+// the video then takes its aspect ratio.
+func (l *Luma) GenerateVideo(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := l.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if len(req.ImageRefs) > 1 || len(req.InputImages) > 0 {
+		return nil, fmt.Errorf("Luma video takes one image as a public URL")
+	}
+	if req.FPS > 0 {
+		return nil, fmt.Errorf("Luma videos have a fixed frame rate")
+	}
+
+	startTime := time.Now()
+
+	apiReq := lumaVideoRequest{
+		Prompt: req.Prompt,
+		Model:  l.extractModelName(req.Model),
+	}
+	if req.VideoDuration > 0 {
+		apiReq.Duration = fmt.Sprintf("%ds", req.VideoDuration)
+	}
+	if len(req.ImageRefs) == 1 {
+		apiReq.Keyframes = map[string]lumaKeyframe{
+			"frame0": {Type: "image", URL: req.ImageRefs[0]},
+		}
+	} else {
+		apiReq.AspectRatio = req.AspectRatio
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, l.baseURL+"/generations", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+l.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, classifyLumaError(resp.StatusCode, respBody)
+	}
+
+	var generation lumaGeneration
+	if err := json.Unmarshal(respBody, &generation); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	result, err := l.waitForGeneration(ctx, generation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if result.Assets.Video == "" {
+		return nil, fmt.Errorf("no video in Luma generation")
+	}
+
+	video := generator.Video{
+		Image:   hostedImage(result.Assets.Video, "mp4", 0, 0),
+		Seconds: float64(req.VideoDuration),
+	}
+	if !req.URLOnly {
+		data, _, err := l.downloadImage(ctx, result.Assets.Video)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download video: %w", err)
+		}
+		video.Image = generator.Image{Data: data, Format: "mp4"}
+	}
+
+	return &generator.Response{
+		Videos:      []generator.Video{video},
+		Model:       req.Model,
+		Provider:    l.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// waitForGeneration polls a generation until it completes or fails
+func (l *Luma) waitForGeneration(ctx context.Context, id string) (*lumaGeneration, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-l.httpClient.Clock().After(l.pollInterval):
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, l.baseURL+"/generations/"+id, nil)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+l.apiKey)
+
+		resp, err := l.httpClient.Do(ctx, httpReq)
+		if err != nil {
+			return nil, err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, classifyLumaError(resp.StatusCode, respBody)
+		}
+
+		var generation lumaGeneration
+		if err := json.Unmarshal(respBody, &generation); err != nil {
+			return nil, fmt.Errorf("failed to decode generation: %w", err)
+		}
+
+		switch generation.State {
+		case "completed":
+			if generation.Assets == nil {
+				return nil, fmt.Errorf("no assets in Luma generation")
+			}
+			return &generation, nil
+		case "failed":
+			return nil, classifyLumaFailure(generation.FailureReason)
+		}
+	}
+}
+
+func (l *Luma) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := l.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (l *Luma) extractModelName(model string) string {
+	return strings.TrimPrefix(model, l.Name()+"/")
+}
+
+// classifyLumaError maps a Luma error response to an APIError
+func classifyLumaError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Luma",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Detail any `json:"detail"`
+	}
+	json.Unmarshal(body, &apiResp)
+	if detail, ok := apiResp.Detail.(string); ok {
+		apiErr.Message = detail
+	}
+
+	switch apiErr.Kind {
+	case ErrKindAuth:
+		apiErr.Hint = "check LUMAAI_API_KEY or providers.luma.api_key"
+	case ErrKindQuota:
+		apiErr.Hint = "your Luma credits are exhausted; top up at https://lumalabs.ai/api"
+	case ErrKindInvalidRequest:
+		if strings.Contains(strings.ToLower(apiErr.Message), "credit") {
+			apiErr.Kind = ErrKindQuota
+			apiErr.Hint = "your Luma credits are exhausted; top up at https://lumalabs.ai/api"
+		}
+	}
+
+	return apiErr
+}
+
+// classifyLumaFailure maps the failure reason of a finished generation to
+// an APIError
+func classifyLumaFailure(reason string) *APIError {
+	apiErr := &APIError{
+		Provider: "Luma",
+		Kind:     ErrKindUnknown,
+		Message:  "generation failed",
+	}
+	if reason != "" {
+		apiErr.Message = reason
+	}
+
+	lower := strings.ToLower(reason)
+	if strings.Contains(lower, "moderation") || strings.Contains(lower, "policy") {
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = "rephrase the prompt or use different reference images"
+	} else if strings.Contains(lower, "image") && strings.Contains(lower, "url") {
+		apiErr.Kind = ErrKindInvalidRequest
+		apiErr.Hint = "reference image URLs must be publicly reachable"
+	}
+
+	return apiErr
+}
@@ -0,0 +1,417 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const novitaBaseURL = "https://api.novita.ai/v3"
+
+// novitaMaxImages is the largest image_num accepted per request
+const novitaMaxImages = 8
+
+// novitaDefaultLoraStrength is used for LoRAs given without a strength
+const novitaDefaultLoraStrength = 0.7
+
+// Novita implements the Provider interface for Novita AI, which runs
+// Stable Diffusion checkpoints and LoRAs
+type Novita struct {
+	apiKey       string
+	baseURL      string
+	httpClient   *httputil.Client
+	pollInterval time.Duration
+}
+
+// NewNovita creates a new Novita AI provider
+func NewNovita(cfg *ProviderConfig) *Novita {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = novitaBaseURL
+	}
+
+	return &Novita{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+		pollInterval: 2 * time.Second,
+	}
+}
+
+func (n *Novita) Name() string {
+	return "novita"
+}
+
+func (n *Novita) SupportedModels() []Model {
+	return catalogModels(n.Name())
+}
+
+// SupportedParams returns the provider-specific options read from
+// Request.Params
+func (n *Novita) SupportedParams() []string {
+	return []string{"sampler", "cfg_scale", "lora", "clip_skip"}
+}
+
+func (n *Novita) ValidateRequest(req *generator.Request) error {
+	if n.apiKey == "" {
+		return fmt.Errorf("Novita API key is required (set NOVITA_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Novita does not support seamless tiling")
+	}
+
+	if req.Count > novitaMaxImages {
+		return fmt.Errorf("Novita generates at most %d images per request", novitaMaxImages)
+	}
+
+	if req.Size != "" {
+		width, height, err := splitSize(req.Size)
+		if err != nil {
+			return err
+		}
+		if width < 128 || height < 128 || width > 2048 || height > 2048 {
+			return fmt.Errorf("invalid size %s: width and height must be between 128 and 2048", req.Size)
+		}
+	}
+
+	_, err := n.buildRequest(req)
+	return err
+}
+
+type novitaTxt2ImgRequest struct {
+	Extra   novitaExtra   `json:"extra"`
+	Request novitaTxt2Img `json:"request"`
+}
+
+type novitaExtra struct {
+	ResponseImageType string `json:"response_image_type"`
+}
+
+type novitaTxt2Img struct {
+	ModelName      string       `json:"model_name"`
+	Prompt         string       `json:"prompt"`
+	NegativePrompt string       `json:"negative_prompt,omitempty"`
+	Width          int          `json:"width"`
+	Height         int          `json:"height"`
+	ImageNum       int          `json:"image_num"`
+	Steps          int          `json:"steps"`
+	Seed           int64        `json:"seed"`
+	GuidanceScale  float64      `json:"guidance_scale"`
+	SamplerName    string       `json:"sampler_name"`
+	ClipSkip       int          `json:"clip_skip,omitempty"`
+	Loras          []novitaLora `json:"loras,omitempty"`
+}
+
+type novitaLora struct {
+	ModelName string  `json:"model_name"`
+	Strength  float64 `json:"strength"`
+}
+
+type novitaTaskResult struct {
+	Task struct {
+		TaskID string `json:"task_id"`
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	} `json:"task"`
+	Images []struct {
+		ImageURL    string `json:"image_url"`
+		ImageType   string `json:"image_type"`
+		ImageURLTTL any    `json:"image_url_ttl"` // Seconds, as a number or string
+	} `json:"images"`
+}
+
+// buildRequest maps a generation request to the txt2img input, applying
+// Novita defaults and the provider-specific params
+func (n *Novita) buildRequest(req *generator.Request) (*novitaTxt2Img, error) {
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	input := &novitaTxt2Img{
+		ModelName:      n.extractModelName(req.Model),
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Width:          1024,
+		Height:         1024,
+		ImageNum:       count,
+		Steps:          20,
+		Seed:           -1, // Random
+		GuidanceScale:  7.5,
+		SamplerName:    "DPM++ 2M Karras",
+	}
+	if req.Size != "" {
+		input.Width, input.Height = parseSize(req.Size)
+	}
+	if req.Steps > 0 {
+		input.Steps = req.Steps
+	}
+	if req.Seed != nil {
+		input.Seed = *req.Seed
+	}
+
+	for key, value := range req.Params {
+		switch key {
+		case "sampler":
+			input.SamplerName = value
+		case "cfg_scale":
+			scale, err := strconv.ParseFloat(value, 64)
+			if err != nil || scale < 1 || scale > 30 {
+				return nil, fmt.Errorf("invalid cfg_scale %q, expected a number between 1 and 30", value)
+			}
+			input.GuidanceScale = scale
+		case "clip_skip":
+			skip, err := strconv.Atoi(value)
+			if err != nil || skip < 1 || skip > 12 {
+				return nil, fmt.Errorf("invalid clip_skip %q, expected 1-12", value)
+			}
+			input.ClipSkip = skip
+		case "lora":
+			loras, err := parseNovitaLoras(value)
+			if err != nil {
+				return nil, err
+			}
+			input.Loras = loras
+		default:
+			return nil, fmt.Errorf("unsupported Novita param %q, supported: %s",
+				key, strings.Join(n.SupportedParams(), ", "))
+		}
+	}
+
+	return input, nil
+}
+
+// parseNovitaLoras parses "name:strength,name" into LoRA selections
+func parseNovitaLoras(s string) ([]novitaLora, error) {
+	var loras []novitaLora
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		lora := novitaLora{ModelName: item, Strength: novitaDefaultLoraStrength}
+		if name, strength, ok := strings.Cut(item, ":"); ok {
+			value, err := strconv.ParseFloat(strength, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LoRA strength in %q, expected name:strength", item)
+			}
+			lora = novitaLora{ModelName: name, Strength: value}
+		}
+		loras = append(loras, lora)
+	}
+	if len(loras) > 5 {
+		return nil, fmt.Errorf("Novita accepts at most 5 LoRAs")
+	}
+	return loras, nil
+}
+
+func (n *Novita) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := n.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	input, err := n.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(novitaTxt2ImgRequest{
+		Extra:   novitaExtra{ResponseImageType: "png"},
+		Request: *input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		n.baseURL+"/async/txt2img",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+n.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyNovitaError(resp.StatusCode, respBody)
+	}
+
+	var task struct {
+		TaskID string `json:"task_id"`
+	}
+	if err := json.Unmarshal(respBody, &task); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	result, err := n.waitForTask(ctx, task.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]generator.Image, 0, len(result.Images))
+	for i, img := range result.Images {
+		format := img.ImageType
+		if format == "" || format == "jpg" {
+			format = "jpeg"
+		}
+
+		if req.URLOnly {
+			ttl, _ := strconv.Atoi(fmt.Sprint(img.ImageURLTTL))
+			image := hostedImage(img.ImageURL, format, i, time.Duration(ttl)*time.Second)
+			image.Seed = req.Seed
+			images = append(images, image)
+			continue
+		}
+
+		data, err := n.downloadImage(ctx, img.ImageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		images = append(images, generator.Image{
+			Data:   data,
+			Format: format,
+			Seed:   req.Seed,
+			Index:  i,
+		})
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    n.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// waitForTask polls a txt2img task until it succeeds or fails
+func (n *Novita) waitForTask(ctx context.Context, taskID string) (*novitaTaskResult, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-n.httpClient.Clock().After(n.pollInterval):
+		}
+
+		httpReq, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodGet,
+			n.baseURL+"/async/task-result?task_id="+url.QueryEscape(taskID),
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+n.apiKey)
+
+		resp, err := n.httpClient.Do(ctx, httpReq)
+		if err != nil {
+			return nil, err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, classifyNovitaError(resp.StatusCode, respBody)
+		}
+
+		var result novitaTaskResult
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode task result: %w", err)
+		}
+
+		switch result.Task.Status {
+		case "TASK_STATUS_SUCCEED":
+			if len(result.Images) == 0 {
+				return nil, fmt.Errorf("no images in Novita task result")
+			}
+			return &result, nil
+		case "TASK_STATUS_FAILED":
+			return nil, &APIError{
+				Provider: "Novita",
+				Kind:     ErrKindUnknown,
+				Message:  result.Task.Reason,
+			}
+		}
+	}
+}
+
+func (n *Novita) downloadImage(ctx context.Context, url string) ([]byte, error) {
+	resp, err := n.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+func (n *Novita) extractModelName(model string) string {
+	return strings.TrimPrefix(model, n.Name()+"/")
+}
+
+// classifyNovitaError maps a Novita error response to an APIError
+func classifyNovitaError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Novita",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Code    int    `json:"code"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &apiResp)
+	apiErr.Code = apiResp.Reason
+	apiErr.Message = apiResp.Message
+
+	switch {
+	case apiResp.Reason == "NOT_ENOUGH_BALANCE" || strings.Contains(strings.ToLower(apiResp.Message), "balance"):
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "your Novita balance is too low; top up at https://novita.ai/billing"
+	case strings.Contains(strings.ToLower(apiResp.Message), "model") && strings.Contains(strings.ToLower(apiResp.Message), "not"):
+		apiErr.Kind = ErrKindNotFound
+		apiErr.Hint = "use a checkpoint file name from https://novita.ai/models, e.g. novita/sd_xl_base_1.0.safetensors"
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check NOVITA_API_KEY or providers.novita.api_key"
+	}
+
+	return apiErr
+}
@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +24,9 @@ const (
 	ModelGPTImage1 = "gpt-image-1"
 )
 
+// openaiMaxImages is the largest n accepted by DALL-E 2 and GPT Image 1
+const openaiMaxImages = 10
+
 // OpenAI implements the Provider interface for OpenAI DALL-E
 type OpenAI struct {
 	apiKey     string
@@ -37,9 +42,15 @@ func NewOpenAI(cfg *ProviderConfig) *OpenAI {
 	}
 
 	return &OpenAI{
-		apiKey:     cfg.APIKey,
-		baseURL:    baseURL,
-		httpClient: httputil.NewClient(httputil.WithRetries(cfg.MaxRetries)),
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+			httputil.WithRetryPolicy(openaiShouldRetry),
+		),
 	}
 }
 
@@ -48,29 +59,7 @@ func (o *OpenAI) Name() string {
 }
 
 func (o *OpenAI) SupportedModels() []Model {
-	return []Model{
-		{
-			ID:       "openai/dall-e-3",
-			Name:     "DALL-E 3",
-			Provider: "openai",
-			Sizes:    []string{"1024x1024", "1792x1024", "1024x1792"},
-			Features: []string{"quality", "style"},
-		},
-		{
-			ID:       "openai/dall-e-2",
-			Name:     "DALL-E 2",
-			Provider: "openai",
-			Sizes:    []string{"256x256", "512x512", "1024x1024"},
-			Features: []string{},
-		},
-		{
-			ID:       "openai/gpt-image-1",
-			Name:     "GPT Image 1",
-			Provider: "openai",
-			Sizes:    []string{"1024x1024", "1024x1536", "1536x1024"},
-			Features: []string{"quality"},
-		},
-	}
+	return catalogModels(o.Name())
 }
 
 func (o *OpenAI) ValidateRequest(req *generator.Request) error {
@@ -78,16 +67,32 @@ func (o *OpenAI) ValidateRequest(req *generator.Request) error {
 		return fmt.Errorf("OpenAI API key is required (set OPENAI_API_KEY)")
 	}
 
+	if req.Tile {
+		return fmt.Errorf("OpenAI does not support seamless tiling")
+	}
+
 	model := o.extractModelName(req.Model)
 
-	// Validate sizes for DALL-E 3
-	if model == ModelDALLE3 && req.Size != "" {
-		validSizes := map[string]bool{
-			"1024x1024": true, "1792x1024": true, "1024x1792": true,
-		}
-		if !validSizes[req.Size] {
-			return fmt.Errorf("invalid size %s for DALL-E 3", req.Size)
-		}
+	// Validate sizes per model
+	validSizes := map[string]map[string]bool{
+		ModelDALLE3:    {"1024x1024": true, "1792x1024": true, "1024x1792": true},
+		ModelDALLE2:    {"256x256": true, "512x512": true, "1024x1024": true},
+		ModelGPTImage1: {"1024x1024": true, "1536x1024": true, "1024x1536": true, "auto": true},
+	}
+	if sizes, ok := validSizes[model]; ok && req.Size != "" && !sizes[req.Size] {
+		return fmt.Errorf("invalid size %s for %s", req.Size, model)
+	}
+
+	// Larger counts are split into several requests by GenerateSplit
+	if model == ModelDALLE3 && req.Count > 1 {
+		return fmt.Errorf("DALL-E 3 generates one image per request")
+	}
+	if req.Count > openaiMaxImages {
+		return fmt.Errorf("%s generates at most %d images per request", model, openaiMaxImages)
+	}
+
+	if model == ModelDALLE2 && req.Quality != "" && req.Quality != "standard" {
+		return fmt.Errorf("DALL-E 2 only supports standard quality")
 	}
 
 	// Validate quality
@@ -155,54 +160,172 @@ func (o *OpenAI) Generate(ctx context.Context, req *generator.Request) (*generat
 		Style:          req.Style,
 		ResponseFormat: "b64_json",
 	}
+	// Style is a DALL-E 3 parameter; DALL-E 2 rejects it
+	if model == ModelDALLE2 {
+		apiReq.Style = ""
+	}
 
-	body, err := json.Marshal(apiReq)
+	apiResp, err := o.requestImages(ctx, &apiReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		o.baseURL+"/images/generations",
-		bytes.NewReader(body),
-	)
-	if err != nil {
+	return o.response(req, apiResp, startTime)
+}
+
+// openaiMaxEditImages is the largest number of input images GPT Image 1
+// edits take
+const openaiMaxEditImages = 16
+
+// Edit edits images via /images/edits. GPT Image 1 takes up to 16 images;
+// DALL-E 2 takes one square PNG. The transparent areas of the mask mark
+// what to change in the first image.
+func (o *OpenAI) Edit(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := o.ValidateRequest(req); err != nil {
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
+	model := o.extractModelName(req.Model)
+	switch model {
+	case ModelGPTImage1:
+		if err := checkInputImages("OpenAI", req, openaiMaxEditImages, true); err != nil {
+			return nil, err
+		}
+	case ModelDALLE2:
+		if err := checkInputImages("DALL-E 2", req, 1, true); err != nil {
+			return nil, err
+		}
+		if req.InputImages[0].MIMEType != "image/png" || (req.Mask != nil && req.Mask.MIMEType != "image/png") {
+			return nil, fmt.Errorf("DALL-E 2 edits take PNG images and masks")
+		}
+	default:
+		return nil, fmt.Errorf("%s does not support edits, use %s or %s", model, ModelGPTImage1, ModelDALLE2)
+	}
+	if req.Fidelity != "" && model != ModelGPTImage1 {
+		return nil, fmt.Errorf("--fidelity is supported by %s only", ModelGPTImage1)
+	}
 
-	resp, err := o.httpClient.Do(ctx, httpReq)
+	startTime := time.Now()
+
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("model", model)
+	writer.WriteField("prompt", req.Prompt)
+	writer.WriteField("n", strconv.Itoa(count))
+	if req.Size != "" {
+		writer.WriteField("size", req.Size)
+	}
+	// GPT Image 1 always returns base64 and rejects response_format
+	if model == ModelGPTImage1 {
+		if quality := gptImageQuality(req.Quality); quality != "" {
+			writer.WriteField("quality", quality)
+		}
+		if req.Fidelity != "" {
+			writer.WriteField("input_fidelity", req.Fidelity)
+		}
+	} else {
+		writer.WriteField("response_format", "b64_json")
+	}
+
+	field := "image"
+	if len(req.InputImages) > 1 {
+		field = "image[]"
+	}
+	for _, img := range req.InputImages {
+		if err := writeFormImage(writer, field, img); err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+	}
+	if req.Mask != nil {
+		if err := writeFormImage(writer, "mask", *req.Mask); err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+	}
+	writer.Close()
+
+	apiResp, err := o.post(ctx, "/images/edits", body.Bytes(), writer.FormDataContentType())
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	return o.response(req, apiResp, startTime)
+}
+
+// gptImageQuality maps a quality to the low, medium, high or auto GPT
+// Image 1 accepts; the DALL-E values of the config default carry over as
+// standard to auto and hd to high
+func gptImageQuality(quality string) string {
+	switch quality {
+	case "standard":
+		return "auto"
+	case "hd":
+		return "high"
 	}
+	return quality
+}
 
-	if resp.StatusCode != http.StatusOK {
-		var apiResp openaiImageResponse
-		json.Unmarshal(respBody, &apiResp)
-		if apiResp.Error != nil {
-			return nil, fmt.Errorf("OpenAI API error: %s", apiResp.Error.Message)
-		}
-		return nil, fmt.Errorf("OpenAI API error: status %d", resp.StatusCode)
+// AcceptsVariations reports whether the model has a variations endpoint;
+// only DALL-E 2 does
+func (o *OpenAI) AcceptsVariations(model string) bool {
+	return o.extractModelName(model) == ModelDALLE2
+}
+
+// Vary makes variations of the initial image via /images/variations,
+// which takes one square PNG and no prompt
+func (o *OpenAI) Vary(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := o.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	model := o.extractModelName(req.Model)
+	if model != ModelDALLE2 {
+		return nil, fmt.Errorf("%s has no variations endpoint, use %s", model, ModelDALLE2)
+	}
+	if req.InitImage == nil || req.InitImage.MIMEType != "image/png" {
+		return nil, fmt.Errorf("DALL-E 2 variations take a PNG image")
 	}
 
-	var apiResp openaiImageResponse
-	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	startTime := time.Now()
+
+	count := req.Count
+	if count <= 0 {
+		count = 1
 	}
 
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("model", model)
+	writer.WriteField("n", strconv.Itoa(count))
+	if req.Size != "" {
+		writer.WriteField("size", req.Size)
+	}
+	writer.WriteField("response_format", "b64_json")
+	if err := writeFormImage(writer, "image", *req.InitImage); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	writer.Close()
+
+	apiResp, err := o.post(ctx, "/images/variations", body.Bytes(), writer.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+
+	return o.response(req, apiResp, startTime)
+}
+
+// response converts an images API response
+func (o *OpenAI) response(req *generator.Request, apiResp *openaiImageResponse, startTime time.Time) (*generator.Response, error) {
 	images := make([]generator.Image, 0, len(apiResp.Data))
 	for i, img := range apiResp.Data {
 		var data []byte
 		if img.B64JSON != "" {
+			var err error
 			data, err = base64.StdEncoding.DecodeString(img.B64JSON)
 			if err != nil {
 				return nil, fmt.Errorf("failed to decode image: %w", err)
@@ -232,9 +355,175 @@ func (o *OpenAI) Generate(ctx context.Context, req *generator.Request) (*generat
 	}, nil
 }
 
+// requestImages sends one image generation request
+func (o *OpenAI) requestImages(ctx context.Context, apiReq *openaiImageRequest) (*openaiImageResponse, error) {
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return o.post(ctx, "/images/generations", body, "application/json")
+}
+
+// post sends a request to an images endpoint and decodes the response
+func (o *OpenAI) post(ctx context.Context, path string, body []byte, contentType string) (*openaiImageResponse, error) {
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		o.baseURL+path,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+	httpReq.Header.Set("Content-Type", contentType)
+
+	resp, err := o.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyOpenAIError(resp.StatusCode, respBody)
+	}
+
+	var apiResp openaiImageResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &apiResp, nil
+}
+
+// Endpoint returns the images API endpoint of a request: edits for input
+// images, variations for an init image, else generations
+func (o *OpenAI) Endpoint(req *generator.Request) string {
+	switch {
+	case len(req.InputImages) > 0:
+		return "POST " + o.baseURL + "/images/edits"
+	case req.InitImage != nil:
+		return "POST " + o.baseURL + "/images/variations"
+	}
+	return "POST " + o.baseURL + "/images/generations"
+}
+
+// Describe answers the prompt about an image with an OpenAI vision model
+func (o *OpenAI) Describe(ctx context.Context, model, prompt string, img generator.InputImage) (string, error) {
+	if o.apiKey == "" {
+		return "", fmt.Errorf("OpenAI API key is required (set OPENAI_API_KEY)")
+	}
+	return describeChat(ctx, o.httpClient, o.baseURL+"/chat/completions",
+		map[string]string{"Authorization": "Bearer " + o.apiKey},
+		"OpenAI", o.extractModelName(model), prompt, img)
+}
+
+// Probe checks model access via the models metadata endpoint
+func (o *OpenAI) Probe(ctx context.Context, model string) (*ProbeResult, error) {
+	if o.apiKey == "" {
+		return nil, fmt.Errorf("OpenAI API key is required (set OPENAI_API_KEY)")
+	}
+
+	result := newProbeResult(o, model)
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		o.baseURL+"/models/"+o.extractModelName(model),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := applyProbeStatus(result, "OpenAI", resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (o *OpenAI) extractModelName(model string) string {
 	if strings.HasPrefix(model, "openai/") {
 		return strings.TrimPrefix(model, "openai/")
 	}
 	return model
 }
+
+const (
+	openaiBillingURL   = "https://platform.openai.com/settings/organization/billing"
+	openaiRateLimitURL = "https://platform.openai.com/settings/organization/limits"
+)
+
+// classifyOpenAIError maps an OpenAI error response to an APIError
+func classifyOpenAIError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "OpenAI",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp openaiImageResponse
+	json.Unmarshal(body, &apiResp)
+	if apiResp.Error != nil {
+		apiErr.Message = apiResp.Error.Message
+		apiErr.Code = apiResp.Error.Code
+		if apiErr.Code == "" {
+			apiErr.Code = apiResp.Error.Type
+		}
+	}
+
+	switch apiErr.Code {
+	case "insufficient_quota", "billing_hard_limit_reached", "billing_not_active":
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "your OpenAI account is out of credits or over its spending limit; " +
+			"check billing at " + openaiBillingURL
+	case "content_policy_violation", "moderation_blocked":
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = "the prompt was rejected by OpenAI's safety system; rephrase it " +
+			"to avoid explicit, violent or copyrighted content"
+	case "rate_limit_exceeded":
+		apiErr.Kind = ErrKindRateLimit
+		apiErr.Hint = "rate limit hit after retries; wait a minute before retrying, " +
+			"lower --count, or raise max_retries (limits: " + openaiRateLimitURL + ")"
+	case "invalid_api_key":
+		apiErr.Kind = ErrKindAuth
+	case "model_not_found":
+		apiErr.Kind = ErrKindNotFound
+	}
+
+	if apiErr.Hint == "" {
+		switch apiErr.Kind {
+		case ErrKindAuth:
+			apiErr.Hint = "check OPENAI_API_KEY or providers.openai.api_key"
+		case ErrKindNotFound:
+			apiErr.Hint = "your key may not have access to this model; run 'llm-imager list models -p openai'"
+		case ErrKindRateLimit:
+			apiErr.Hint = "rate limit hit after retries; wait a minute before retrying"
+		}
+	}
+
+	return apiErr
+}
+
+// openaiShouldRetry skips retries for 429 responses caused by exhausted
+// quota, which will not recover by waiting
+func openaiShouldRetry(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return true
+	}
+	return classifyOpenAIError(resp.StatusCode, body).Kind != ErrKindQuota
+}
@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/piligrim/llm-imager/internal/generator"
@@ -17,11 +20,32 @@ import (
 
 const openrouterBaseURL = "https://openrouter.ai/api/v1"
 
+// The live model catalogue is cached for a day; fetching it at startup
+// gives up after a few seconds
+const (
+	openrouterModelsTTL   = 24 * time.Hour
+	openrouterLiveTimeout = 5 * time.Second
+)
+
+// Default app attribution sent to OpenRouter
+const (
+	openrouterAppURL   = "https://github.com/piligrim/llm-imager"
+	openrouterAppTitle = "llm-imager"
+)
+
 // OpenRouter implements the Provider interface for OpenRouter
 type OpenRouter struct {
 	apiKey     string
 	baseURL    string
 	httpClient *httputil.Client
+	appURL     string
+	appTitle   string
+	headers    map[string]string
+	routing    *RoutingPreferences
+	liveModels bool // Add the live catalogue to SupportedModels
+
+	liveOnce sync.Once
+	live     []Model
 }
 
 // NewOpenRouter creates a new OpenRouter provider
@@ -30,11 +54,29 @@ func NewOpenRouter(cfg *ProviderConfig) *OpenRouter {
 	if baseURL == "" {
 		baseURL = openrouterBaseURL
 	}
+	appURL := cfg.AppURL
+	if appURL == "" {
+		appURL = openrouterAppURL
+	}
+	appTitle := cfg.AppTitle
+	if appTitle == "" {
+		appTitle = openrouterAppTitle
+	}
 
 	return &OpenRouter{
-		apiKey:     cfg.APIKey,
-		baseURL:    baseURL,
-		httpClient: httputil.NewClient(httputil.WithRetries(cfg.MaxRetries)),
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithMiddleware(cfg.Middleware),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+		),
+		appURL:     appURL,
+		appTitle:   appTitle,
+		headers:    expandHeaders(cfg.Headers),
+		routing:    cfg.Routing,
+		liveModels: cfg.LiveModels,
 	}
 }
 
@@ -42,39 +84,49 @@ func (o *OpenRouter) Name() string {
 	return "openrouter"
 }
 
+// SupportedModels returns the catalog models and, with live_models, the
+// image models of the live OpenRouter catalogue. Any other
+// openrouter/<vendor>/<model> ID is accepted as well.
 func (o *OpenRouter) SupportedModels() []Model {
-	return []Model{
-		{
-			ID:       "openrouter/google/gemini-2.5-flash-image",
-			Name:     "Gemini 2.5 Flash Image (via OpenRouter)",
-			Provider: "openrouter",
-			Features: []string{"aspect_ratio", "image_size"},
-		},
-		{
-			ID:       "openrouter/google/gemini-3-pro-image-preview",
-			Name:     "Gemini 3 Pro Image Preview (via OpenRouter)",
-			Provider: "openrouter",
-			Features: []string{"aspect_ratio", "image_size"},
-		},
-		{
-			ID:       "openrouter/openai/gpt-5-image",
-			Name:     "GPT-5 Image (via OpenRouter)",
-			Provider: "openrouter",
-			Features: []string{"aspect_ratio"},
-		},
-		{
-			ID:       "openrouter/openai/gpt-5-image-mini",
-			Name:     "GPT-5 Image Mini (via OpenRouter)",
-			Provider: "openrouter",
-			Features: []string{"aspect_ratio"},
-		},
+	models := catalogModels(o.Name())
+	if !o.liveModels {
+		return models
+	}
+
+	o.liveOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), openrouterLiveTimeout)
+		defer cancel()
+		live, err := cachedImageModels(ctx, o.httpClient, o.baseURL, openrouterModelsCachePath())
+		if err != nil {
+			debugf("OpenRouter live models unavailable: %v", err)
+		}
+		o.live = live
+	})
+
+	known := make(map[string]bool, len(models))
+	for _, m := range models {
+		known[m.ID] = true
 	}
+	for _, m := range o.live {
+		if !known[m.ID] {
+			models = append(models, m)
+		}
+	}
+	return models
 }
 
 func (o *OpenRouter) ValidateRequest(req *generator.Request) error {
 	if o.apiKey == "" {
 		return fmt.Errorf("OpenRouter API key is required (set OPENROUTER_API_KEY)")
 	}
+
+	if req.Model != "" && !strings.Contains(o.extractModelName(req.Model), "/") {
+		return fmt.Errorf("invalid OpenRouter model %s, expected openrouter/<vendor>/<model> (e.g. openrouter/google/gemini-2.5-flash-image)", req.Model)
+	}
+
+	if req.Tile {
+		return fmt.Errorf("OpenRouter does not support seamless tiling")
+	}
 	return nil
 }
 
@@ -93,6 +145,7 @@ type openrouterRequest struct {
 	Messages    []openrouterMessage `json:"messages"`
 	Modalities  []string            `json:"modalities"`
 	ImageConfig *openrouterImageConfig `json:"image_config,omitempty"`
+	Provider    *RoutingPreferences    `json:"provider,omitempty"`
 }
 
 type openrouterResponse struct {
@@ -130,6 +183,7 @@ func (o *OpenRouter) Generate(ctx context.Context, req *generator.Request) (*gen
 			{Role: "user", Content: req.Prompt},
 		},
 		Modalities: []string{"image", "text"},
+		Provider:   o.routing,
 	}
 
 	if req.AspectRatio != "" || req.Size != "" {
@@ -156,7 +210,11 @@ func (o *OpenRouter) Generate(ctx context.Context, req *generator.Request) (*gen
 
 	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("HTTP-Referer", "https://github.com/piligrim/llm-imager")
+	httpReq.Header.Set("HTTP-Referer", o.appURL)
+	httpReq.Header.Set("X-Title", o.appTitle)
+	for name, value := range o.headers {
+		httpReq.Header.Set(name, value)
+	}
 
 	resp, err := o.httpClient.Do(ctx, httpReq)
 	if err != nil {
@@ -179,7 +237,7 @@ func (o *OpenRouter) Generate(ctx context.Context, req *generator.Request) (*gen
 	}
 
 	var apiResp openrouterResponse
-	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+	if err := decodeTolerant("OpenRouter", respBody, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -250,8 +308,34 @@ func (o *OpenRouter) Generate(ctx context.Context, req *generator.Request) (*gen
 		}
 	}
 
+	// Payload variants the structured decoding above does not know
+	if len(images) == 0 {
+		for i, url := range o.findMessageImageURLs(respBody) {
+			var imageData []byte
+			var format string
+			var err error
+			if strings.HasPrefix(url, "data:image/") {
+				imageData, format, err = o.parseDataURL(url)
+			} else {
+				imageData, format, err = o.downloadImage(ctx, url)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get image: %w", err)
+			}
+			images = append(images, generator.Image{
+				Data:   imageData,
+				Format: format,
+				Index:  i,
+			})
+		}
+	}
+
 	if len(images) == 0 {
-		return nil, fmt.Errorf("no images in response")
+		var text string
+		if len(apiResp.Choices) > 0 {
+			text, _ = apiResp.Choices[0].Message.Content.(string)
+		}
+		return nil, noImagesError(text)
 	}
 
 	return &generator.Response{
@@ -263,6 +347,93 @@ func (o *OpenRouter) Generate(ctx context.Context, req *generator.Request) (*gen
 	}, nil
 }
 
+// Endpoint returns the chat completions endpoint images are generated with
+func (o *OpenRouter) Endpoint(req *generator.Request) string {
+	return "POST " + o.baseURL + "/chat/completions"
+}
+
+// Probe checks the API key via the key endpoint and the model via the
+// Describe answers the prompt about an image with a vision model routed
+// by OpenRouter
+func (o *OpenRouter) Describe(ctx context.Context, model, prompt string, img generator.InputImage) (string, error) {
+	if o.apiKey == "" {
+		return "", fmt.Errorf("OpenRouter API key is required (set OPENROUTER_API_KEY)")
+	}
+
+	headers := map[string]string{
+		"Authorization": "Bearer " + o.apiKey,
+		"HTTP-Referer":  o.appURL,
+		"X-Title":       o.appTitle,
+	}
+	for name, value := range o.headers {
+		headers[name] = value
+	}
+	return describeChat(ctx, o.httpClient, o.baseURL+"/chat/completions", headers,
+		"OpenRouter", o.extractModelName(model), prompt, img)
+}
+
+// public models list
+func (o *OpenRouter) Probe(ctx context.Context, model string) (*ProbeResult, error) {
+	if err := o.ValidateRequest(&generator.Request{}); err != nil {
+		return nil, err
+	}
+
+	result := newProbeResult(o, model)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+"/key", nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if err := applyProbeStatus(result, "OpenRouter", resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	models, err := fetchImageModels(ctx, o.httpClient, o.baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Available = false
+	result.Message = "model not found in OpenRouter image models"
+	for _, m := range models {
+		if m.ID == result.Model {
+			result.Available = true
+			result.Message = ""
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// findMessageImageURLs scans the images and content of the first choice
+// for image URLs in any shape
+func (o *OpenRouter) findMessageImageURLs(body []byte) []string {
+	var raw struct {
+		Choices []struct {
+			Message map[string]any `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil || len(raw.Choices) == 0 {
+		return nil
+	}
+
+	msg := raw.Choices[0].Message
+	urls := findImageURLs(map[string]any{"images": msg["images"], "content": msg["content"]})
+	if len(urls) > 0 {
+		debugf("OpenRouter response: found %d image(s) in an unrecognized payload shape", len(urls))
+	}
+	return urls
+}
+
 func (o *OpenRouter) extractModelName(model string) string {
 	if name, found := strings.CutPrefix(model, "openrouter/"); found {
 		return name
@@ -347,7 +518,12 @@ type openrouterModelInfo struct {
 
 // FetchImageModels fetches available image generation models from OpenRouter API
 func FetchImageModels(ctx context.Context) ([]Model, error) {
-	resp, err := http.Get(openrouterBaseURL + "/models")
+	return fetchImageModels(ctx, httputil.NewClient(), openrouterBaseURL)
+}
+
+// fetchImageModels lists the image models of the /models endpoint
+func fetchImageModels(ctx context.Context, client *httputil.Client, baseURL string) ([]Model, error) {
+	resp, err := client.Get(ctx, baseURL+"/models")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch models: %w", err)
 	}
@@ -389,3 +565,47 @@ func FetchImageModels(ctx context.Context) ([]Model, error) {
 
 	return models, nil
 }
+
+// openrouterModelsCachePath returns where the live catalogue is cached
+func openrouterModelsCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "llm-imager", "openrouter-models.json")
+}
+
+// cachedImageModels returns the live image models, fetched at most once
+// per openrouterModelsTTL and cached at path. A stale cache is used when
+// the fetch fails.
+func cachedImageModels(ctx context.Context, client *httputil.Client, baseURL, path string) ([]Model, error) {
+	var cached []Model
+	if info, err := os.Stat(path); err == nil {
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil &&
+			time.Since(info.ModTime()) < openrouterModelsTTL {
+			return cached, nil
+		}
+	}
+
+	models, err := fetchImageModels(ctx, client, baseURL)
+	if err != nil {
+		return cached, err
+	}
+
+	if data, err := json.Marshal(models); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			os.WriteFile(path, data, 0644)
+		}
+	}
+	return models, nil
+}
+
+// expandHeaders copies extra headers, expanding $VAR references in their
+// values so keys can be kept out of the config file
+func expandHeaders(headers map[string]string) map[string]string {
+	expanded := make(map[string]string, len(headers))
+	for name, value := range headers {
+		expanded[name] = os.ExpandEnv(value)
+	}
+	return expanded
+}
@@ -0,0 +1,258 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// PluginPrefix is the file name prefix of provider plugin executables,
+// e.g. llm-imager-provider-acme
+const PluginPrefix = "llm-imager-provider-"
+
+// PluginProtocolVersion is the version of the exec+JSON plugin protocol
+const PluginProtocolVersion = 1
+
+// pluginDescribeTimeout bounds the describe call made at startup
+const pluginDescribeTimeout = 5 * time.Second
+
+// pluginDescription is the output of "<plugin> describe"
+type pluginDescription struct {
+	Protocol int           `json:"protocol"`
+	Name     string        `json:"name"`
+	Models   []pluginModel `json:"models"`
+	Params   []string      `json:"params,omitempty"`
+}
+
+type pluginModel struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	Sizes     []string `json:"sizes,omitempty"`
+	Features  []string `json:"features,omitempty"`
+	MaxImages int      `json:"max_images,omitempty"`
+}
+
+// pluginResult is the output of "<plugin> generate"
+type pluginResult struct {
+	Images        []generator.Image `json:"images"`
+	RevisedPrompt string            `json:"revised_prompt,omitempty"`
+	Error         *struct {
+		Kind    ErrorKind `json:"kind,omitempty"`
+		Code    string    `json:"code,omitempty"`
+		Message string    `json:"message"`
+		Hint    string    `json:"hint,omitempty"`
+	} `json:"error,omitempty"`
+}
+
+// Plugin implements the Provider interface by running an external
+// executable. "<plugin> describe" prints the provider name and models;
+// "<plugin> generate" reads a generator.Request as JSON on stdin and
+// prints the images, or an error, as JSON on stdout. The plugin's stderr
+// is passed through.
+type Plugin struct {
+	path   string
+	name   string
+	models []pluginModel
+	params []string
+}
+
+// DiscoverPlugins describes the plugin executables in dir. Plugins that
+// fail to describe themselves are reported as errors and skipped; a
+// missing directory has no plugins.
+func DiscoverPlugins(ctx context.Context, dir string) ([]*Plugin, []error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var plugins []*Plugin
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), PluginPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+
+		p, err := NewPlugin(ctx, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, errs
+}
+
+// NewPlugin runs "<path> describe" and returns the described provider
+func NewPlugin(ctx context.Context, path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginDescribeTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "describe")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s: describe failed: %w%s", filepath.Base(path), err, stderrSuffix(stderr.String()))
+	}
+
+	var desc pluginDescription
+	if err := json.Unmarshal(stdout.Bytes(), &desc); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid describe output: %w", filepath.Base(path), err)
+	}
+	if desc.Protocol != PluginProtocolVersion {
+		return nil, fmt.Errorf("plugin %s: unsupported protocol %d, expected %d",
+			filepath.Base(path), desc.Protocol, PluginProtocolVersion)
+	}
+	if desc.Name == "" {
+		desc.Name = strings.TrimPrefix(filepath.Base(path), PluginPrefix)
+	}
+	if err := checkProviderName(desc.Name); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+	}
+
+	return &Plugin{
+		path:   path,
+		name:   strings.ToLower(desc.Name),
+		models: desc.Models,
+		params: desc.Params,
+	}, nil
+}
+
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Path returns the plugin executable
+func (p *Plugin) Path() string {
+	return p.path
+}
+
+func (p *Plugin) SupportedModels() []Model {
+	models := make([]Model, 0, len(p.models))
+	for _, m := range p.models {
+		name := m.Name
+		if name == "" {
+			name = m.ID
+		}
+		models = append(models, Model{
+			ID:        p.name + "/" + m.ID,
+			Name:      name,
+			Provider:  p.name,
+			Sizes:     m.Sizes,
+			Features:  m.Features,
+			MaxImages: m.MaxImages,
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// SupportedParams returns the --param keys the plugin described
+func (p *Plugin) SupportedParams() []string {
+	return p.params
+}
+
+func (p *Plugin) ValidateRequest(req *generator.Request) error {
+	if p.extractModelName(req.Model) == "" {
+		return fmt.Errorf("%s needs a model name, e.g. %s/<model>", p.name, p.name)
+	}
+	return nil
+}
+
+func (p *Plugin) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := p.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	pluginReq := *req
+	pluginReq.Model = p.extractModelName(req.Model)
+	input, err := json.Marshal(pluginReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path, "generate")
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+
+	var result pluginResult
+	if err := decodeTolerant(p.name, stdout.Bytes(), &result); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("plugin %s failed: %w", p.name, runErr)
+		}
+		return nil, fmt.Errorf("plugin %s: invalid output: %w", p.name, err)
+	}
+
+	if result.Error != nil {
+		kind := result.Error.Kind
+		if kind == "" {
+			kind = ErrKindUnknown
+		}
+		return nil, &APIError{
+			Provider: p.name,
+			Kind:     kind,
+			Code:     result.Error.Code,
+			Message:  result.Error.Message,
+			Hint:     result.Error.Hint,
+		}
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", p.name, runErr)
+	}
+	if len(result.Images) == 0 {
+		return nil, noImagesError("")
+	}
+
+	for i := range result.Images {
+		result.Images[i].Index = i
+		if result.Images[i].Format == "" {
+			result.Images[i].Format = "png"
+		}
+	}
+
+	return &generator.Response{
+		Images:        result.Images,
+		Model:         req.Model,
+		Provider:      p.name,
+		RevisedPrompt: result.RevisedPrompt,
+		GeneratedAt:   time.Now(),
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+func (p *Plugin) extractModelName(model string) string {
+	return strings.TrimPrefix(model, p.name+"/")
+}
+
+// stderrSuffix formats the stderr of a failed plugin call for an error
+// message
+func stderrSuffix(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	if len(stderr) > 200 {
+		stderr = stderr[:200] + "..."
+	}
+	return ": " + stderr
+}
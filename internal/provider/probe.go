@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Prober is implemented by providers that can verify model access
+// without generating an image
+type Prober interface {
+	// Probe checks that the model exists and the API key can use it
+	Probe(ctx context.Context, model string) (*ProbeResult, error)
+}
+
+// ProbeResult describes what a provider reported about a model
+type ProbeResult struct {
+	Model     string    `json:"model"`
+	Provider  string    `json:"provider"`
+	Available bool      `json:"available"`
+	Params    []string  `json:"params,omitempty"` // Accepted optional parameters
+	Message   string    `json:"message,omitempty"`
+	ProbedAt  time.Time `json:"probed_at"`
+}
+
+// Accepts reports whether the probed model accepts the given parameter
+func (r *ProbeResult) Accepts(param string) bool {
+	for _, p := range r.Params {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
+
+// newProbeResult creates a result pre-filled with the static model features
+func newProbeResult(p Provider, model string) *ProbeResult {
+	id := QualifiedModelID(p.Name(), model)
+	result := &ProbeResult{
+		Model:    id,
+		Provider: p.Name(),
+		ProbedAt: time.Now(),
+	}
+	for _, m := range p.SupportedModels() {
+		if m.ID == id {
+			result.Params = append([]string(nil), m.Features...)
+			break
+		}
+	}
+	return result
+}
+
+// applyProbeStatus fills availability from a metadata endpoint status code
+func applyProbeStatus(result *ProbeResult, providerName string, status int) error {
+	switch {
+	case status == http.StatusOK:
+		result.Available = true
+	case status == http.StatusNotFound:
+		result.Message = "model not found or not accessible with this key"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return &APIError{
+			Provider:   providerName,
+			Kind:       ErrKindAuth,
+			StatusCode: status,
+			Message:    "API key rejected",
+		}
+	default:
+		return &APIError{
+			Provider:   providerName,
+			Kind:       kindFromStatus(status),
+			StatusCode: status,
+		}
+	}
+	return nil
+}
+
+// QualifiedModelID returns the model ID in "provider/model" form
+func QualifiedModelID(providerName, model string) string {
+	if strings.HasPrefix(model, providerName+"/") {
+		return model
+	}
+	return fmt.Sprintf("%s/%s", providerName, model)
+}
@@ -2,8 +2,11 @@ package provider
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
 )
 
 // Provider defines the contract for all image generation providers
@@ -29,6 +32,9 @@ type Model struct {
 	Sizes    []string // Supported sizes
 	Features []string // negative_prompt, seed, steps, etc.
 	Pricing  *Pricing // Pricing info (optional)
+
+	PricePerImage float64 // Approximate USD per image (0 if unknown)
+	MaxImages     int     // Images per request (0 if unknown)
 }
 
 // Pricing contains model pricing information (per token)
@@ -37,9 +43,162 @@ type Pricing struct {
 	Completion string // Price per completion token
 }
 
+// ParamsProvider is implemented by providers that read provider-specific
+// options from Request.Params
+type ParamsProvider interface {
+	// SupportedParams returns the accepted Request.Params keys
+	SupportedParams() []string
+}
+
+// InputParamsProvider is implemented by providers that pass any
+// Request.ParamValues through as model inputs, checking the keys against
+// the model when generating
+type InputParamsProvider interface {
+	// AcceptsInputParams reports whether params are passed through
+	AcceptsInputParams() bool
+}
+
+// Editor is implemented by providers that can edit existing images,
+// taking them from Request.InputImages and Request.Mask
+type Editor interface {
+	// Edit changes the input images as described by the prompt
+	Edit(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// Outpainter is implemented by providers that can extend the first input
+// image beyond its borders by Request.Outpaint
+type Outpainter interface {
+	// Outpaint fills the added area, guided by the prompt if there is one
+	Outpaint(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// BackgroundRemover is implemented by providers that can cut the subject
+// of the first input image out onto a transparent background
+type BackgroundRemover interface {
+	// RemoveBackground returns the input image as a transparent PNG
+	RemoveBackground(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// SearchReplacer is implemented by providers that can find the object
+// described by Request.SearchPrompt in the first input image and replace
+// it, without a mask
+type SearchReplacer interface {
+	// SearchReplace replaces the object with what the prompt describes
+	SearchReplace(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// Recolorer is implemented by providers that can find the object
+// described by Request.SelectPrompt in the first input image and change
+// its colors, without a mask
+type Recolorer interface {
+	// Recolor recolors the object as the prompt describes
+	Recolor(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// Vectorizer is implemented by providers that can trace the first input
+// image into an SVG
+type Vectorizer interface {
+	// Vectorize returns the input image as an SVG image
+	Vectorize(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// VideoGenerator is implemented by providers that can generate a video
+// from the prompt, or animate the first input image, or the reference
+// image URL, into one
+type VideoGenerator interface {
+	// GenerateVideo returns the video in Response.Videos
+	GenerateVideo(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// Segmenter is implemented by providers that can find the object a text
+// prompt describes in an image, for masks drawn from a description
+type Segmenter interface {
+	// Segment returns a mask of img, white where prompt matches and black
+	// elsewhere
+	Segment(ctx context.Context, model, prompt string, img generator.InputImage) (generator.Image, error)
+}
+
+// Endpointer is implemented by providers that can tell where a generation
+// request would be sent, for --plan
+type Endpointer interface {
+	// Endpoint returns the HTTP method and URL a Generate, Edit, Vary,
+	// Outpaint, SearchReplace or Recolor call with req would use
+	Endpoint(req *generator.Request) string
+}
+
+// InitImageProvider is implemented by providers that can start generating
+// from Request.InitImage (image-to-image)
+type InitImageProvider interface {
+	// AcceptsInitImage reports whether the model takes an initial image
+	AcceptsInitImage(model string) bool
+}
+
+// ControlProvider is implemented by providers that can guide generation
+// by Request.Control
+type ControlProvider interface {
+	// AcceptsControl reports whether the model takes a control image in
+	// the mode
+	AcceptsControl(model, mode string) bool
+}
+
+// StyleImageProvider is implemented by providers that can take the style
+// of Request.StyleImage
+type StyleImageProvider interface {
+	// AcceptsStyleImage reports whether the model takes a style reference
+	// image
+	AcceptsStyleImage(model string) bool
+}
+
+// Variator is implemented by providers with an endpoint that makes
+// variations of Request.InitImage without a prompt
+type Variator interface {
+	// AcceptsVariations reports whether the model has a variations endpoint
+	AcceptsVariations(model string) bool
+	// Vary returns Count variations of the initial image
+	Vary(ctx context.Context, req *generator.Request) (*generator.Response, error)
+}
+
+// Describer is implemented by providers with vision models that can
+// describe an image in text
+type Describer interface {
+	// Describe answers the prompt about the image with the vision model
+	Describe(ctx context.Context, model, prompt string, img generator.InputImage) (string, error)
+}
+
 // ProviderConfig contains provider configuration
 type ProviderConfig struct {
 	APIKey     string
 	BaseURL    string
 	MaxRetries int
+
+	Name   string   // Provider name (custom providers)
+	API    string   // images or chat (custom providers)
+	Models []string // Model names (custom providers)
+
+	APIVersion  string               // API version query parameter (Azure)
+	Deployments map[string]string    // Model name -> deployment name (Azure)
+	Region      string               // Cloud region (Bedrock)
+	Profile     string               // Credentials profile (Bedrock)
+	AccountID   string               // Account ID (Cloudflare)
+	SecretID    string               // API secret ID (Hunyuan)
+	SecretKey   string               // API secret key (Hunyuan)
+	MaxWait     time.Duration        // Longest wait in the queue (Stable Horde, InvokeAI)
+	Timeout     time.Duration        // HTTP timeout, for slow local servers (LocalAI)
+	AppURL      string               // App attribution URL (OpenRouter)
+	AppTitle    string               // App attribution title (OpenRouter)
+	Headers     map[string]string    // Extra request headers (OpenRouter)
+	Routing     *RoutingPreferences  // Upstream provider selection (OpenRouter)
+	LiveModels  bool                 // List models from the live catalogue (OpenRouter)
+	Middleware  *httputil.Middleware // JSON body mutations from the config
+
+	Transport http.RoundTripper // HTTP transport, nil for the default (tests)
+	Clock     httputil.Clock    // Clock for backoff and polling, nil for the system clock (tests)
+}
+
+// RoutingPreferences selects the upstream providers an aggregator may use
+type RoutingPreferences struct {
+	Order          []string `json:"order,omitempty"`           // Providers to try first, in order
+	Only           []string `json:"only,omitempty"`            // Allowed providers
+	Ignore         []string `json:"ignore,omitempty"`          // Excluded providers
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"` // Use other providers when these fail
 }
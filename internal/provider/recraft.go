@@ -0,0 +1,364 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const recraftBaseURL = "https://external.api.recraft.ai/v1"
+
+// Recraft styles; vector styles produce SVG output
+var (
+	recraftRasterStyles = []string{"realistic_image", "digital_illustration"}
+	recraftVectorStyles = []string{"vector_illustration", "icon"}
+)
+
+// Recraft implements the Provider interface for Recraft, including its
+// vector models that return SVG
+type Recraft struct {
+	apiKey     string
+	baseURL    string
+	httpClient *httputil.Client
+}
+
+// NewRecraft creates a new Recraft provider
+func NewRecraft(cfg *ProviderConfig) *Recraft {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = recraftBaseURL
+	}
+
+	return &Recraft{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+	}
+}
+
+func (r *Recraft) Name() string {
+	return "recraft"
+}
+
+func (r *Recraft) SupportedModels() []Model {
+	return catalogModels(r.Name())
+}
+
+func (r *Recraft) ValidateRequest(req *generator.Request) error {
+	if r.apiKey == "" {
+		return fmt.Errorf("Recraft API key is required (set RECRAFT_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Recraft does not support seamless tiling")
+	}
+
+	if req.StyleType != "" {
+		styles := recraftRasterStyles
+		if r.isVector(req.Model) {
+			styles = recraftVectorStyles
+		}
+		if !slices.Contains(styles, strings.ToLower(req.StyleType)) {
+			return fmt.Errorf("invalid style type %s for %s, valid: %s",
+				req.StyleType, req.Model, strings.Join(styles, ", "))
+		}
+	}
+
+	if req.Count > 6 {
+		return fmt.Errorf("Recraft returns at most 6 images per request")
+	}
+
+	return nil
+}
+
+type recraftRequest struct {
+	Prompt         string `json:"prompt"`
+	Model          string `json:"model"`
+	Style          string `json:"style,omitempty"`
+	Size           string `json:"size,omitempty"`
+	N              int    `json:"n,omitempty"`
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+}
+
+func (r *Recraft) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := r.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	model := r.extractModelName(req.Model)
+	vector := r.isVector(req.Model)
+
+	style := strings.ToLower(req.StyleType)
+	if style == "" {
+		style = "realistic_image"
+		if vector {
+			style = "vector_illustration"
+		}
+	}
+
+	apiReq := recraftRequest{
+		Prompt:         req.Prompt,
+		Model:          strings.TrimSuffix(model, "-vector"),
+		Style:          style,
+		Size:           req.Size,
+		N:              count,
+		NegativePrompt: req.NegativePrompt,
+		ResponseFormat: "b64_json",
+	}
+	// SVG output is only available as a URL
+	if vector {
+		apiReq.ResponseFormat = "url"
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		r.baseURL+"/images/generations",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyRecraftError(resp.StatusCode, respBody)
+	}
+
+	var apiResp openaiImageResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Data))
+	for i, img := range apiResp.Data {
+		var data []byte
+		format := "webp"
+		switch {
+		case img.B64JSON != "":
+			data, err = base64.StdEncoding.DecodeString(img.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image: %w", err)
+			}
+		case img.URL != "" && req.URLOnly:
+			image := hostedImage(img.URL, format, i, 0)
+			if vector {
+				image.Format = "svg"
+			}
+			images = append(images, image)
+			continue
+		case img.URL != "":
+			data, format, err = r.downloadImage(ctx, img.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image: %w", err)
+			}
+		}
+		if vector {
+			format = "svg"
+		}
+
+		images = append(images, generator.Image{
+			Data:   data,
+			Format: format,
+			Index:  i,
+		})
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    r.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+func (r *Recraft) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := r.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "webp"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "svg") {
+		format = "svg"
+	} else if strings.Contains(contentType, "png") {
+		format = "png"
+	} else if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	}
+
+	return data, format, nil
+}
+
+// Vectorize traces the input image into an SVG via the vectorize
+// endpoint, whichever Recraft model is selected
+func (r *Recraft) Vectorize(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if r.apiKey == "" {
+		return nil, fmt.Errorf("Recraft API key is required (set RECRAFT_API_KEY)")
+	}
+	if err := checkInputImages("Recraft", req, 1, false); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writeFormImage(writer, "file", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	writer.Close()
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		r.baseURL+"/images/vectorize",
+		&body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := r.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyRecraftError(resp.StatusCode, respBody)
+	}
+
+	var apiResp struct {
+		Image struct {
+			URL string `json:"url"`
+		} `json:"image"`
+	}
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if apiResp.Image.URL == "" {
+		return nil, fmt.Errorf("Recraft returned no vectorized image")
+	}
+
+	var image generator.Image
+	if req.URLOnly {
+		image = hostedImage(apiResp.Image.URL, "svg", 0, 0)
+		image.Format = "svg"
+	} else {
+		data, _, err := r.downloadImage(ctx, apiResp.Image.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		image = generator.Image{Data: data, Format: "svg"}
+	}
+
+	return &generator.Response{
+		Images:      []generator.Image{image},
+		Model:       req.Model,
+		Provider:    r.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+func (r *Recraft) extractModelName(model string) string {
+	return strings.TrimPrefix(model, r.Name()+"/")
+}
+
+// isVector reports whether a model produces SVG output, based on the
+// "vector" feature in the model catalog
+func (r *Recraft) isVector(model string) bool {
+	id := QualifiedModelID(r.Name(), model)
+	for _, m := range r.SupportedModels() {
+		if m.ID == id {
+			return slices.Contains(m.Features, "vector")
+		}
+	}
+	return strings.HasSuffix(model, "-vector")
+}
+
+// classifyRecraftError maps a Recraft error response to an APIError
+func classifyRecraftError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Recraft",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &apiResp)
+	apiErr.Code = apiResp.Code
+	apiErr.Message = apiResp.Message
+
+	switch {
+	case apiErr.Code == "not_enough_credits":
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "your Recraft API units are exhausted; top up in the Recraft API settings"
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check RECRAFT_API_KEY or providers.recraft.api_key"
+	}
+
+	return apiErr
+}
@@ -2,25 +2,95 @@ package provider
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 )
 
+// defaultAliases maps short model names to fully qualified model IDs
+var defaultAliases = map[string]string{
+	"dalle":     "openai/dall-e-3",
+	"gpt-image": "openai/gpt-image-1",
+	"flux":      "replicate/flux-schnell",
+	"flux-pro":  "replicate/flux-1.1-pro",
+	"imagen":    "google/imagen-3.0-generate-002",
+}
+
+// builtinNames are the names of the built-in providers, which custom,
+// template and plugin providers cannot take even when the built-in is
+// disabled, since commands look providers up by these names
+var builtinNames = []string{
+	"azure-openai", "bedrock", "bfl", "cloudflare", "dryrun", "google", "hunyuan",
+	"ideogram", "invokeai", "localai", "luma", "novita", "openai", "openrouter",
+	"recraft", "replicate", "runware", "seedream", "stability", "stablehorde",
+}
+
+// checkProviderName validates the name of a custom, template or plugin
+// provider
+func checkProviderName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/ ") {
+		return fmt.Errorf("invalid provider name %q: must be non-empty without slashes or spaces", name)
+	}
+	if slices.Contains(builtinNames, strings.ToLower(name)) {
+		return fmt.Errorf("invalid provider name %q: reserved for the built-in provider", name)
+	}
+	return nil
+}
+
 // Registry manages all registered providers
 type Registry struct {
 	mu        sync.RWMutex
 	providers map[string]Provider
 	models    map[string]string // model_id -> provider_name
+	aliases   map[string]string // alias -> model_id
+	priority  []string          // provider order for ambiguous model names
 }
 
 // NewRegistry creates a new provider registry
 func NewRegistry() *Registry {
+	aliases := make(map[string]string, len(defaultAliases))
+	for k, v := range defaultAliases {
+		aliases[k] = v
+	}
+
 	return &Registry{
 		providers: make(map[string]Provider),
 		models:    make(map[string]string),
+		aliases:   aliases,
 	}
 }
 
+// SetAliases adds model aliases, overriding built-in ones
+func (r *Registry) SetAliases(aliases map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, v := range aliases {
+		r.aliases[strings.ToLower(k)] = v
+	}
+}
+
+// SetPriority sets the provider order used to resolve ambiguous model names
+func (r *Registry) SetPriority(providers []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.priority = providers
+}
+
+// AmbiguousModelError is returned when a bare model name matches
+// models of several providers
+type AmbiguousModelError struct {
+	Model      string
+	Candidates []string
+}
+
+func (e *AmbiguousModelError) Error() string {
+	return fmt.Sprintf("model %s is ambiguous, use one of: %s",
+		e.Model, strings.Join(e.Candidates, ", "))
+}
+
 // Register adds a provider to the registry
 func (r *Registry) Register(p Provider) error {
 	r.mu.Lock()
@@ -55,24 +125,104 @@ func (r *Registry) GetByName(name string) (Provider, error) {
 
 // GetByModel automatically determines the provider by model
 func (r *Registry) GetByModel(modelID string) (Provider, error) {
+	p, _, err := r.Resolve(modelID)
+	return p, err
+}
+
+// Resolve determines the provider for a model reference and returns the
+// fully qualified model ID. References are resolved in order: aliases,
+// "provider/model" IDs, registered model IDs, then bare model names
+// (e.g. "dall-e-3"), using the provider priority to break ties.
+func (r *Registry) Resolve(modelID string) (Provider, string, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	alias := ""
+	if target, ok := r.aliases[strings.ToLower(modelID)]; ok {
+		alias, modelID = modelID, target
+	}
+
 	// Parse "provider/model" format
 	if strings.Contains(modelID, "/") {
 		parts := strings.SplitN(modelID, "/", 2)
 		providerName := parts[0]
 		if p, ok := r.providers[providerName]; ok {
-			return p, nil
+			return p, modelID, nil
 		}
 	}
 
 	// Search by model index
 	if providerName, ok := r.models[modelID]; ok {
-		return r.providers[providerName], nil
+		return r.providers[providerName], modelID, nil
+	}
+
+	// Search by bare model name. An alias whose target provider is not
+	// registered falls back to the bare name of the target, then to the
+	// models named after the alias (flux-dev for "flux"), so another
+	// provider serving the model can take it.
+	var candidates []string
+	if alias != "" {
+		candidates = r.bareNameCandidates(modelID[strings.Index(modelID, "/")+1:])
+		if len(candidates) == 0 {
+			candidates = r.familyCandidates(alias)
+		}
+	} else {
+		candidates = r.bareNameCandidates(modelID)
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, "", &ModelNotFoundError{Model: modelID, Suggestions: r.suggestModels(modelID)}
+	case 1:
+		return r.providers[r.models[candidates[0]]], candidates[0], nil
+	}
+
+	for _, providerName := range r.priority {
+		for _, id := range candidates {
+			if r.models[id] == providerName {
+				return r.providers[providerName], id, nil
+			}
+		}
+	}
+
+	if alias != "" {
+		modelID = alias
+	}
+	return nil, "", &AmbiguousModelError{Model: modelID, Candidates: candidates}
+}
+
+// bareNameCandidates returns model IDs whose name without the provider
+// prefix, or whose last path segment, equals name
+func (r *Registry) bareNameCandidates(name string) []string {
+	name = strings.ToLower(name)
+
+	var candidates []string
+	for id, providerName := range r.models {
+		bare := strings.ToLower(strings.TrimPrefix(id, providerName+"/"))
+		last := bare[strings.LastIndex(bare, "/")+1:]
+		if bare == name || last == name {
+			candidates = append(candidates, id)
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates
+}
+
+// familyCandidates returns model IDs whose last path segment starts with
+// name followed by a dash, such as bfl/flux-dev for "flux"
+func (r *Registry) familyCandidates(name string) []string {
+	prefix := strings.ToLower(name) + "-"
+
+	var candidates []string
+	for id := range r.models {
+		last := strings.ToLower(id[strings.LastIndex(id, "/")+1:])
+		if strings.HasPrefix(last, prefix) {
+			candidates = append(candidates, id)
+		}
 	}
+	sort.Strings(candidates)
 
-	return nil, fmt.Errorf("no provider found for model %s", modelID)
+	return candidates
 }
 
 // ListProviders returns a list of all providers
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/piligrim/llm-imager/internal/generator"
@@ -21,6 +22,9 @@ type Replicate struct {
 	apiKey     string
 	baseURL    string
 	httpClient *httputil.Client
+
+	schemaMu sync.Mutex
+	schemas  map[string]*replicateSchema // model ref -> input schema
 }
 
 // NewReplicate creates a new Replicate provider
@@ -31,9 +35,15 @@ func NewReplicate(cfg *ProviderConfig) *Replicate {
 	}
 
 	return &Replicate{
-		apiKey:     cfg.APIKey,
-		baseURL:    baseURL,
-		httpClient: httputil.NewClient(httputil.WithRetries(cfg.MaxRetries)),
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithMiddleware(cfg.Middleware),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+		),
+		schemas: make(map[string]*replicateSchema),
 	}
 }
 
@@ -42,26 +52,7 @@ func (r *Replicate) Name() string {
 }
 
 func (r *Replicate) SupportedModels() []Model {
-	return []Model{
-		{
-			ID:       "replicate/flux-1.1-pro",
-			Name:     "FLUX 1.1 Pro",
-			Provider: "replicate",
-			Features: []string{"aspect_ratio", "seed"},
-		},
-		{
-			ID:       "replicate/flux-schnell",
-			Name:     "FLUX Schnell",
-			Provider: "replicate",
-			Features: []string{"aspect_ratio", "seed"},
-		},
-		{
-			ID:       "replicate/sdxl",
-			Name:     "Stable Diffusion XL",
-			Provider: "replicate",
-			Features: []string{"negative_prompt", "seed", "steps"},
-		},
-	}
+	return catalogModels(r.Name())
 }
 
 func (r *Replicate) ValidateRequest(req *generator.Request) error {
@@ -71,6 +62,31 @@ func (r *Replicate) ValidateRequest(req *generator.Request) error {
 	return nil
 }
 
+// AcceptsInitImage reports true for every model; the model's inputs are
+// checked when generating
+func (r *Replicate) AcceptsInitImage(model string) bool {
+	return true
+}
+
+// AcceptsStyleImage reports true for every model; the model's inputs are
+// checked when generating
+func (r *Replicate) AcceptsStyleImage(model string) bool {
+	return true
+}
+
+// AcceptsControl reports true for structure and sketch control, which
+// ControlNet models take as an image input checked when generating; no
+// Replicate input transfers style only
+func (r *Replicate) AcceptsControl(model, mode string) bool {
+	return mode == generator.ControlStructure || mode == generator.ControlSketch
+}
+
+// AcceptsInputParams reports that --param values are passed through as
+// model inputs, checked against the model's input schema
+func (r *Replicate) AcceptsInputParams() bool {
+	return true
+}
+
 type replicatePrediction struct {
 	ID     string   `json:"id"`
 	Status string   `json:"status"`
@@ -97,24 +113,16 @@ func (r *Replicate) Generate(ctx context.Context, req *generator.Request) (*gene
 	model := r.extractModelName(req.Model)
 	modelRef := r.getModelRef(model)
 
-	input := map[string]any{
-		"prompt": req.Prompt,
-	}
-
-	if req.NegativePrompt != "" {
-		input["negative_prompt"] = req.NegativePrompt
-	}
-
-	if req.AspectRatio != "" {
-		input["aspect_ratio"] = req.AspectRatio
-	}
-
-	if req.Seed != nil {
-		input["seed"] = *req.Seed
-	}
-
-	if req.Steps > 0 {
-		input["num_inference_steps"] = req.Steps
+	var input map[string]any
+	schema, err := r.inputSchema(ctx, modelRef)
+	if err == nil {
+		input, err = schema.mapInput(req.Model, req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Schema unavailable: send the generic parameters as-is
+		input = defaultReplicateInput(req)
 	}
 
 	apiReq := replicateRequest{
@@ -157,7 +165,7 @@ func (r *Replicate) Generate(ctx context.Context, req *generator.Request) (*gene
 	}
 
 	var prediction replicatePrediction
-	if err := json.Unmarshal(respBody, &prediction); err != nil {
+	if err := decodeTolerant("Replicate", respBody, &prediction); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -166,7 +174,7 @@ func (r *Replicate) Generate(ctx context.Context, req *generator.Request) (*gene
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(2 * time.Second):
+		case <-r.httpClient.Clock().After(2 * time.Second):
 		}
 
 		prediction, err = r.getPrediction(ctx, prediction.URLs.Get)
@@ -192,12 +200,23 @@ func (r *Replicate) Generate(ctx context.Context, req *generator.Request) (*gene
 		}
 	}
 
+	// Models that wrap their output in objects, e.g. {"image": "https://..."}
 	if len(imageURLs) == 0 {
-		return nil, fmt.Errorf("no images in response")
+		imageURLs = findImageURLs(map[string]any{"output": prediction.Output})
+	}
+
+	if len(imageURLs) == 0 {
+		return nil, noImagesError("")
 	}
 
 	images := make([]generator.Image, 0, len(imageURLs))
 	for i, url := range imageURLs {
+		// Prediction outputs are deleted an hour after creation
+		if req.URLOnly {
+			images = append(images, hostedImage(url, "png", i, time.Hour))
+			continue
+		}
+
 		data, format, err := r.downloadImage(ctx, url)
 		if err != nil {
 			return nil, fmt.Errorf("failed to download image: %w", err)
@@ -219,6 +238,125 @@ func (r *Replicate) Generate(ctx context.Context, req *generator.Request) (*gene
 	}, nil
 }
 
+// Endpoint returns the predictions endpoint with the model it runs
+func (r *Replicate) Endpoint(req *generator.Request) string {
+	return fmt.Sprintf("POST %s/predictions (model %s)", r.baseURL, r.getModelRef(r.extractModelName(req.Model)))
+}
+
+// ReplicateRembgModel is the background removal model used when no
+// Replicate model is given
+const ReplicateRembgModel = "cjwbw/rembg"
+
+// RemoveBackground runs the requested model, an image-only background
+// remover such as rembg, on the input image
+func (r *Replicate) RemoveBackground(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	return r.runOnImage(ctx, req)
+}
+
+// runOnImage runs an image-only model with the input image as its only
+// input
+func (r *Replicate) runOnImage(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := checkInputImages("Replicate", req, 1, false); err != nil {
+		return nil, err
+	}
+	return r.Generate(ctx, &generator.Request{
+		Model:     req.Model,
+		InitImage: &req.InputImages[0],
+		URLOnly:   req.URLOnly,
+	})
+}
+
+// ReplicateVectorizeModel is the vectorization model used when no
+// Replicate model is given
+const ReplicateVectorizeModel = "recraft-ai/recraft-vectorize"
+
+// Vectorize runs the requested model, an image-only vectorizer, on the
+// input image and checks that it returned SVG
+func (r *Replicate) Vectorize(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	resp, err := r.runOnImage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range resp.Images {
+		if img.Format != "svg" {
+			return nil, fmt.Errorf("%s returned %s instead of SVG; use a vectorization model", req.Model, img.Format)
+		}
+	}
+	return resp, nil
+}
+
+// ReplicateSegmentModel is the text-prompted segmentation model used for
+// masks when no Replicate model is given
+const ReplicateSegmentModel = "tmappdev/lang-segment-anything"
+
+// Segment runs the requested model, a text-prompted segmentation model
+// such as Lang Segment Anything, on img and returns the mask it outputs
+func (r *Replicate) Segment(ctx context.Context, model, prompt string, img generator.InputImage) (generator.Image, error) {
+	resp, err := r.Generate(ctx, &generator.Request{
+		Model:     model,
+		Prompt:    prompt,
+		InitImage: &img,
+	})
+	if err != nil {
+		return generator.Image{}, err
+	}
+	if len(resp.Images) != 1 {
+		return generator.Image{}, fmt.Errorf("%s returned %d images instead of one mask; use a segmentation model", model, len(resp.Images))
+	}
+	return resp.Images[0], nil
+}
+
+// ReplicateVideoModel is the image-to-video model used when no
+// Replicate model is given
+const ReplicateVideoModel = "wavespeedai/wan-2.1-i2v-480p"
+
+// ReplicateTextVideoModel is the text-to-video model used when no
+// Replicate model is given and there is no input image
+const ReplicateTextVideoModel = "wavespeedai/wan-2.1-t2v-480p"
+
+// GenerateVideo runs the requested model, a text-to-video or
+// image-to-video model such as Wan or Kling, and checks that it returned
+// a video. With an input image the video takes its aspect ratio.
+func (r *Replicate) GenerateVideo(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if len(req.InputImages) == 0 && len(req.ImageRefs) > 0 {
+		return nil, fmt.Errorf("Replicate video takes the image as a file, not a URL")
+	}
+	if len(req.InputImages) > 0 {
+		if err := checkInputImages("Replicate", req, 1, false); err != nil {
+			return nil, err
+		}
+	}
+	videoReq := &generator.Request{
+		Model:          req.Model,
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		AspectRatio:    req.AspectRatio,
+		Seed:           req.Seed,
+		VideoDuration:  req.VideoDuration,
+		FPS:            req.FPS,
+		ParamValues:    req.ParamValues,
+		URLOnly:        req.URLOnly,
+	}
+	if len(req.InputImages) > 0 {
+		videoReq.InitImage = &req.InputImages[0]
+		videoReq.AspectRatio = ""
+	}
+	resp, err := r.Generate(ctx, videoReq)
+	if err != nil {
+		return nil, err
+	}
+	videos := make([]generator.Video, 0, len(resp.Images))
+	for _, img := range resp.Images {
+		if !img.IsVideo() {
+			return nil, fmt.Errorf("%s returned %s instead of a video; use a video model", req.Model, img.Format)
+		}
+		videos = append(videos, generator.Video{Image: img, Seconds: float64(req.VideoDuration), FPS: req.FPS})
+	}
+	resp.Images = nil
+	resp.Videos = videos
+	return resp, nil
+}
+
 func (r *Replicate) getPrediction(ctx context.Context, url string) (replicatePrediction, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -233,14 +371,59 @@ func (r *Replicate) getPrediction(ctx context.Context, url string) (replicatePre
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return replicatePrediction{}, err
+	}
+
 	var prediction replicatePrediction
-	if err := json.NewDecoder(resp.Body).Decode(&prediction); err != nil {
+	if err := decodeTolerant("Replicate", body, &prediction); err != nil {
 		return replicatePrediction{}, err
 	}
 
 	return prediction, nil
 }
 
+// Probe checks model access via the models metadata endpoint
+func (r *Replicate) Probe(ctx context.Context, model string) (*ProbeResult, error) {
+	if err := r.ValidateRequest(&generator.Request{}); err != nil {
+		return nil, err
+	}
+
+	result := newProbeResult(r, model)
+
+	modelRef := r.getModelRef(r.extractModelName(model))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/models/"+modelRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+
+	resp, err := r.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := applyProbeStatus(result, "Replicate", resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	// Report the generic parameters the model's input schema accepts
+	var info replicateModelInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err == nil && info.LatestVersion != nil {
+		if schema, err := parseReplicateSchema(info.LatestVersion.OpenAPISchema); err == nil {
+			result.Params = schema.acceptedParams()
+
+			r.schemaMu.Lock()
+			r.schemas[modelRef] = schema
+			r.schemaMu.Unlock()
+		}
+	}
+
+	return result, nil
+}
+
 func (r *Replicate) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
 	resp, err := r.httpClient.Get(ctx, url)
 	if err != nil {
@@ -255,7 +438,13 @@ func (r *Replicate) downloadImage(ctx context.Context, url string) ([]byte, stri
 
 	format := "webp"
 	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "png") {
+	if strings.Contains(contentType, "svg") {
+		format = "svg"
+	} else if strings.Contains(contentType, "mp4") {
+		format = "mp4"
+	} else if strings.Contains(contentType, "video/webm") {
+		format = "webm"
+	} else if strings.Contains(contentType, "png") {
 		format = "png"
 	} else if strings.Contains(contentType, "jpeg") {
 		format = "jpeg"
@@ -279,7 +468,192 @@ func (r *Replicate) getModelRef(model string) string {
 		return "black-forest-labs/flux-schnell"
 	case "sdxl":
 		return "stability-ai/sdxl"
+	case "flux-redux-dev":
+		return "black-forest-labs/flux-redux-dev"
 	default:
 		return model
 	}
 }
+
+// ReplicateModel describes a runnable model from a Replicate collection
+type ReplicateModel struct {
+	ID          string   // replicate/owner/name
+	Description string   // Model description
+	RunCount    int      // Number of runs on Replicate
+	Inputs      []string // Input parameter names from the OpenAPI schema
+}
+
+type replicateModelInfo struct {
+	Owner         string `json:"owner"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	RunCount      int    `json:"run_count"`
+	LatestVersion *struct {
+		ID            string          `json:"id"`
+		OpenAPISchema json.RawMessage `json:"openapi_schema"`
+	} `json:"latest_version"`
+}
+
+type replicateCollection struct {
+	Name   string               `json:"name"`
+	Slug   string               `json:"slug"`
+	Models []replicateModelInfo `json:"models"`
+}
+
+// defaultReplicateInput maps request parameters to the input names used
+// by most Replicate image models
+func defaultReplicateInput(req *generator.Request) map[string]any {
+	input := map[string]any{
+		"prompt": req.Prompt,
+	}
+
+	if req.NegativePrompt != "" {
+		input["negative_prompt"] = req.NegativePrompt
+	}
+
+	if req.AspectRatio != "" {
+		input["aspect_ratio"] = req.AspectRatio
+	}
+
+	if req.Seed != nil {
+		input["seed"] = *req.Seed
+	}
+
+	if req.Steps > 0 {
+		input["num_inference_steps"] = req.Steps
+	}
+
+	if req.VideoDuration > 0 {
+		input["duration"] = req.VideoDuration
+	}
+
+	if req.FPS > 0 {
+		input["fps"] = req.FPS
+	}
+
+	if req.Tile {
+		input["tiling"] = true
+	}
+
+	if req.InitImage != nil {
+		input["image"] = dataURL(*req.InitImage)
+	}
+
+	if req.Strength != nil {
+		input["prompt_strength"] = *req.Strength
+	}
+
+	if req.StyleImage != nil {
+		input["redux_image"] = dataURL(*req.StyleImage)
+	}
+
+	if req.Control != nil {
+		input["control_image"] = dataURL(req.Control.Image)
+		if req.Control.Strength != nil {
+			input["control_strength"] = *req.Control.Strength
+		}
+	}
+
+	for key, value := range req.ParamValues {
+		input[key] = value
+	}
+
+	return input
+}
+
+// inputSchema returns the input schema of the latest version of a model,
+// fetching it once per provider instance
+func (r *Replicate) inputSchema(ctx context.Context, modelRef string) (*replicateSchema, error) {
+	r.schemaMu.Lock()
+	defer r.schemaMu.Unlock()
+
+	if schema, ok := r.schemas[modelRef]; ok {
+		return schema, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/models/"+modelRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+
+	resp, err := r.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Replicate API error: status %d", resp.StatusCode)
+	}
+
+	var info replicateModelInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode model: %w", err)
+	}
+	if info.LatestVersion == nil {
+		return nil, fmt.Errorf("model %s has no published version", modelRef)
+	}
+
+	schema, err := parseReplicateSchema(info.LatestVersion.OpenAPISchema)
+	if err != nil {
+		return nil, err
+	}
+
+	r.schemas[modelRef] = schema
+	return schema, nil
+}
+
+// FetchCollection lists runnable models of a Replicate collection
+// (e.g. "text-to-image") with their input parameters
+func (r *Replicate) FetchCollection(ctx context.Context, slug string) ([]ReplicateModel, error) {
+	if err := r.ValidateRequest(&generator.Request{}); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/collections/"+slug, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+
+	resp, err := r.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("Replicate collection %s not found", slug)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Replicate API error: status %d", resp.StatusCode)
+	}
+
+	var collection replicateCollection
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	models := make([]ReplicateModel, 0, len(collection.Models))
+	for _, m := range collection.Models {
+		// Models without a published version cannot be run
+		if m.LatestVersion == nil {
+			continue
+		}
+
+		var inputs []string
+		if schema, err := parseReplicateSchema(m.LatestVersion.OpenAPISchema); err == nil {
+			inputs = schema.inputNames()
+		}
+
+		models = append(models, ReplicateModel{
+			ID:          fmt.Sprintf("replicate/%s/%s", m.Owner, m.Name),
+			Description: m.Description,
+			RunCount:    m.RunCount,
+			Inputs:      inputs,
+		})
+	}
+
+	return models, nil
+}
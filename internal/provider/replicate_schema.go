@@ -0,0 +1,451 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// replicateSchema is the input part of a model version's OpenAPI schema
+type replicateSchema struct {
+	input replicateSchemaObject
+	refs  map[string]replicateSchemaObject // Component schemas by name
+}
+
+type replicateSchemaObject struct {
+	Required   []string                          `json:"required"`
+	Properties map[string]replicateInputProperty `json:"properties"`
+	Enum       []any                             `json:"enum"`
+}
+
+type replicateInputProperty struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Default     any      `json:"default"`
+	Minimum     *float64 `json:"minimum"`
+	Maximum     *float64 `json:"maximum"`
+	Enum        []any    `json:"enum"`
+	AllOf       []struct {
+		Ref string `json:"$ref"`
+	} `json:"allOf"`
+	Order int `json:"x-order"`
+}
+
+// parseReplicateSchema extracts the input schema from an OpenAPI document
+func parseReplicateSchema(raw json.RawMessage) (*replicateSchema, error) {
+	var doc struct {
+		Components struct {
+			Schemas map[string]replicateSchemaObject `json:"schemas"`
+		} `json:"components"`
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("model version has no OpenAPI schema")
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI schema: %w", err)
+	}
+
+	input, ok := doc.Components.Schemas["Input"]
+	if !ok {
+		return nil, fmt.Errorf("OpenAPI schema has no Input component")
+	}
+
+	return &replicateSchema{input: input, refs: doc.Components.Schemas}, nil
+}
+
+// inputNames returns the input parameter names in schema order
+func (s *replicateSchema) inputNames() []string {
+	props := s.input.Properties
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if props[names[i]].Order != props[names[j]].Order {
+			return props[names[i]].Order < props[names[j]].Order
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// has reports whether the schema accepts an input
+func (s *replicateSchema) has(name string) bool {
+	_, ok := s.input.Properties[name]
+	return ok
+}
+
+// firstOf returns the first input name accepted by the schema
+func (s *replicateSchema) firstOf(names ...string) string {
+	for _, name := range names {
+		if s.has(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// enum returns the allowed values of an input, resolving $ref components
+func (s *replicateSchema) enum(name string) []any {
+	prop := s.input.Properties[name]
+	if len(prop.Enum) > 0 {
+		return prop.Enum
+	}
+	for _, ref := range prop.AllOf {
+		if obj, ok := s.refs[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; ok {
+			return obj.Enum
+		}
+	}
+	return nil
+}
+
+// acceptedParams returns the generic parameters the model accepts, using
+// the same names as Model.Features
+func (s *replicateSchema) acceptedParams() []string {
+	var params []string
+	if s.has("negative_prompt") {
+		params = append(params, "negative_prompt")
+	}
+	if s.has("seed") {
+		params = append(params, "seed")
+	}
+	if s.firstOf("num_inference_steps", "steps") != "" {
+		params = append(params, "steps")
+	}
+	if s.has("aspect_ratio") {
+		params = append(params, "aspect_ratio")
+	}
+	if s.has("width") && s.has("height") {
+		params = append(params, "size")
+	}
+	if s.firstOf("num_outputs", "num_images") != "" {
+		params = append(params, "count")
+	}
+	return params
+}
+
+// mapInput builds the prediction input for a request, mapping generic
+// parameters to the model's input names (steps -> num_inference_steps,
+// size -> width/height) and rejecting parameters the model doesn't accept.
+// Size and aspect ratio have config defaults, so they are dropped rather
+// than rejected when the model has no matching input.
+func (s *replicateSchema) mapInput(modelID string, req *generator.Request) (map[string]any, error) {
+	unsupported := func(param string) error {
+		return fmt.Errorf("%s does not accept %s (inputs: %s)",
+			modelID, param, strings.Join(s.inputNames(), ", "))
+	}
+
+	// Image-only models such as background removers take no prompt, and
+	// FLUX Redux varies its image input ignoring one. Segmentation models
+	// name it text_prompt.
+	input := map[string]any{}
+	imageOnly := req.InitImage != nil || req.StyleImage != nil
+	switch name := s.firstOf("prompt", "text_prompt"); {
+	case name != "":
+		input[name] = req.Prompt
+	case !imageOnly || (req.Prompt != "" && !s.has("redux_image")):
+		return nil, unsupported("prompt")
+	}
+
+	if req.NegativePrompt != "" {
+		if !s.has("negative_prompt") {
+			return nil, unsupported("negative_prompt")
+		}
+		input["negative_prompt"] = req.NegativePrompt
+	}
+
+	if req.Seed != nil {
+		if !s.has("seed") {
+			return nil, unsupported("seed")
+		}
+		input["seed"] = *req.Seed
+	}
+
+	if req.Steps > 0 {
+		name := s.firstOf("num_inference_steps", "steps")
+		if name == "" {
+			return nil, unsupported("steps")
+		}
+		if err := s.checkRange(modelID, name, float64(req.Steps)); err != nil {
+			return nil, err
+		}
+		input[name] = req.Steps
+	}
+
+	// Models without a duration input take the length as a frame count
+	if req.VideoDuration > 0 {
+		switch {
+		case s.has("duration"):
+			if err := s.checkRange(modelID, "duration", float64(req.VideoDuration)); err != nil {
+				return nil, err
+			}
+			input["duration"] = req.VideoDuration
+		case s.has("num_frames") && req.FPS > 0:
+			frames := req.VideoDuration * req.FPS
+			if err := s.checkRange(modelID, "num_frames", float64(frames)); err != nil {
+				return nil, err
+			}
+			input["num_frames"] = frames
+		case s.has("num_frames"):
+			return nil, fmt.Errorf("%s takes the length as a frame count; pass --fps with --duration", modelID)
+		default:
+			return nil, unsupported("duration")
+		}
+	}
+
+	if req.FPS > 0 {
+		name := s.firstOf("fps", "frames_per_second")
+		if name == "" {
+			return nil, unsupported("fps")
+		}
+		if err := s.checkRange(modelID, name, float64(req.FPS)); err != nil {
+			return nil, err
+		}
+		input[name] = req.FPS
+	}
+
+	if req.Count > 1 {
+		name := s.firstOf("num_outputs", "num_images")
+		if name == "" {
+			return nil, unsupported("count")
+		}
+		if err := s.checkRange(modelID, name, float64(req.Count)); err != nil {
+			return nil, err
+		}
+		input[name] = req.Count
+	}
+
+	if req.InitImage != nil {
+		name := s.firstOf("image", "init_image", "input_image", "start_image", "redux_image")
+		if name == "" {
+			return nil, unsupported("an init image")
+		}
+		input[name] = dataURL(*req.InitImage)
+	}
+
+	// Style reference inputs, or the image FLUX Redux varies
+	if req.StyleImage != nil {
+		name := s.firstOf("style_image", "style_reference_image", "redux_image")
+		if name == "" || input[name] != nil {
+			return nil, unsupported("a style image")
+		}
+		input[name] = dataURL(*req.StyleImage)
+	}
+
+	// ControlNet models take the control image as control_image, or as
+	// image when they have no init image input
+	if req.Control != nil {
+		name := s.firstOf("control_image", "image", "input_image")
+		if name == "" || input[name] != nil {
+			return nil, unsupported("a control image")
+		}
+		input[name] = dataURL(req.Control.Image)
+		if req.Control.Strength != nil {
+			name := s.firstOf("control_strength", "controlnet_conditioning_scale", "conditioning_scale")
+			if name == "" {
+				return nil, unsupported("control strength")
+			}
+			if err := s.checkRange(modelID, name, *req.Control.Strength); err != nil {
+				return nil, err
+			}
+			input[name] = *req.Control.Strength
+		}
+	}
+
+	if req.Strength != nil {
+		name := s.firstOf("prompt_strength", "strength", "image_strength")
+		if name == "" {
+			return nil, unsupported("strength")
+		}
+		if err := s.checkRange(modelID, name, *req.Strength); err != nil {
+			return nil, err
+		}
+		input[name] = *req.Strength
+	}
+
+	if req.Tile {
+		name := s.firstOf("tiling", "tileable", "seamless")
+		if name == "" {
+			return nil, unsupported("tiling")
+		}
+		input[name] = true
+	}
+
+	// Aspect ratio wins over size for models accepting both, since
+	// width/height are only honored there with a "custom" ratio
+	switch {
+	case req.AspectRatio != "" && req.AspectRatio != "custom" && s.has("aspect_ratio"):
+		if allowed := s.enum("aspect_ratio"); len(allowed) > 0 && !containsValue(allowed, req.AspectRatio) {
+			return nil, fmt.Errorf("%s does not support aspect ratio %s (allowed: %s)",
+				modelID, req.AspectRatio, joinValues(allowed))
+		}
+		input["aspect_ratio"] = req.AspectRatio
+
+	case req.Size != "" && s.has("width") && s.has("height"):
+		width, height, err := splitSize(req.Size)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkRange(modelID, "width", float64(width)); err != nil {
+			return nil, err
+		}
+		if err := s.checkRange(modelID, "height", float64(height)); err != nil {
+			return nil, err
+		}
+		input["width"] = width
+		input["height"] = height
+		if containsValue(s.enum("aspect_ratio"), "custom") {
+			input["aspect_ratio"] = "custom"
+		}
+	}
+
+	// --param values are passed through and override the inputs mapped
+	// from flags above
+	for _, key := range slices.Sorted(maps.Keys(req.ParamValues)) {
+		if !s.has(key) {
+			return nil, unsupported(key)
+		}
+		value, err := s.coerceParam(modelID, key, req.ParamValues[key], req.Params[key])
+		if err != nil {
+			return nil, err
+		}
+		input[key] = value
+	}
+
+	return input, nil
+}
+
+// coerceParam checks a typed --param value against the type, enum and
+// bounds of an input. Scalars given to string inputs are sent as typed on
+// the command line, so --param version=1.0 stays "1.0".
+func (s *replicateSchema) coerceParam(modelID, name string, value any, raw string) (any, error) {
+	prop := s.input.Properties[name]
+	mismatch := func(want string) error {
+		err := fmt.Errorf("%s: input %s must be %s, got %s", modelID, name, want, describeParam(value))
+		if strings.HasPrefix(want, "a JSON") {
+			err = fmt.Errorf("%w (pass JSON with --param %s:=<json>)", err, name)
+		}
+		return err
+	}
+
+	switch prop.Type {
+	case "string":
+		switch value.(type) {
+		case []any, map[string]any:
+			return nil, mismatch("a string")
+		case string:
+		default:
+			value = raw
+		}
+	case "integer":
+		switch v := value.(type) {
+		case int64, json.Number:
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, mismatch("an integer")
+			}
+			value = int64(v)
+		default:
+			return nil, mismatch("an integer")
+		}
+	case "number":
+		switch value.(type) {
+		case int64, float64, json.Number:
+		default:
+			return nil, mismatch("a number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return nil, mismatch("true or false")
+		}
+	case "array":
+		if _, ok := value.([]any); !ok {
+			return nil, mismatch("a JSON array")
+		}
+	case "object":
+		if _, ok := value.(map[string]any); !ok {
+			return nil, mismatch("a JSON object")
+		}
+	}
+
+	if allowed := s.enum(name); len(allowed) > 0 && !containsValue(allowed, fmt.Sprint(value)) {
+		return nil, fmt.Errorf("%s: input %s must be one of %s, got %v", modelID, name, joinValues(allowed), value)
+	}
+	switch v := value.(type) {
+	case int64:
+		if err := s.checkRange(modelID, name, float64(v)); err != nil {
+			return nil, err
+		}
+	case float64:
+		if err := s.checkRange(modelID, name, v); err != nil {
+			return nil, err
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if err := s.checkRange(modelID, name, f); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// describeParam names the JSON type of a typed --param value for errors
+func describeParam(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case int64, float64, json.Number:
+		return fmt.Sprintf("number %v", v)
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "an object"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// checkRange validates a numeric input against the schema bounds
+func (s *replicateSchema) checkRange(modelID, name string, value float64) error {
+	prop := s.input.Properties[name]
+	if prop.Minimum != nil && value < *prop.Minimum {
+		return fmt.Errorf("%s: %s must be at least %g", modelID, name, *prop.Minimum)
+	}
+	if prop.Maximum != nil && value > *prop.Maximum {
+		return fmt.Errorf("%s: %s must be at most %g", modelID, name, *prop.Maximum)
+	}
+	return nil
+}
+
+// splitSize parses a "WIDTHxHEIGHT" size
+func splitSize(size string) (int, int, error) {
+	var width, height int
+	if _, err := fmt.Sscanf(strings.ToLower(size), "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %s, expected WIDTHxHEIGHT", size)
+	}
+	return width, height, nil
+}
+
+func containsValue(values []any, v string) bool {
+	for _, value := range values {
+		if fmt.Sprint(value) == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,396 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const runwareBaseURL = "https://api.runware.ai/v1"
+
+// runwareMaxImages is the largest numberResults accepted per task
+const runwareMaxImages = 20
+
+// runwareSizes maps aspect ratios to sizes that are multiples of 64
+var runwareSizes = map[string]string{
+	"1:1":  "1024x1024",
+	"4:3":  "1152x896",
+	"3:4":  "896x1152",
+	"16:9": "1344x768",
+	"9:16": "768x1344",
+	"3:2":  "1216x832",
+	"2:3":  "832x1216",
+	"21:9": "1536x640",
+}
+
+// Runware implements the Provider interface for the Runware task API,
+// which serves FLUX and Stable Diffusion models by AIR ID (e.g.
+// runware:100@1) with low latency
+type Runware struct {
+	apiKey     string
+	baseURL    string
+	httpClient *httputil.Client
+}
+
+// NewRunware creates a new Runware provider
+func NewRunware(cfg *ProviderConfig) *Runware {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = runwareBaseURL
+	}
+
+	return &Runware{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+	}
+}
+
+func (r *Runware) Name() string {
+	return "runware"
+}
+
+func (r *Runware) SupportedModels() []Model {
+	return catalogModels(r.Name())
+}
+
+// SupportedParams returns the provider-specific options read from
+// Request.Params
+func (r *Runware) SupportedParams() []string {
+	return []string{"cfg_scale", "scheduler"}
+}
+
+func (r *Runware) ValidateRequest(req *generator.Request) error {
+	if r.apiKey == "" {
+		return fmt.Errorf("Runware API key is required (set RUNWARE_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Runware does not support seamless tiling")
+	}
+
+	if req.Count > runwareMaxImages {
+		return fmt.Errorf("Runware generates at most %d images per request", runwareMaxImages)
+	}
+
+	_, err := r.buildTask(req)
+	return err
+}
+
+// runwareTask is an imageInference task; requests are arrays of tasks and
+// results carry the taskUUID of the task they belong to
+type runwareTask struct {
+	TaskType       string   `json:"taskType"`
+	TaskUUID       string   `json:"taskUUID"`
+	Model          string   `json:"model"`
+	PositivePrompt string   `json:"positivePrompt"`
+	NegativePrompt string   `json:"negativePrompt,omitempty"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	NumberResults  int      `json:"numberResults"`
+	Steps          int      `json:"steps,omitempty"`
+	CFGScale       *float64 `json:"CFGScale,omitempty"`
+	Scheduler      string   `json:"scheduler,omitempty"`
+	Seed           *int64   `json:"seed,omitempty"`
+	OutputType     string   `json:"outputType"`
+	OutputFormat   string   `json:"outputFormat"`
+	IncludeCost    bool     `json:"includeCost"`
+}
+
+type runwareResponse struct {
+	Data []struct {
+		TaskType        string  `json:"taskType"`
+		TaskUUID        string  `json:"taskUUID"`
+		ImageUUID       string  `json:"imageUUID"`
+		ImageURL        string  `json:"imageURL"`
+		ImageBase64Data string  `json:"imageBase64Data"`
+		Seed            int64   `json:"seed"`
+		Cost            float64 `json:"cost"`
+	} `json:"data"`
+	Errors []runwareError `json:"errors"`
+}
+
+type runwareError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Parameter string `json:"parameter"`
+	TaskUUID  string `json:"taskUUID"`
+}
+
+// buildTask maps a generation request to an imageInference task with a
+// fresh taskUUID
+func (r *Runware) buildTask(req *generator.Request) (*runwareTask, error) {
+	task := &runwareTask{
+		TaskType:       "imageInference",
+		TaskUUID:       newTaskUUID(),
+		Model:          r.extractModelName(req.Model),
+		PositivePrompt: req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Width:          1024,
+		Height:         1024,
+		NumberResults:  max(req.Count, 1),
+		Steps:          req.Steps,
+		Seed:           req.Seed,
+		OutputType:     "base64Data",
+		OutputFormat:   "PNG",
+		IncludeCost:    true,
+	}
+	if req.URLOnly {
+		task.OutputType = "URL"
+	}
+
+	size := req.Size
+	if req.AspectRatio != "" {
+		s, ok := runwareSizes[req.AspectRatio]
+		if !ok {
+			return nil, fmt.Errorf("invalid aspect ratio %s for Runware, supported: %s",
+				req.AspectRatio, strings.Join(slices.Sorted(maps.Keys(runwareSizes)), ", "))
+		}
+		size = s
+	}
+	if size != "" {
+		width, height, err := splitSize(size)
+		if err != nil {
+			return nil, err
+		}
+		if width%64 != 0 || height%64 != 0 || width < 128 || height < 128 || width > 2048 || height > 2048 {
+			return nil, fmt.Errorf("invalid size %s for Runware: width and height must be multiples of 64 between 128 and 2048", size)
+		}
+		task.Width, task.Height = width, height
+	}
+
+	if req.Steps < 0 || req.Steps > 100 {
+		return nil, fmt.Errorf("invalid steps %d for Runware, expected 1-100", req.Steps)
+	}
+
+	for key, value := range req.Params {
+		switch key {
+		case "cfg_scale":
+			scale, err := strconv.ParseFloat(value, 64)
+			if err != nil || scale < 0 || scale > 50 {
+				return nil, fmt.Errorf("invalid cfg_scale %q, expected a number between 0 and 50", value)
+			}
+			task.CFGScale = &scale
+		case "scheduler":
+			task.Scheduler = value
+		default:
+			return nil, fmt.Errorf("unsupported Runware param %q, supported: %s",
+				key, strings.Join(r.SupportedParams(), ", "))
+		}
+	}
+
+	return task, nil
+}
+
+func (r *Runware) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := r.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	task, err := r.buildTask(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal([]*runwareTask{task})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyRunwareError(resp.StatusCode, respBody, task.TaskUUID)
+	}
+
+	var apiResp runwareResponse
+	if err := decodeTolerant("Runware", respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if slices.ContainsFunc(apiResp.Errors, func(e runwareError) bool {
+		return e.TaskUUID == "" || e.TaskUUID == task.TaskUUID
+	}) {
+		return nil, classifyRunwareError(resp.StatusCode, respBody, task.TaskUUID)
+	}
+
+	var images []generator.Image
+	for _, item := range apiResp.Data {
+		// Keep only the results correlated with our task
+		if item.TaskUUID != task.TaskUUID {
+			continue
+		}
+		index := len(images)
+		seed := item.Seed
+
+		if item.ImageBase64Data != "" {
+			data, err := base64.StdEncoding.DecodeString(item.ImageBase64Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image %d: %w", index+1, err)
+			}
+			images = append(images, generator.Image{Data: data, Format: "png", Index: index, Seed: &seed})
+			continue
+		}
+		if item.ImageURL == "" {
+			continue
+		}
+		if req.URLOnly {
+			image := hostedImage(item.ImageURL, "png", index, 0)
+			image.Seed = &seed
+			images = append(images, image)
+			continue
+		}
+		data, format, err := r.downloadImage(ctx, item.ImageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		images = append(images, generator.Image{
+			Data:   data,
+			URL:    item.ImageURL,
+			Format: format,
+			Index:  index,
+			Seed:   &seed,
+		})
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError("")
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    r.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+func (r *Runware) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := r.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (r *Runware) extractModelName(model string) string {
+	return strings.TrimPrefix(model, r.Name()+"/")
+}
+
+// newTaskUUID returns a random (version 4) UUID used to correlate a task
+// with its results
+func newTaskUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// classifyRunwareError maps the errors array of a Runware response to an
+// APIError, preferring the error of our task
+func classifyRunwareError(status int, body []byte, taskUUID string) *APIError {
+	apiErr := &APIError{
+		Provider:   "Runware",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp runwareResponse
+	json.Unmarshal(body, &apiResp)
+	for _, e := range apiResp.Errors {
+		if e.TaskUUID != "" && e.TaskUUID != taskUUID {
+			continue
+		}
+		apiErr.Code = e.Code
+		apiErr.Message = e.Message
+		if e.Parameter != "" {
+			apiErr.Message += " (" + e.Parameter + ")"
+		}
+		break
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+
+	code := strings.ToLower(apiErr.Code)
+	switch {
+	case strings.Contains(code, "apikey") || strings.Contains(code, "auth"):
+		apiErr.Kind = ErrKindAuth
+	case strings.Contains(code, "credit") || strings.Contains(code, "balance"):
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "top up the Runware account balance"
+	case strings.Contains(code, "nsfw") || strings.Contains(code, "safety"):
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = "rephrase the prompt"
+	case strings.Contains(code, "model"):
+		apiErr.Kind = ErrKindNotFound
+		apiErr.Hint = "use an AIR model ID, e.g. runware/runware:100@1"
+	case strings.HasPrefix(code, "invalid") || strings.HasPrefix(code, "missing"):
+		apiErr.Kind = ErrKindInvalidRequest
+	}
+	if apiErr.Kind == ErrKindUnknown && status == http.StatusOK {
+		apiErr.Kind = ErrKindInvalidRequest
+	}
+	if apiErr.Kind == ErrKindAuth {
+		apiErr.Hint = "check RUNWARE_API_KEY or providers.runware.api_key"
+	}
+
+	return apiErr
+}
@@ -0,0 +1,368 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+// seedreamBaseURL is the BytePlus ModelArk endpoint; Volcengine Ark users
+// set base_url to https://ark.cn-beijing.volces.com/api/v3
+const seedreamBaseURL = "https://ark.ap-southeast.bytepluses.com/api/v3"
+
+// seedreamV4Sizes maps aspect ratios to the recommended Seedream 4 sizes
+var seedreamV4Sizes = map[string]string{
+	"1:1":  "2048x2048",
+	"4:3":  "2304x1728",
+	"3:4":  "1728x2304",
+	"16:9": "2560x1440",
+	"9:16": "1440x2560",
+	"3:2":  "2496x1664",
+	"2:3":  "1664x2496",
+	"21:9": "3024x1296",
+}
+
+// seedreamV3Sizes maps aspect ratios to the recommended Seedream 3 sizes
+var seedreamV3Sizes = map[string]string{
+	"1:1":  "1024x1024",
+	"4:3":  "1152x864",
+	"3:4":  "864x1152",
+	"16:9": "1280x720",
+	"9:16": "720x1280",
+	"3:2":  "1248x832",
+	"2:3":  "832x1248",
+	"21:9": "1512x648",
+}
+
+// Seedream implements the Provider interface for ByteDance Seedream models
+// served by the BytePlus ModelArk and Volcengine Ark image API
+type Seedream struct {
+	apiKey     string
+	baseURL    string
+	httpClient *httputil.Client
+}
+
+// NewSeedream creates a new Seedream provider
+func NewSeedream(cfg *ProviderConfig) *Seedream {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = seedreamBaseURL
+	}
+
+	return &Seedream{
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+	}
+}
+
+func (s *Seedream) Name() string {
+	return "seedream"
+}
+
+func (s *Seedream) SupportedModels() []Model {
+	return catalogModels(s.Name())
+}
+
+// SupportedParams returns the provider-specific options read from
+// Request.Params
+func (s *Seedream) SupportedParams() []string {
+	return []string{"watermark", "guidance_scale"}
+}
+
+func (s *Seedream) ValidateRequest(req *generator.Request) error {
+	if s.apiKey == "" {
+		return fmt.Errorf("Ark API key is required (set ARK_API_KEY)")
+	}
+
+	if req.Tile {
+		return fmt.Errorf("Seedream does not support seamless tiling")
+	}
+
+	if req.Count > 1 {
+		return fmt.Errorf("Seedream returns one image per request")
+	}
+
+	_, err := s.buildRequest(req)
+	return err
+}
+
+type seedreamRequest struct {
+	Model          string   `json:"model"`
+	Prompt         string   `json:"prompt"`
+	Size           string   `json:"size,omitempty"`
+	Seed           *int64   `json:"seed,omitempty"`
+	GuidanceScale  *float64 `json:"guidance_scale,omitempty"`
+	Watermark      bool     `json:"watermark"`
+	ResponseFormat string   `json:"response_format"`
+}
+
+type seedreamResponse struct {
+	Data []struct {
+		URL     string `json:"url"`
+		B64JSON string `json:"b64_json"`
+	} `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// isV3 reports whether a model is a Seedream 3 model, which takes
+// explicit sizes only, a seed and a guidance scale
+func (s *Seedream) isV3(model string) bool {
+	return strings.Contains(model, "seedream-3")
+}
+
+// buildRequest maps a generation request to the Ark request, validating
+// the size against the model generation
+func (s *Seedream) buildRequest(req *generator.Request) (*seedreamRequest, error) {
+	model := s.extractModelName(req.Model)
+	v3 := s.isV3(model)
+
+	sizes := seedreamV4Sizes
+	if v3 {
+		sizes = seedreamV3Sizes
+	}
+
+	apiReq := &seedreamRequest{
+		Model:          model,
+		Prompt:         req.Prompt,
+		Size:           req.Size,
+		ResponseFormat: "url",
+	}
+
+	if req.AspectRatio != "" {
+		size, ok := sizes[req.AspectRatio]
+		if !ok {
+			return nil, fmt.Errorf("invalid aspect ratio %s for Seedream, supported: %s",
+				req.AspectRatio, strings.Join(slices.Sorted(maps.Keys(sizes)), ", "))
+		}
+		apiReq.Size = size
+	}
+	if err := s.validateSize(apiReq.Size, v3); err != nil {
+		return nil, err
+	}
+
+	if req.Seed != nil {
+		if !v3 {
+			return nil, fmt.Errorf("seeds are only supported by Seedream 3 models")
+		}
+		apiReq.Seed = req.Seed
+	}
+
+	for key, value := range req.Params {
+		switch key {
+		case "watermark":
+			watermark, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid watermark %q, expected true or false", value)
+			}
+			apiReq.Watermark = watermark
+		case "guidance_scale":
+			scale, err := strconv.ParseFloat(value, 64)
+			if err != nil || scale < 1 || scale > 10 {
+				return nil, fmt.Errorf("invalid guidance_scale %q, expected a number between 1 and 10", value)
+			}
+			if !v3 {
+				return nil, fmt.Errorf("guidance_scale is only supported by Seedream 3 models")
+			}
+			apiReq.GuidanceScale = &scale
+		default:
+			return nil, fmt.Errorf("unsupported Seedream param %q, supported: %s",
+				key, strings.Join(s.SupportedParams(), ", "))
+		}
+	}
+
+	return apiReq, nil
+}
+
+// validateSize checks a size: Seedream 3 takes 512-2048 per side, Seedream
+// 4 takes 1K/2K/4K or 1280x720 to 4096x4096 total pixels
+func (s *Seedream) validateSize(size string, v3 bool) error {
+	if size == "" {
+		return nil
+	}
+	if !v3 && (size == "1K" || size == "2K" || size == "4K") {
+		return nil
+	}
+
+	width, height, err := splitSize(size)
+	if err != nil {
+		return err
+	}
+	if v3 {
+		if width < 512 || height < 512 || width > 2048 || height > 2048 {
+			return fmt.Errorf("invalid size %s for Seedream 3: width and height must be between 512 and 2048", size)
+		}
+		return nil
+	}
+
+	if pixels := width * height; pixels < 1280*720 || pixels > 4096*4096 {
+		return fmt.Errorf("invalid size %s for Seedream 4: total pixels must be between 1280x720 and 4096x4096", size)
+	}
+	if width > 16*height || height > 16*width {
+		return fmt.Errorf("invalid size %s for Seedream 4: aspect ratio must be between 1:16 and 16:1", size)
+	}
+	return nil
+}
+
+func (s *Seedream) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	apiReq, err := s.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		s.baseURL+"/images/generations",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifySeedreamError(resp.StatusCode, respBody)
+	}
+
+	var apiResp seedreamResponse
+	if err := decodeTolerant("Seedream", respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	images := make([]generator.Image, 0, len(apiResp.Data))
+	for i, item := range apiResp.Data {
+		if item.URL == "" {
+			continue
+		}
+		// Ark keeps generated images for 24 hours
+		if req.URLOnly {
+			images = append(images, hostedImage(item.URL, "jpeg", i, 24*time.Hour))
+			continue
+		}
+		data, format, err := s.downloadImage(ctx, item.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download image: %w", err)
+		}
+		images = append(images, generator.Image{
+			Data:   data,
+			URL:    item.URL,
+			Format: format,
+			Index:  i,
+		})
+	}
+
+	if len(images) == 0 {
+		return nil, noImagesError("")
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    s.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+func (s *Seedream) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
+	resp, err := s.httpClient.Get(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "png"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	} else if strings.Contains(contentType, "webp") {
+		format = "webp"
+	}
+
+	return data, format, nil
+}
+
+func (s *Seedream) extractModelName(model string) string {
+	return strings.TrimPrefix(model, s.Name()+"/")
+}
+
+// classifySeedreamError maps an Ark error response to an APIError
+func classifySeedreamError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Seedream",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp seedreamResponse
+	json.Unmarshal(body, &apiResp)
+	if apiResp.Error != nil {
+		apiErr.Code = apiResp.Error.Code
+		apiErr.Message = apiResp.Error.Message
+	}
+
+	switch {
+	case strings.Contains(apiErr.Code, "SensitiveContent"):
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = "rephrase the prompt"
+	case strings.Contains(apiErr.Code, "Overdue") || strings.Contains(apiErr.Code, "QuotaExceeded"):
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "check the account balance and model activation in the Ark console"
+	case strings.Contains(apiErr.Code, "ModelNotOpen") || apiErr.Kind == ErrKindNotFound:
+		apiErr.Kind = ErrKindNotFound
+		apiErr.Hint = "activate the model in the Ark console; Volcengine model IDs start with doubao-"
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check ARK_API_KEY or providers.seedream.api_key"
+	}
+
+	return apiErr
+}
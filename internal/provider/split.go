@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/piligrim/llm-imager/internal/catalog"
+	"github.com/piligrim/llm-imager/internal/generator"
+)
+
+// MaxImages returns the number of images a model of p generates per
+// request, from the catalog or else the models p describes itself, such
+// as a plugin's max_images. It returns 0 if the limit is unknown.
+func MaxImages(p Provider, model string) int {
+	id := QualifiedModelID(p.Name(), model)
+	if m, ok := catalog.Current().Lookup(id); ok && m.MaxImages > 0 {
+		return m.MaxImages
+	}
+	for _, m := range p.SupportedModels() {
+		if m.ID == id {
+			return m.MaxImages
+		}
+	}
+	return 0
+}
+
+// GenerateSplit generates req.Count images, splitting the request into
+// several calls when the count exceeds the model's per-request limit.
+// Calls run one after another, so provider rate limits and retries apply
+// as for a single request. With a seed, each call continues from the seed
+// offset by the images already generated so the split run stays
+// reproducible without repeating images.
+func GenerateSplit(ctx context.Context, p Provider, req *generator.Request) (*generator.Response, error) {
+	return SplitCalls(ctx, p, req, p.Generate)
+}
+
+// SplitCalls is GenerateSplit for other calls returning images, such as
+// edits and variations
+func SplitCalls(ctx context.Context, p Provider, req *generator.Request, call func(context.Context, *generator.Request) (*generator.Response, error)) (*generator.Response, error) {
+	limit := MaxImages(p, req.Model)
+	if limit <= 0 || req.Count <= limit {
+		return call(ctx, req)
+	}
+
+	var merged *generator.Response
+	var images []generator.Image
+	for done := 0; done < req.Count; {
+		part := *req
+		part.Count = min(limit, req.Count-done)
+		if req.Seed != nil {
+			seed := *req.Seed + int64(done)
+			part.Seed = &seed
+		}
+
+		resp, err := call(ctx, &part)
+		if err != nil {
+			return nil, err
+		}
+
+		if merged == nil {
+			merged = resp
+		} else {
+			merged.Duration += resp.Duration
+			merged.Kudos += resp.Kudos
+			merged.GeneratedAt = resp.GeneratedAt
+		}
+		for _, img := range resp.Images {
+			img.Index = len(images)
+			images = append(images, img)
+		}
+		done += part.Count
+	}
+
+	merged.Images = images
+	return merged, nil
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,9 +34,14 @@ func NewStability(cfg *ProviderConfig) *Stability {
 	}
 
 	return &Stability{
-		apiKey:     cfg.APIKey,
-		baseURL:    baseURL,
-		httpClient: httputil.NewClient(httputil.WithRetries(cfg.MaxRetries)),
+		apiKey:  cfg.APIKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithMiddleware(cfg.Middleware),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+		),
 	}
 }
 
@@ -43,42 +50,49 @@ func (s *Stability) Name() string {
 }
 
 func (s *Stability) SupportedModels() []Model {
-	return []Model{
-		{
-			ID:       "stability/stable-image-core",
-			Name:     "Stable Image Core",
-			Provider: "stability",
-			Sizes:    []string{"1024x1024", "1152x896", "896x1152"},
-			Features: []string{"negative_prompt", "seed", "aspect_ratio", "style_preset"},
-		},
-		{
-			ID:       "stability/stable-image-ultra",
-			Name:     "Stable Image Ultra",
-			Provider: "stability",
-			Sizes:    []string{"1024x1024"},
-			Features: []string{"negative_prompt", "seed", "aspect_ratio"},
-		},
-		{
-			ID:       "stability/sd3-large",
-			Name:     "Stable Diffusion 3 Large",
-			Provider: "stability",
-			Sizes:    []string{"1024x1024"},
-			Features: []string{"negative_prompt", "seed"},
-		},
-	}
+	return catalogModels(s.Name())
 }
 
 func (s *Stability) ValidateRequest(req *generator.Request) error {
 	if s.apiKey == "" {
 		return fmt.Errorf("Stability API key is required (set STABILITY_API_KEY)")
 	}
+
+	if req.Tile {
+		return fmt.Errorf("Stability does not support seamless tiling")
+	}
+
+	if req.InitImage != nil {
+		if !s.AcceptsInitImage(req.Model) {
+			return fmt.Errorf("%s does not support image-to-image, use stable-image-ultra or sd3-large",
+				s.extractModelName(req.Model))
+		}
+		if req.Strength == nil {
+			return fmt.Errorf("Stability image-to-image requires --strength")
+		}
+	}
 	return nil
 }
 
+// AcceptsInitImage reports whether the model takes an initial image;
+// Stable Image Core does not
+func (s *Stability) AcceptsInitImage(model string) bool {
+	return s.getEndpoint(s.extractModelName(model)) != "/v2beta/stable-image/generate/core"
+}
+
+// AcceptsControl reports true for every mode; the control endpoints are
+// the same for all models
+func (s *Stability) AcceptsControl(model, mode string) bool {
+	return slices.Contains(generator.ControlModes, mode)
+}
+
 func (s *Stability) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
 	if err := s.ValidateRequest(req); err != nil {
 		return nil, err
 	}
+	if req.Control != nil || req.StyleImage != nil {
+		return s.control(ctx, req)
+	}
 
 	startTime := time.Now()
 
@@ -94,7 +108,76 @@ func (s *Stability) Generate(ctx context.Context, req *generator.Request) (*gene
 		writer.WriteField("negative_prompt", req.NegativePrompt)
 	}
 
-	if req.AspectRatio != "" {
+	// Image-to-image results keep the aspect ratio of the init image
+	if req.InitImage != nil {
+		if err := writeFormImage(writer, "image", *req.InitImage); err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+		writer.WriteField("strength", strconv.FormatFloat(*req.Strength, 'f', -1, 64))
+		if endpoint == "/v2beta/stable-image/generate/sd3" {
+			writer.WriteField("mode", "image-to-image")
+		}
+	} else if req.AspectRatio != "" {
+		writer.WriteField("aspect_ratio", req.AspectRatio)
+	}
+
+	if req.Seed != nil {
+		writer.WriteField("seed", fmt.Sprintf("%d", *req.Seed))
+	}
+
+	writer.WriteField("output_format", "png")
+
+	writer.Close()
+
+	return s.submit(ctx, req, endpoint, &body, writer.FormDataContentType(), startTime)
+}
+
+// AcceptsStyleImage reports true for every model; style images go to the
+// style control endpoint
+func (s *Stability) AcceptsStyleImage(model string) bool {
+	return true
+}
+
+// control generates guided by the control image via the control endpoint
+// of its mode, whichever Stability model is selected. A style image is
+// style control.
+func (s *Stability) control(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if req.InitImage != nil {
+		return nil, fmt.Errorf("Stability control generation takes no init image")
+	}
+	control := req.Control
+	if req.StyleImage != nil {
+		if control != nil {
+			return nil, fmt.Errorf("Stability takes either a control image or a style image")
+		}
+		control = &generator.Control{Image: *req.StyleImage, Mode: generator.ControlStyle}
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", control.Image); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	writer.WriteField("prompt", req.Prompt)
+
+	if req.NegativePrompt != "" {
+		writer.WriteField("negative_prompt", req.NegativePrompt)
+	}
+
+	// Style control calls the strength fidelity and may change the aspect
+	// ratio; structure and sketch keep the one of the image
+	if control.Strength != nil {
+		field := "control_strength"
+		if control.Mode == generator.ControlStyle {
+			field = "fidelity"
+		}
+		writer.WriteField(field, strconv.FormatFloat(*control.Strength, 'f', -1, 64))
+	}
+	if control.Mode == generator.ControlStyle && req.AspectRatio != "" {
 		writer.WriteField("aspect_ratio", req.AspectRatio)
 	}
 
@@ -106,18 +189,391 @@ func (s *Stability) Generate(ctx context.Context, req *generator.Request) (*gene
 
 	writer.Close()
 
+	return s.submit(ctx, req, "/v2beta/stable-image/control/"+control.Mode, &body, writer.FormDataContentType(), startTime)
+}
+
+// Edit inpaints the input image via the edit/inpaint endpoint, whichever
+// Stability model is selected. The white areas of the mask are repainted;
+// without a mask, the transparent areas of the image are.
+func (s *Stability) Edit(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if err := checkInputImages("Stability", req, 1, true); err != nil {
+		return nil, err
+	}
+	if req.Count > 1 {
+		return nil, fmt.Errorf("Stability inpaints one image per request")
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if req.Mask != nil {
+		if err := writeFormImage(writer, "mask", *req.Mask); err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+	}
+
+	writer.WriteField("prompt", req.Prompt)
+
+	if req.NegativePrompt != "" {
+		writer.WriteField("negative_prompt", req.NegativePrompt)
+	}
+
+	if req.Seed != nil {
+		writer.WriteField("seed", fmt.Sprintf("%d", *req.Seed))
+	}
+
+	writer.WriteField("output_format", "png")
+
+	writer.Close()
+
+	return s.submit(ctx, req, "/v2beta/stable-image/edit/inpaint", &body, writer.FormDataContentType(), startTime)
+}
+
+// Endpoint returns the endpoint of a request: outpaint, control (also for
+// style images),
+// search-and-replace, search-and-recolor, inpaint for other input images,
+// else the generate endpoint of the model
+func (s *Stability) Endpoint(req *generator.Request) string {
+	switch {
+	case req.Outpaint != nil:
+		return "POST " + s.baseURL + "/v2beta/stable-image/edit/outpaint"
+	case req.Control != nil:
+		return "POST " + s.baseURL + "/v2beta/stable-image/control/" + req.Control.Mode
+	case req.StyleImage != nil:
+		return "POST " + s.baseURL + "/v2beta/stable-image/control/" + generator.ControlStyle
+	case req.SearchPrompt != "":
+		return "POST " + s.baseURL + "/v2beta/stable-image/edit/search-and-replace"
+	case req.SelectPrompt != "":
+		return "POST " + s.baseURL + "/v2beta/stable-image/edit/search-and-recolor"
+	case len(req.InputImages) > 0:
+		return "POST " + s.baseURL + "/v2beta/stable-image/edit/inpaint"
+	}
+	return "POST " + s.baseURL + s.getEndpoint(s.extractModelName(req.Model))
+}
+
+// SearchReplace replaces the object described by the search prompt with
+// the prompt via the edit/search-and-replace endpoint, whichever Stability
+// model is selected
+func (s *Stability) SearchReplace(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if err := checkInputImages("Stability", req, 1, false); err != nil {
+		return nil, err
+	}
+	if req.Count > 1 {
+		return nil, fmt.Errorf("Stability replaces in one image per request")
+	}
+	if req.SearchPrompt == "" {
+		return nil, fmt.Errorf("Stability search-and-replace requires a search prompt")
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	writer.WriteField("prompt", req.Prompt)
+	writer.WriteField("search_prompt", req.SearchPrompt)
+
+	if req.NegativePrompt != "" {
+		writer.WriteField("negative_prompt", req.NegativePrompt)
+	}
+
+	if req.Seed != nil {
+		writer.WriteField("seed", fmt.Sprintf("%d", *req.Seed))
+	}
+
+	writer.WriteField("output_format", "png")
+
+	writer.Close()
+
+	return s.submit(ctx, req, "/v2beta/stable-image/edit/search-and-replace", &body, writer.FormDataContentType(), startTime)
+}
+
+// Recolor changes the colors of the object described by the select prompt
+// as the prompt describes via the edit/search-and-recolor endpoint,
+// whichever Stability model is selected
+func (s *Stability) Recolor(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if err := checkInputImages("Stability", req, 1, false); err != nil {
+		return nil, err
+	}
+	if req.Count > 1 {
+		return nil, fmt.Errorf("Stability recolors one image per request")
+	}
+	if req.SelectPrompt == "" {
+		return nil, fmt.Errorf("Stability search-and-recolor requires a select prompt")
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	writer.WriteField("prompt", req.Prompt)
+	writer.WriteField("select_prompt", req.SelectPrompt)
+
+	if req.NegativePrompt != "" {
+		writer.WriteField("negative_prompt", req.NegativePrompt)
+	}
+
+	if req.Seed != nil {
+		writer.WriteField("seed", fmt.Sprintf("%d", *req.Seed))
+	}
+
+	writer.WriteField("output_format", "png")
+
+	writer.Close()
+
+	return s.submit(ctx, req, "/v2beta/stable-image/edit/search-and-recolor", &body, writer.FormDataContentType(), startTime)
+}
+
+// stabilityMaxOutpaint is the most pixels the outpaint endpoint adds on
+// one side
+const stabilityMaxOutpaint = 2000
+
+// Outpaint extends the input image via the edit/outpaint endpoint,
+// whichever Stability model is selected; the prompt is optional
+func (s *Stability) Outpaint(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if err := checkInputImages("Stability", req, 1, false); err != nil {
+		return nil, err
+	}
+	if req.Count > 1 {
+		return nil, fmt.Errorf("Stability outpaints one image per request")
+	}
+	out := req.Outpaint
+	if out == nil || out.Left+out.Right+out.Up+out.Down == 0 {
+		return nil, fmt.Errorf("Stability outpainting requires at least one extent")
+	}
+	for _, n := range []int{out.Left, out.Right, out.Up, out.Down} {
+		if n < 0 || n > stabilityMaxOutpaint {
+			return nil, fmt.Errorf("Stability outpaints 0 to %d pixels per side, got %d", stabilityMaxOutpaint, n)
+		}
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	sides := []struct {
+		field string
+		n     int
+	}{{"left", out.Left}, {"right", out.Right}, {"up", out.Up}, {"down", out.Down}}
+	for _, side := range sides {
+		if side.n > 0 {
+			writer.WriteField(side.field, strconv.Itoa(side.n))
+		}
+	}
+
+	if req.Prompt != "" {
+		writer.WriteField("prompt", req.Prompt)
+	}
+
+	if out.Creativity != nil {
+		writer.WriteField("creativity", strconv.FormatFloat(*out.Creativity, 'f', -1, 64))
+	}
+
+	if req.Seed != nil {
+		writer.WriteField("seed", fmt.Sprintf("%d", *req.Seed))
+	}
+
+	writer.WriteField("output_format", "png")
+
+	writer.Close()
+
+	return s.submit(ctx, req, "/v2beta/stable-image/edit/outpaint", &body, writer.FormDataContentType(), startTime)
+}
+
+// RemoveBackground cuts out the subject of the input image via the
+// edit/remove-background endpoint, whichever Stability model is selected
+func (s *Stability) RemoveBackground(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if err := checkInputImages("Stability", req, 1, false); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	writer.WriteField("output_format", "png")
+
+	writer.Close()
+
+	return s.submit(ctx, req, "/v2beta/stable-image/edit/remove-background", &body, writer.FormDataContentType(), startTime)
+}
+
+// stabilityVideoPollInterval is how often a pending image-to-video job is
+// checked; Stability asks for no more than one check every 10 seconds
+const stabilityVideoPollInterval = 10 * time.Second
+
+// GenerateVideo animates the input image into an MP4 via the
+// image-to-video endpoint, whichever Stability model is selected. The
+// image must be 1024x576, 576x1024 or 768x768.
+func (s *Stability) GenerateVideo(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	if len(req.InputImages) == 0 && len(req.ImageRefs) > 0 {
+		return nil, fmt.Errorf("Stability image-to-video takes the image as a file, not a URL")
+	}
+	if len(req.InputImages) == 0 {
+		return nil, fmt.Errorf("Stability AI only animates images (--image); use a Luma or Replicate model for text-to-video")
+	}
+	if err := checkInputImages("Stability", req, 1, false); err != nil {
+		return nil, err
+	}
+	if req.VideoDuration > 0 || req.FPS > 0 {
+		return nil, fmt.Errorf("Stability image-to-video has a fixed length and frame rate")
+	}
+
+	startTime := time.Now()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writeFormImage(writer, "image", req.InputImages[0]); err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if req.Seed != nil {
+		writer.WriteField("seed", strconv.FormatInt(*req.Seed, 10))
+	}
+
+	writer.Close()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v2beta/image-to-video", &body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := s.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyStabilityError(resp.StatusCode, respBody)
+	}
+
+	var job struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &job); err != nil || job.ID == "" {
+		return nil, fmt.Errorf("failed to decode image-to-video job: %s", string(respBody))
+	}
+
+	data, err := s.waitForVideo(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generator.Response{
+		Videos:      []generator.Video{{Image: generator.Image{Data: data, Format: "mp4"}}},
+		Model:       req.Model,
+		Provider:    s.Name(),
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// waitForVideo polls an image-to-video job until it returns the video,
+// answering 202 while the job is in progress
+func (s *Stability) waitForVideo(ctx context.Context, id string) ([]byte, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.httpClient.Clock().After(stabilityVideoPollInterval):
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/v2beta/image-to-video/result/"+id, nil)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+		httpReq.Header.Set("Accept", "video/*")
+
+		resp, err := s.httpClient.Do(ctx, httpReq)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusAccepted:
+			continue
+		case resp.StatusCode != http.StatusOK:
+			return nil, classifyStabilityError(resp.StatusCode, respBody)
+		case resp.Header.Get("Finish-Reason") == "CONTENT_FILTERED":
+			return nil, &APIError{
+				Provider:   "Stability",
+				Kind:       ErrKindContentPolicy,
+				StatusCode: resp.StatusCode,
+				Code:       "CONTENT_FILTERED",
+				Message:    "generated video was blocked by the content filter",
+				Hint:       stabilityContentHint,
+			}
+		}
+		return respBody, nil
+	}
+}
+
+// submit posts a multipart form to an endpoint returning the image bytes
+func (s *Stability) submit(ctx context.Context, req *generator.Request, endpoint string, body io.Reader, contentType string, startTime time.Time) (*generator.Response, error) {
 	httpReq, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		s.baseURL+endpoint,
-		&body,
+		body,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
-	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	httpReq.Header.Set("Content-Type", contentType)
 	httpReq.Header.Set("Accept", "image/*")
 
 	resp, err := s.httpClient.Do(ctx, httpReq)
@@ -132,22 +588,26 @@ func (s *Stability) Generate(ctx context.Context, req *generator.Request) (*gene
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var errResp struct {
-			Name    string `json:"name"`
-			Message string `json:"message"`
-		}
-		json.Unmarshal(respBody, &errResp)
-		if errResp.Message != "" {
-			return nil, fmt.Errorf("Stability API error: %s", errResp.Message)
+		return nil, classifyStabilityError(resp.StatusCode, respBody)
+	}
+
+	// Filtered images are returned with 200 and a blurred result
+	if resp.Header.Get("Finish-Reason") == "CONTENT_FILTERED" {
+		return nil, &APIError{
+			Provider:   "Stability",
+			Kind:       ErrKindContentPolicy,
+			StatusCode: resp.StatusCode,
+			Code:       "CONTENT_FILTERED",
+			Message:    "generated image was blocked by the content filter",
+			Hint:       stabilityContentHint,
 		}
-		return nil, fmt.Errorf("Stability API error: status %d", resp.StatusCode)
 	}
 
 	format := "png"
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "jpeg") {
+	respType := resp.Header.Get("Content-Type")
+	if strings.Contains(respType, "jpeg") {
 		format = "jpeg"
-	} else if strings.Contains(contentType, "webp") {
+	} else if strings.Contains(respType, "webp") {
 		format = "webp"
 	}
 
@@ -168,6 +628,54 @@ func (s *Stability) Generate(ctx context.Context, req *generator.Request) (*gene
 	}, nil
 }
 
+// Probe checks the API key via the account balance endpoint; Stability
+// has no per-model metadata endpoint, so the model is checked against
+// the known model list
+func (s *Stability) Probe(ctx context.Context, model string) (*ProbeResult, error) {
+	if err := s.ValidateRequest(&generator.Request{}); err != nil {
+		return nil, err
+	}
+
+	result := newProbeResult(s, model)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/v1/user/balance", nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := applyProbeStatus(result, "Stability", resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	var balance struct {
+		Credits float64 `json:"credits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&balance); err == nil {
+		result.Message = fmt.Sprintf("%.2f credits remaining", balance.Credits)
+	}
+
+	known := false
+	for _, m := range s.SupportedModels() {
+		if m.ID == result.Model {
+			known = true
+			break
+		}
+	}
+	if !known {
+		result.Available = false
+		result.Message = "unknown Stability model"
+	}
+
+	return result, nil
+}
+
 func (s *Stability) extractModelName(model string) string {
 	if strings.HasPrefix(model, "stability/") {
 		return strings.TrimPrefix(model, "stability/")
@@ -185,3 +693,44 @@ func (s *Stability) getEndpoint(model string) string {
 		return "/v2beta/stable-image/generate/core"
 	}
 }
+
+const (
+	stabilityCreditsURL  = "https://platform.stability.ai/account/credits"
+	stabilityContentHint = "rephrase the prompt, or switch to a model from another provider " +
+		"(e.g. -m replicate/flux-schnell); see 'llm-imager list models'"
+)
+
+// classifyStabilityError maps a Stability error response to an APIError
+func classifyStabilityError(status int, body []byte) *APIError {
+	var errResp struct {
+		Name    string   `json:"name"`
+		Message string   `json:"message"`
+		Errors  []string `json:"errors"`
+	}
+	json.Unmarshal(body, &errResp)
+
+	apiErr := &APIError{
+		Provider:   "Stability",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+		Code:       errResp.Name,
+		Message:    errResp.Message,
+	}
+	if apiErr.Message == "" && len(errResp.Errors) > 0 {
+		apiErr.Message = strings.Join(errResp.Errors, "; ")
+	}
+
+	switch {
+	case status == http.StatusPaymentRequired || errResp.Name == "payment_required":
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "your Stability account is out of credits; top up at " + stabilityCreditsURL +
+			" or switch to a model from another provider (e.g. -m replicate/flux-schnell)"
+	case errResp.Name == "content_moderation":
+		apiErr.Kind = ErrKindContentPolicy
+		apiErr.Hint = stabilityContentHint
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check STABILITY_API_KEY or providers.stability.api_key"
+	}
+
+	return apiErr
+}
@@ -0,0 +1,434 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piligrim/llm-imager/internal/generator"
+	"github.com/piligrim/llm-imager/pkg/httputil"
+)
+
+const (
+	stableHordeBaseURL = "https://aihorde.net/api/v2"
+
+	// stableHordeAnonymousKey is the shared key for users without an
+	// account; anonymous requests have the lowest queue priority
+	stableHordeAnonymousKey = "0000000000"
+
+	// stableHordeClientAgent identifies the client as the API asks
+	stableHordeClientAgent = "llm-imager:1:https://github.com/piligrim/llm-imager"
+
+	// stableHordeMaxImages is the largest batch a single request may ask for
+	stableHordeMaxImages = 20
+
+	// stableHordeAnyModel lets any available worker pick the model
+	stableHordeAnyModel = "any"
+)
+
+// StableHorde implements the Provider interface for the AI Horde
+// (Stable Horde), a crowdsourced cluster of community workers
+type StableHorde struct {
+	apiKey       string
+	baseURL      string
+	httpClient   *httputil.Client
+	pollInterval time.Duration
+	maxWait      time.Duration
+}
+
+// NewStableHorde creates a new Stable Horde provider
+func NewStableHorde(cfg *ProviderConfig) *StableHorde {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = stableHordeBaseURL
+	}
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = stableHordeAnonymousKey
+	}
+	maxWait := cfg.MaxWait
+	if maxWait <= 0 {
+		maxWait = 10 * time.Minute
+	}
+
+	return &StableHorde{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		httpClient: httputil.NewClient(
+			httputil.WithRetries(cfg.MaxRetries),
+			httputil.WithTransport(cfg.Transport),
+			httputil.WithClock(cfg.Clock),
+			httputil.WithMiddleware(cfg.Middleware),
+		),
+		pollInterval: 5 * time.Second,
+		maxWait:      maxWait,
+	}
+}
+
+func (s *StableHorde) Name() string {
+	return "stablehorde"
+}
+
+func (s *StableHorde) SupportedModels() []Model {
+	return catalogModels(s.Name())
+}
+
+func (s *StableHorde) ValidateRequest(req *generator.Request) error {
+	if req.Count > stableHordeMaxImages {
+		return fmt.Errorf("Stable Horde generates at most %d images per request", stableHordeMaxImages)
+	}
+
+	if req.Size != "" {
+		width, height, err := splitSize(req.Size)
+		if err != nil {
+			return err
+		}
+		if width%64 != 0 || height%64 != 0 || width < 64 || height < 64 || width > 3072 || height > 3072 {
+			return fmt.Errorf("invalid size %s: width and height must be multiples of 64 between 64 and 3072", req.Size)
+		}
+	}
+
+	return nil
+}
+
+type stableHordeRequest struct {
+	Prompt string            `json:"prompt"`
+	Params stableHordeParams `json:"params"`
+	Models []string          `json:"models,omitempty"`
+	R2     bool              `json:"r2"`
+}
+
+type stableHordeParams struct {
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Steps  int    `json:"steps,omitempty"`
+	N      int    `json:"n,omitempty"`
+	Seed   string `json:"seed,omitempty"`
+	Tiling bool   `json:"tiling,omitempty"`
+}
+
+type stableHordeCheck struct {
+	Done          bool    `json:"done"`
+	Faulted       bool    `json:"faulted"`
+	IsPossible    bool    `json:"is_possible"`
+	QueuePosition int     `json:"queue_position"`
+	WaitTime      int     `json:"wait_time"`
+	Kudos         float64 `json:"kudos"`
+}
+
+type stableHordeStatus struct {
+	Faulted     bool    `json:"faulted"`
+	Kudos       float64 `json:"kudos"`
+	Generations []struct {
+		Img      string `json:"img"` // URL, or base64 without r2
+		Seed     string `json:"seed"`
+		Censored bool   `json:"censored"`
+	} `json:"generations"`
+}
+
+func (s *StableHorde) Generate(ctx context.Context, req *generator.Request) (*generator.Response, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	count := req.Count
+	if count <= 0 {
+		count = 1
+	}
+
+	// The Horde has no negative prompt field; it is appended after "###"
+	prompt := req.Prompt
+	if req.NegativePrompt != "" {
+		prompt += " ### " + req.NegativePrompt
+	}
+
+	apiReq := stableHordeRequest{
+		Prompt: prompt,
+		Params: stableHordeParams{
+			Steps:  req.Steps,
+			N:      count,
+			Tiling: req.Tile,
+		},
+		R2: true,
+	}
+	if model := s.extractModelName(req.Model); model != stableHordeAnyModel {
+		apiReq.Models = []string{model}
+	}
+	if req.Size != "" {
+		apiReq.Params.Width, apiReq.Params.Height = parseSize(req.Size)
+	}
+	if req.Seed != nil {
+		apiReq.Params.Seed = strconv.FormatInt(*req.Seed, 10)
+	}
+
+	body, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := s.newRequest(ctx, http.MethodPost, "/generate/async", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return nil, classifyStableHordeError(resp.StatusCode, respBody)
+	}
+
+	var submitted struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &submitted); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if err := s.waitForRequest(ctx, submitted.ID); err != nil {
+		return nil, err
+	}
+
+	status, err := s.getStatus(ctx, submitted.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]generator.Image, 0, len(status.Generations))
+	for _, gen := range status.Generations {
+		// Censored generations carry a placeholder image
+		if gen.Censored {
+			continue
+		}
+
+		var image generator.Image
+		if req.URLOnly && (strings.HasPrefix(gen.Img, "http://") || strings.HasPrefix(gen.Img, "https://")) {
+			image = hostedImage(gen.Img, "webp", len(images), 0)
+		} else {
+			data, format, err := s.fetchImage(ctx, gen.Img)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download image: %w", err)
+			}
+			image = generator.Image{
+				Data:   data,
+				Format: format,
+				Index:  len(images),
+			}
+		}
+		if seed, err := strconv.ParseInt(gen.Seed, 10, 64); err == nil {
+			image.Seed = &seed
+		}
+		images = append(images, image)
+	}
+
+	if len(images) == 0 {
+		return nil, &APIError{
+			Provider: "Stable Horde",
+			Kind:     ErrKindContentPolicy,
+			Message:  "all images were censored by the workers",
+			Hint:     "rephrase the prompt; anonymous and low-kudos requests are filtered strictly",
+		}
+	}
+
+	return &generator.Response{
+		Images:      images,
+		Model:       req.Model,
+		Provider:    s.Name(),
+		Kudos:       status.Kudos,
+		GeneratedAt: time.Now(),
+		Duration:    time.Since(startTime),
+	}, nil
+}
+
+// waitForRequest polls the lightweight check endpoint until the request
+// is done. Requests still queued after maxWait are cancelled so they
+// don't consume kudos later.
+func (s *StableHorde) waitForRequest(ctx context.Context, id string) error {
+	deadline := s.httpClient.Clock().Now().Add(s.maxWait)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.cancel(id)
+			return ctx.Err()
+		case <-s.httpClient.Clock().After(s.pollInterval):
+		}
+
+		httpReq, err := s.newRequest(ctx, http.MethodGet, "/generate/check/"+id, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.httpClient.Do(ctx, httpReq)
+		if err != nil {
+			return err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return classifyStableHordeError(resp.StatusCode, respBody)
+		}
+
+		var check stableHordeCheck
+		if err := json.Unmarshal(respBody, &check); err != nil {
+			return fmt.Errorf("failed to decode check: %w", err)
+		}
+
+		switch {
+		case check.Done:
+			return nil
+		case check.Faulted:
+			return fmt.Errorf("Stable Horde generation failed on the worker")
+		case !check.IsPossible:
+			s.cancel(id)
+			return fmt.Errorf("no Stable Horde worker can serve this request; try another model or a smaller size")
+		case s.httpClient.Clock().Now().After(deadline):
+			s.cancel(id)
+			return fmt.Errorf("Stable Horde request not finished after %s (queue position %d); "+
+				"raise providers.stablehorde.max_wait or use an API key for higher priority",
+				s.maxWait, check.QueuePosition)
+		}
+	}
+}
+
+func (s *StableHorde) getStatus(ctx context.Context, id string) (*stableHordeStatus, error) {
+	httpReq, err := s.newRequest(ctx, http.MethodGet, "/generate/status/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyStableHordeError(resp.StatusCode, respBody)
+	}
+
+	var status stableHordeStatus
+	if err := json.Unmarshal(respBody, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode status: %w", err)
+	}
+	if status.Faulted {
+		return nil, fmt.Errorf("Stable Horde generation failed on the worker")
+	}
+
+	return &status, nil
+}
+
+// cancel deletes a pending request; errors are ignored since the request
+// expires on its own
+func (s *StableHorde) cancel(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	httpReq, err := s.newRequest(ctx, http.MethodDelete, "/generate/status/"+id, nil)
+	if err != nil {
+		return
+	}
+	httputil.ApplyUserAgent(httpReq)
+	if resp, err := http.DefaultClient.Do(httpReq); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (s *StableHorde) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("apikey", s.apiKey)
+	httpReq.Header.Set("Client-Agent", stableHordeClientAgent)
+	return httpReq, nil
+}
+
+// fetchImage returns the image of a generation, which is a download URL
+// or, from older workers, base64 data
+func (s *StableHorde) fetchImage(ctx context.Context, img string) ([]byte, string, error) {
+	if !strings.HasPrefix(img, "http://") && !strings.HasPrefix(img, "https://") {
+		data, err := base64.StdEncoding.DecodeString(img)
+		return data, "webp", err
+	}
+
+	resp, err := s.httpClient.Get(ctx, img)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := "webp"
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "png") {
+		format = "png"
+	} else if strings.Contains(contentType, "jpeg") {
+		format = "jpeg"
+	}
+
+	return data, format, nil
+}
+
+func (s *StableHorde) extractModelName(model string) string {
+	return strings.TrimPrefix(model, s.Name()+"/")
+}
+
+// classifyStableHordeError maps a Stable Horde error response to an APIError
+func classifyStableHordeError(status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   "Stable Horde",
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+	}
+
+	var apiResp struct {
+		Message string `json:"message"`
+		RC      string `json:"rc"`
+	}
+	json.Unmarshal(body, &apiResp)
+	apiErr.Message = apiResp.Message
+	apiErr.Code = apiResp.RC
+
+	switch {
+	case strings.Contains(apiResp.RC, "Kudos"):
+		apiErr.Kind = ErrKindQuota
+		apiErr.Hint = "not enough kudos; lower the size, steps or count, or earn kudos by running a worker"
+	case strings.Contains(apiResp.RC, "CorruptPrompt"):
+		apiErr.Kind = ErrKindContentPolicy
+	case apiErr.Kind == ErrKindAuth:
+		apiErr.Hint = "check STABLE_HORDE_API_KEY, or unset it to use the anonymous key"
+	}
+
+	return apiErr
+}
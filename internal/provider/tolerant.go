@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// debugOutput receives raw responses and decoding warnings; nil disables them
+var debugOutput io.Writer
+
+// SetDebugOutput enables debug logging of raw provider responses
+func SetDebugOutput(w io.Writer) {
+	debugOutput = w
+}
+
+func debugf(format string, args ...any) {
+	if debugOutput != nil {
+		fmt.Fprintf(debugOutput, "debug: "+format+"\n", args...)
+	}
+}
+
+// decodeTolerant decodes a JSON response like json.Unmarshal, but skips
+// fields whose type changed instead of failing. Skipped fields and the
+// raw body are logged in debug mode.
+func decodeTolerant(providerName string, body []byte, v any) error {
+	debugf("%s response: %s", providerName, body)
+
+	err := json.Unmarshal(body, v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		debugf("%s response: ignoring field %q: expected %s, got %s",
+			providerName, typeErr.Field, typeErr.Type.Kind(), typeErr.Value)
+		return nil
+	}
+	return err
+}
+
+// imageURLKeys are the object keys under which response variants carry
+// image URLs
+var imageURLKeys = map[string]bool{
+	"url":       true,
+	"uri":       true,
+	"image":     true,
+	"image_url": true,
+	"images":    true,
+	"output":    true,
+}
+
+// findImageURLs collects image URLs from an arbitrary JSON value: data
+// URLs anywhere, and http(s) URLs under one of imageURLKeys
+func findImageURLs(v any) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	var walk func(v any, key string)
+	walk = func(v any, key string) {
+		switch n := v.(type) {
+		case string:
+			isData := strings.HasPrefix(n, "data:image/")
+			isHTTP := strings.HasPrefix(n, "https://") || strings.HasPrefix(n, "http://")
+			if (isData || isHTTP && imageURLKeys[key]) && !seen[n] {
+				seen[n] = true
+				urls = append(urls, n)
+			}
+		case []any:
+			for _, item := range n {
+				walk(item, key)
+			}
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(n)) {
+				walk(n[k], k)
+			}
+		}
+	}
+	walk(v, "")
+
+	return urls
+}
+
+// noImagesError explains an empty response, quoting any text the model
+// returned instead of an image
+func noImagesError(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("no images in response (run with --debug to see the raw response)")
+	}
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return fmt.Errorf("no images in response, model replied: %q", text)
+}
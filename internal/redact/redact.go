@@ -0,0 +1,50 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Rule replaces every match of a regular expression
+type Rule struct {
+	Pattern     string
+	Replacement string // May reference groups, e.g. "$1"; defaults to "[redacted]"
+}
+
+// DefaultReplacement is used for rules without a replacement
+const DefaultReplacement = "[redacted]"
+
+// Redactor applies redaction rules in order
+type Redactor struct {
+	patterns     []*regexp.Regexp
+	replacements []string
+}
+
+// Compile validates rules and returns a redactor for them
+func Compile(rules []Rule) (*Redactor, error) {
+	r := &Redactor{}
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", rule.Pattern, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = DefaultReplacement
+		}
+		r.patterns = append(r.patterns, re)
+		r.replacements = append(r.replacements, replacement)
+	}
+	return r, nil
+}
+
+// Apply returns s with all rules applied. A nil redactor returns s as is.
+func (r *Redactor) Apply(s string) string {
+	if r == nil {
+		return s
+	}
+	for i, re := range r.patterns {
+		s = re.ReplaceAllString(s, r.replacements[i])
+	}
+	return s
+}
@@ -0,0 +1,234 @@
+package run
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Files written to every run directory
+const (
+	ManifestFile = "manifest.json"
+	LogFile      = "run.log"
+	ReportFile   = "report.md"
+)
+
+// Run statuses
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Manifest describes a finished run
+type Manifest struct {
+	ID         string    `json:"id"`
+	Command    string    `json:"command"`
+	Model      string    `json:"model,omitempty"`
+	Prompt     string    `json:"prompt,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Images     []string  `json:"images"`            // Relative to the run directory when inside it
+	Results    string    `json:"results,omitempty"` // Batch results file
+	Succeeded  int       `json:"succeeded"`
+	Skipped    int       `json:"skipped"`
+	Failed     int       `json:"failed"`
+	Cost       float64   `json:"cost"` // USD, estimated from the model catalog
+}
+
+// Run is the working directory of one invocation
+type Run struct {
+	ID        string
+	Dir       string
+	StartedAt time.Time
+
+	logged  bool
+	log     *os.File
+	stdout  *os.File
+	stderr  *os.File
+	pipes   []*os.File
+	copying sync.WaitGroup
+}
+
+// New creates <root>/<id> for a new run. IDs start with the UTC start
+// time, so run directories sort chronologically.
+func New(root string) (*Run, error) {
+	now := time.Now()
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	id := now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+
+	dir := filepath.Join(root, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	return &Run{ID: id, Dir: dir, StartedAt: now}, nil
+}
+
+// History returns the manifests of the runs under root, oldest first.
+// Runs without a readable manifest, such as unfinished ones, are skipped.
+func History(root string) ([]Manifest, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, entry.Name(), ManifestFile))
+		if err != nil {
+			continue
+		}
+		var m Manifest
+		if json.Unmarshal(data, &m) == nil {
+			manifests = append(manifests, m)
+		}
+	}
+	return manifests, nil
+}
+
+// Capture copies everything written to stdout and stderr into the run log
+// until Finish is called
+func (r *Run) Capture() error {
+	log, err := os.Create(filepath.Join(r.Dir, LogFile))
+	if err != nil {
+		return fmt.Errorf("failed to create run log: %w", err)
+	}
+	r.log, r.stdout, r.stderr = log, os.Stdout, os.Stderr
+	r.logged = true
+
+	tee := func(orig *os.File) (*os.File, error) {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		r.pipes = append(r.pipes, pw)
+		r.copying.Add(1)
+		go func() {
+			defer r.copying.Done()
+			io.Copy(io.MultiWriter(orig, log), pr)
+			pr.Close()
+		}()
+		return pw, nil
+	}
+
+	if os.Stdout, err = tee(r.stdout); err != nil {
+		r.restore()
+		return err
+	}
+	if os.Stderr, err = tee(r.stderr); err != nil {
+		r.restore()
+		return err
+	}
+	return nil
+}
+
+// restore stops capturing output and closes the log
+func (r *Run) restore() {
+	if r.log == nil {
+		return
+	}
+	os.Stdout, os.Stderr = r.stdout, r.stderr
+	for _, pw := range r.pipes {
+		pw.Close()
+	}
+	r.copying.Wait()
+	r.log.Close()
+	r.log = nil
+}
+
+// Finish stops capturing output and writes the manifest and report. Image
+// paths inside the run directory are recorded relative to it.
+func (r *Run) Finish(m Manifest) error {
+	r.restore()
+
+	m.ID = r.ID
+	m.StartedAt = r.StartedAt
+	m.FinishedAt = time.Now()
+	if m.Status == "" {
+		m.Status = StatusOK
+		if m.Error != "" {
+			m.Status = StatusFailed
+		}
+	}
+	for i, path := range m.Images {
+		m.Images[i] = r.relative(path)
+	}
+	if m.Images == nil {
+		m.Images = []string{}
+	}
+	m.Results = r.relative(m.Results)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, ManifestFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, ReportFile), []byte(report(m, r.logged)), 0644); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	return nil
+}
+
+// relative returns path relative to the run directory if it is inside it
+func (r *Run) relative(path string) string {
+	if path == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(r.Dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// report formats a manifest as a short Markdown summary
+func report(m Manifest, logged bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Run %s\n\n", m.ID)
+	fmt.Fprintf(&b, "- Command: %s\n", m.Command)
+	if m.Model != "" {
+		fmt.Fprintf(&b, "- Model: %s\n", m.Model)
+	}
+	fmt.Fprintf(&b, "- Started: %s\n", m.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Duration: %s\n", m.FinishedAt.Sub(m.StartedAt).Round(100*time.Millisecond))
+	fmt.Fprintf(&b, "- Status: %s\n", m.Status)
+	if m.Results != "" {
+		fmt.Fprintf(&b, "- Rows: %d succeeded, %d skipped, %d failed\n", m.Succeeded, m.Skipped, m.Failed)
+		fmt.Fprintf(&b, "- Results: %s\n", m.Results)
+	}
+	fmt.Fprintf(&b, "- Estimated cost: $%.2f\n", m.Cost)
+	if m.Error != "" {
+		fmt.Fprintf(&b, "\n## Error\n\n```\n%s\n```\n", m.Error)
+	}
+	if len(m.Images) > 0 {
+		b.WriteString("\n## Images\n\n")
+		for _, path := range m.Images {
+			fmt.Fprintf(&b, "- %s\n", path)
+		}
+	}
+	if logged {
+		fmt.Fprintf(&b, "\nLog: %s\n", LogFile)
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package tcauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const signingAlgorithm = "TC3-HMAC-SHA256"
+
+// Credentials are Tencent Cloud API keys
+type Credentials struct {
+	SecretID  string
+	SecretKey string
+}
+
+// Sign adds Tencent Cloud TC3-HMAC-SHA256 headers to a POST request.
+// body must be the exact request payload and the Content-Type header must
+// already be set. Action, version and region are sent as X-TC-* headers.
+func Sign(req *http.Request, body []byte, creds *Credentials, service, action, version, region string, now time.Time) {
+	now = now.UTC()
+	date := now.Format("2006-01-02")
+
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", version)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	if region != "" {
+		req.Header.Set("X-TC-Region", region)
+	}
+
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\n",
+		strings.ToLower(req.Header.Get("Content-Type")), req.URL.Host)
+	signedHeaders := "content-type;host"
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/tc3_request", date, service)
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		strconv.FormatInt(now.Unix(), 10),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("TC3"+creds.SecretKey), date)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, creds.SecretID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
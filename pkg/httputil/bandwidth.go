@@ -0,0 +1,127 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bandwidth is the limiter shared by all clients, nil when unlimited
+var bandwidth atomic.Pointer[limiter]
+
+// SetBandwidthLimit caps the combined rate of the request and response
+// bodies of all clients, uploads and downloads alike, at bytesPerSecond.
+// 0 removes the limit.
+func SetBandwidthLimit(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		bandwidth.Store(nil)
+		return
+	}
+	bandwidth.Store(&limiter{rate: float64(bytesPerSecond)})
+}
+
+var bandwidthPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kmg]?)(b|bit)?(/s|ps)?$`)
+
+// bandwidthUnits are the multipliers of the size prefixes; link speeds
+// are decimal
+var bandwidthUnits = map[string]float64{"": 1, "k": 1e3, "m": 1e6, "g": 1e9}
+
+// ParseBandwidth parses a rate such as 500KB/s, 2MB/s, 8Mbit/s or 8Mbps
+// into bytes per second. A bare number is bytes per second, and "" is 0.
+func ParseBandwidth(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	m := bandwidthPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid bandwidth %q, expected e.g. 500KB/s, 2MB/s or 8Mbit/s", s)
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q: %w", s, err)
+	}
+	value *= bandwidthUnits[m[2]]
+	// 8Mbit/s and 8Mbps are bits, 1MB/s is bytes
+	if m[3] == "bit" || (m[3] == "b" && m[4] == "ps") {
+		value /= 8
+	}
+	return int64(value), nil
+}
+
+// limiter paces bytes to a rate
+type limiter struct {
+	mu   sync.Mutex
+	rate float64   // Bytes per second
+	next time.Time // When the bytes granted so far are sent at the rate
+}
+
+// chunk is the most bytes a throttled read or write takes at once, a
+// quarter second at the rate, so transfers stay smooth
+func (l *limiter) chunk() int {
+	return max(int(l.rate/4), 1024)
+}
+
+// wait blocks until n more bytes fit the rate
+func (l *limiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / l.rate * float64(time.Second)))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttledBody paces reads of a request or response body
+type throttledBody struct {
+	ctx     context.Context
+	limiter *limiter
+	body    io.ReadCloser
+}
+
+// throttle wraps body in the bandwidth limit, if one is set
+func throttle(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	l := bandwidth.Load()
+	if l == nil || body == nil || body == http.NoBody {
+		return body
+	}
+	return &throttledBody{ctx: ctx, limiter: l, body: body}
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if len(p) > b.limiter.chunk() {
+		p = p[:b.limiter.chunk()]
+	}
+	n, err := b.body.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.wait(b.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (b *throttledBody) Close() error {
+	return b.body.Close()
+}
@@ -1,6 +1,7 @@
 package httputil
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -10,10 +11,17 @@ import (
 
 // Client is an HTTP client with retry, timeout and rate limiting
 type Client struct {
-	httpClient *http.Client
-	maxRetries int
+	httpClient  *http.Client
+	maxRetries  int
+	shouldRetry RetryPolicy
+	middleware  *Middleware
+	clock       Clock
 }
 
+// RetryPolicy decides whether a response with a retryable status code
+// (429 or 5xx) should be retried. The body is already buffered.
+type RetryPolicy func(resp *http.Response, body []byte) bool
+
 // ClientOption configures the client
 type ClientOption func(*Client)
 
@@ -24,6 +32,7 @@ func NewClient(opts ...ClientOption) *Client {
 			Timeout: 60 * time.Second,
 		},
 		maxRetries: 3,
+		clock:      SystemClock,
 	}
 
 	for _, opt := range opts {
@@ -40,6 +49,32 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithTransport replaces the HTTP transport, e.g. with a stub in tests.
+// A nil transport keeps the default.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		if rt != nil {
+			c.httpClient.Transport = rt
+		}
+	}
+}
+
+// WithClock replaces the clock used for retry backoff and returned by
+// Clock. A nil clock keeps the system clock.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+// Clock returns the clock of the client, for callers that poll or set
+// deadlines alongside its requests
+func (c *Client) Clock() Clock {
+	return c.clock
+}
+
 // WithRetries sets the maximum number of retries
 func WithRetries(retries int) ClientOption {
 	return func(c *Client) {
@@ -47,9 +82,47 @@ func WithRetries(retries int) ClientOption {
 	}
 }
 
+// WithRetryPolicy sets a policy that can veto retries for responses
+// that look transient by status code but are not (e.g. exhausted quota)
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.shouldRetry = policy
+	}
+}
+
 // Do executes an HTTP request with retries
+// If all retries are exhausted on a retryable status code, the last
+// response is returned so the caller can inspect the error body.
+// Downloads interrupted partway resume with Range requests where the
+// server supports them, and bodies are paced by SetBandwidthLimit.
+// Requests without a User-Agent get the one of SetUserAgent.
+// Configured middleware rewrites JSON request and response bodies, and
+// responses are recorded for contexts set up with WithRecorder and
+// reported for contexts set up with WithProgress.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req, err := c.mutateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	reportProgress(ctx, req, resp)
+	if err := record(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	if err := c.mutateResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	var lastErr error
+	var lastResp *http.Response
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		if attempt > 0 {
@@ -58,7 +131,7 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
-			case <-time.After(delay):
+			case <-c.clock.After(delay):
 			}
 		}
 
@@ -68,6 +141,8 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 				seeker.Seek(0, io.SeekStart)
 			}
 		}
+		reqClone.Body = throttle(ctx, reqClone.Body)
+		ApplyUserAgent(reqClone)
 
 		resp, err := c.httpClient.Do(reqClone)
 		if err != nil {
@@ -77,14 +152,33 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 
 		// Check for retryable status codes
 		if resp.StatusCode >= 500 || resp.StatusCode == 429 {
+			body, err := io.ReadAll(resp.Body)
 			resp.Body.Close()
+			if err != nil {
+				lastErr = err
+				lastResp = nil
+				continue
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			if c.shouldRetry != nil && !c.shouldRetry(resp, body) {
+				return resp, nil
+			}
+
 			lastErr = fmt.Errorf("server error: %d", resp.StatusCode)
+			lastResp = resp
 			continue
 		}
 
+		resp = c.resumable(ctx, req, resp)
+		resp.Body = throttle(ctx, resp.Body)
 		return resp, nil
 	}
 
+	if lastResp != nil {
+		return lastResp, nil
+	}
+
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
@@ -0,0 +1,18 @@
+package httputil
+
+import "time"
+
+// Clock tells the time and waits. Clients use it for retry backoff and
+// expose it to pollers, so tests can replace real waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the real time
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,228 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Mutation is a single edit of a JSON body. Paths are dot-separated
+// object keys; numeric segments index arrays (e.g. "data.0.url").
+type Mutation struct {
+	Op    string // "set", "rename" or "remove"
+	Path  string // Field to edit
+	To    string // Destination path (rename)
+	Value any    // New value (set)
+}
+
+// Middleware rewrites JSON request and response bodies. It is an escape
+// hatch for APIs that change shape faster than the provider code.
+type Middleware struct {
+	Request  []Mutation // Applied to outgoing JSON bodies
+	Response []Mutation // Applied to successful JSON responses
+}
+
+// WithMiddleware sets the body mutations applied by the client
+func WithMiddleware(m *Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = m
+	}
+}
+
+// MutateRequest applies the request mutations to a JSON body
+func (m *Middleware) MutateRequest(body []byte) ([]byte, error) {
+	if m == nil || len(m.Request) == 0 {
+		return body, nil
+	}
+	out, err := mutateJSON(body, m.Request)
+	if err != nil {
+		return nil, fmt.Errorf("request middleware: %w", err)
+	}
+	return out, nil
+}
+
+// MutateResponse applies the response mutations to a JSON body
+func (m *Middleware) MutateResponse(body []byte) ([]byte, error) {
+	if m == nil || len(m.Response) == 0 {
+		return body, nil
+	}
+	out, err := mutateJSON(body, m.Response)
+	if err != nil {
+		return nil, fmt.Errorf("response middleware: %w", err)
+	}
+	return out, nil
+}
+
+// mutateRequest rewrites the body of a JSON request
+func (c *Client) mutateRequest(req *http.Request) (*http.Request, error) {
+	if c.middleware == nil || len(c.middleware.Request) == 0 || req.Body == nil || !isJSON(req.Header) {
+		return req, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if body, err = c.middleware.MutateRequest(body); err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
+
+// mutateResponse rewrites the body of a successful JSON response
+func (c *Client) mutateResponse(resp *http.Response) error {
+	if c.middleware == nil || len(c.middleware.Response) == 0 ||
+		resp.StatusCode < 200 || resp.StatusCode >= 300 || !isJSON(resp.Header) {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if body, err = c.middleware.MutateResponse(body); err != nil {
+		return err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return nil
+}
+
+func isJSON(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func mutateJSON(body []byte, mutations []Mutation) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode body: %w", err)
+	}
+
+	for _, m := range mutations {
+		if m.Op == "" {
+			return nil, fmt.Errorf("mutation needs one of set, rename or remove")
+		}
+		if m.Path == "" {
+			return nil, fmt.Errorf("%s: empty field path", m.Op)
+		}
+
+		var err error
+		switch m.Op {
+		case "set":
+			doc, err = setPath(doc, splitPath(m.Path), m.Value)
+		case "rename":
+			if m.To == "" {
+				return nil, fmt.Errorf("rename %s: missing destination", m.Path)
+			}
+			if value, ok := getPath(doc, splitPath(m.Path)); ok {
+				deletePath(doc, splitPath(m.Path))
+				doc, err = setPath(doc, splitPath(m.To), value)
+			}
+		case "remove":
+			deletePath(doc, splitPath(m.Path))
+		default:
+			return nil, fmt.Errorf("unknown operation %q", m.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", m.Op, m.Path, err)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func splitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+func getPath(node any, path []string) (any, bool) {
+	for _, key := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[key]
+			if !ok {
+				return nil, false
+			}
+			node = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// setPath sets a value, creating missing objects along the path
+func setPath(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	key := path[0]
+	switch n := node.(type) {
+	case nil:
+		child, err := setPath(nil, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{key: child}, nil
+	case map[string]any:
+		child, err := setPath(n[key], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = child
+		return n, nil
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+		child, err := setPath(n[i], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot set %q on a non-object value", key)
+	}
+}
+
+func deletePath(node any, path []string) {
+	parent, ok := getPath(node, path[:len(path)-1])
+	if !ok {
+		return
+	}
+	if m, ok := parent.(map[string]any); ok {
+		delete(m, path[len(path)-1])
+	}
+}
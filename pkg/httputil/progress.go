@@ -0,0 +1,68 @@
+package httputil
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Progress stages reported for requests
+const (
+	StageSubmitted   = "submitted"   // A request that creates or changes something, e.g. a POST
+	StagePolling     = "polling"     // A GET for status or JSON results
+	StageDownloading = "downloading" // A GET whose response is an image or binary file
+)
+
+// Progress describes a response received by a client using a context set
+// up with WithProgress. Credentials in the URL are redacted.
+type Progress struct {
+	Stage  string
+	Method string
+	URL    string
+	Status int
+}
+
+// ProgressFunc receives progress reports
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose HTTP responses are reported to fn.
+// Reports are made when the response headers arrive, before the body is
+// read.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress reports a response of a request made with a progress
+// context
+func reportProgress(ctx context.Context, req *http.Request, resp *http.Response) {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return
+	}
+
+	stage := StageSubmitted
+	if req.Method == http.MethodGet {
+		stage = StagePolling
+		if isBinary(resp.Header) {
+			stage = StageDownloading
+		}
+	}
+
+	fn(Progress{
+		Stage:  stage,
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+		Status: resp.StatusCode,
+	})
+}
+
+func isBinary(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "image/") || mediaType == "application/octet-stream"
+}
@@ -0,0 +1,108 @@
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// secretParam matches query parameter names that carry credentials
+var secretParam = regexp.MustCompile(`(?i)key|token|secret|signature|auth`)
+
+// Exchange is a recorded request and the raw response it received
+type Exchange struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Recorder collects the responses received by clients using a context
+// set up with WithRecorder. Credentials in URLs are redacted.
+type Recorder struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context whose HTTP responses are recorded by r
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// Exchanges returns the recorded exchanges in order
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+// record stores a response of a request made with a recording context.
+// Textual bodies are buffered and restored; binary bodies are not read.
+func record(ctx context.Context, req *http.Request, resp *http.Response) error {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok || r == nil {
+		return nil
+	}
+
+	exchange := Exchange{
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+		Status: resp.StatusCode,
+		Header: resp.Header.Clone(),
+	}
+	exchange.Header.Del("Set-Cookie")
+
+	if isText(resp.Header) {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.Body = string(body)
+	} else {
+		exchange.Body = fmt.Sprintf("<%s body not recorded>", resp.Header.Get("Content-Type"))
+	}
+
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, exchange)
+	r.mu.Unlock()
+	return nil
+}
+
+// redactURL hides the values of query parameters that look like credentials
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for name := range query {
+		if secretParam.MatchString(name) {
+			query.Set(name, "REDACTED")
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+	return redacted.String()
+}
+
+func isText(h http.Header) bool {
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || isJSON(h) ||
+		strings.HasSuffix(mediaType, "+xml") || mediaType == "application/xml"
+}
@@ -0,0 +1,102 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// resumableBody is the body of a download that, when the connection drops
+// or times out partway, continues with a Range request from the bytes
+// already read instead of failing or starting over
+type resumableBody struct {
+	ctx       context.Context
+	client    *Client
+	req       *http.Request
+	body      io.ReadCloser
+	read      int64
+	validator string // ETag or Last-Modified, sent as If-Range
+	resumes   int
+}
+
+// resumable wraps the body of a successful GET response whose server
+// accepts byte ranges. Other responses, and bodies the transport
+// decompressed, whose offsets don't match the server's, are returned
+// unchanged.
+func (c *Client) resumable(ctx context.Context, req *http.Request, resp *http.Response) *http.Response {
+	if req.Method != http.MethodGet || req.Body != nil || resp.StatusCode != http.StatusOK ||
+		resp.Uncompressed || resp.Header.Get("Accept-Ranges") != "bytes" || c.maxRetries == 0 {
+		return resp
+	}
+
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		// Weak ETags cannot be used with If-Range
+		validator = resp.Header.Get("Last-Modified")
+	}
+	resp.Body = &resumableBody{
+		ctx:       ctx,
+		client:    c,
+		req:       req,
+		body:      resp.Body,
+		validator: validator,
+	}
+	return resp
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if err == nil || err == io.EOF || b.resumes >= b.client.maxRetries || b.ctx.Err() != nil {
+		return n, err
+	}
+
+	if resumeErr := b.resume(); resumeErr != nil {
+		return n, fmt.Errorf("%w (resuming the download failed: %v)", err, resumeErr)
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return b.Read(p)
+}
+
+// resume requests the rest of the body after a backoff
+func (b *resumableBody) resume() error {
+	b.body.Close()
+	b.resumes++
+
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case <-b.client.clock.After(time.Duration(1<<b.resumes) * time.Second):
+	}
+
+	req := b.req.Clone(b.ctx)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", b.read))
+	// Compressed ranges would not continue the uncompressed body
+	req.Header.Set("Accept-Encoding", "identity")
+	if b.validator != "" {
+		req.Header.Set("If-Range", b.validator)
+	}
+	ApplyUserAgent(req)
+
+	resp, err := b.client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	// A 200 means the server ignored the range or the file changed
+	if resp.StatusCode != http.StatusPartialContent ||
+		!strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", b.read)) {
+		resp.Body.Close()
+		return fmt.Errorf("server did not resume at byte %d (status %d)", b.read, resp.StatusCode)
+	}
+	b.body = resp.Body
+	return nil
+}
+
+func (b *resumableBody) Close() error {
+	return b.body.Close()
+}
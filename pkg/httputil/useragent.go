@@ -0,0 +1,31 @@
+package httputil
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// userAgent is the User-Agent of all requests, empty for Go's default
+var userAgent atomic.Pointer[string]
+
+// SetUserAgent sets the User-Agent header sent with the requests of all
+// clients, unless a request sets its own. "" restores Go's default.
+func SetUserAgent(ua string) {
+	userAgent.Store(&ua)
+}
+
+// UserAgent returns the User-Agent set with SetUserAgent
+func UserAgent() string {
+	if ua := userAgent.Load(); ua != nil {
+		return *ua
+	}
+	return ""
+}
+
+// ApplyUserAgent sets the User-Agent of a request made outside a Client,
+// unless it has one
+func ApplyUserAgent(req *http.Request) {
+	if ua := UserAgent(); ua != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", ua)
+	}
+}